@@ -9,6 +9,11 @@ type ConnectionFunc func(string, hcl.Range) connection.PipelingConnection
 
 var ConnectionTypeRegistry map[string]ConnectionFunc
 
+func init() {
+	// key_value connections are created by dotenv connection imports, so are supported by all applications
+	RegisterConnections(connection.NewKeyValueConnection)
+}
+
 func ConnectionTypeSupported(connectionType string) bool {
 	_, exists := ConnectionTypeRegistry[connectionType]
 	return exists
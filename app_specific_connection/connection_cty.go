@@ -47,7 +47,11 @@ func CtyValueToConnection(value cty.Value) (_ connection.PipelingConnection, err
 		return nil, perr.BadRequestWithMessage("unable to decode ConnectionImpl: " + err.Error())
 	}
 	// decode remaining fields into the derived connection
-	err = gocty.FromCtyValue(derivedValue, &conn)
+	if ctyValueDecoder, ok := conn.(connection.CtyValueDecoder); ok {
+		err = ctyValueDecoder.FromCtyValue(derivedValue)
+	} else {
+		err = gocty.FromCtyValue(derivedValue, &conn)
+	}
 	if err != nil {
 		return nil, perr.BadRequestWithMessage("unable to decode connection: " + err.Error())
 	}
@@ -0,0 +1,72 @@
+package backend
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// connection string parameter used to attach databases - supported by the duckdb and sqlite backends
+const attachParam = "attach"
+
+// DatabaseAttachment is a database to attach when connecting to duckdb or sqlite
+type DatabaseAttachment struct {
+	Path string
+	// if not set, the alias is derived from the file name
+	Alias string
+	// NOTE: only applies to duckdb
+	ReadOnly bool
+}
+
+// ParseDatabaseAttachment parses an attachment in the form [alias=]path
+func ParseDatabaseAttachment(str string) DatabaseAttachment {
+	if alias, path, ok := strings.Cut(str, "="); ok {
+		return DatabaseAttachment{Alias: alias, Path: path}
+	}
+	return DatabaseAttachment{Path: str}
+}
+
+// parseDatabaseAttachments removes any attach parameters from the connection string parameters and parses them
+func parseDatabaseAttachments(params url.Values) []DatabaseAttachment {
+	var res []DatabaseAttachment
+	for _, a := range params[attachParam] {
+		res = append(res, ParseDatabaseAttachment(a))
+	}
+	params.Del(attachParam)
+	return res
+}
+
+func (a DatabaseAttachment) String() string {
+	if a.Alias == "" {
+		return a.Path
+	}
+	return a.Alias + "=" + a.Path
+}
+
+// alias returns the alias, or if not set, the file name without extension (as used by duckdb)
+func (a DatabaseAttachment) alias() string {
+	if a.Alias != "" {
+		return a.Alias
+	}
+	name := filepath.Base(a.Path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+func (a DatabaseAttachment) duckDBStatement(readOnly bool) string {
+	var sb strings.Builder
+	sb.WriteString("ATTACH ")
+	sb.WriteString(quoteLiteral(a.Path))
+	if a.Alias != "" {
+		sb.WriteString(" AS ")
+		sb.WriteString(quoteIdentifier(a.Alias))
+	}
+	if a.ReadOnly || readOnly {
+		sb.WriteString(" (READ_ONLY)")
+	}
+	sb.WriteString(";")
+	return sb.String()
+}
+
+func (a DatabaseAttachment) sqliteStatement() string {
+	return "ATTACH DATABASE " + quoteLiteral(a.Path) + " AS " + quoteIdentifier(a.alias()) + ";"
+}
@@ -18,6 +18,8 @@ type RowReader interface {
 
 type Backend interface {
 	Connect(context.Context, ...ConnectOption) (*sql.DB, error)
+	// Ping connects to the database and verifies the connection is usable, retrying using the RetryConfig
+	Ping(context.Context, ...ConnectOption) error
 	RowReader() RowReader
 	ConnectionString() string
 	Name() string
@@ -28,48 +30,33 @@ type SearchPathProvider interface {
 	ResolvedSearchPath() []string
 }
 
-func FromConnectionString(ctx context.Context, str string) (Backend, error) {
-	switch {
-	case IsPostgresConnectionString(str):
-		pgBackend, err := NewPostgresBackend(ctx, str)
-		if err != nil {
-			return nil, err
-		}
-		// check if this is in fact a steampipe backend
-		if isSteampipeBackend(ctx, pgBackend) {
-			return NewSteampipeBackend(ctx, *pgBackend)
-		}
-		return pgBackend, nil
-
-	case IsMySqlConnectionString(str):
-		return NewMySQLBackend(str), nil
-	case IsDuckDBConnectionString(str):
-		return NewDuckDBBackend(str), nil
-	case IsSqliteConnectionString(str):
-		return NewSqliteBackend(str), nil
-	default:
-
+// FromConnectionString creates a Backend using the factory registered for the connection string prefix.
+// Any detectors registered for the backend are then run, allowing a more specific backend to be returned.
+// The options are used by factories and detectors which connect to the database, e.g. to configure retries.
+// If a detector fails, a *DetectionError is returned
+func FromConnectionString(ctx context.Context, str string, opts ...ConnectOption) (Backend, error) {
+	r := registrationForConnectionString(str)
+	if r == nil {
 		return nil, sperr.WrapWithMessage(ErrUnknownBackend, "could not evaluate backend: %s", str)
 	}
+	b, err := r.factory(ctx, str, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return runDetectors(ctx, b, opts)
 }
 
+// HasBackend returns true if a backend is registered for the connection string
 func HasBackend(str string) bool {
-	switch {
-	case
-		IsPostgresConnectionString(str),
-		IsMySqlConnectionString(str),
-		IsDuckDBConnectionString(str),
-		IsSqliteConnectionString(str):
-		return true
-	default:
-
-		return false
-	}
+	return registrationForConnectionString(str) != nil
 }
-func isSteampipeBackend(ctx context.Context, s *PostgresBackend) bool {
-	db, err := s.Connect(ctx)
+
+// isSteampipeBackend returns whether the postgres database is a Steampipe database.
+// An error is returned if this could not be determined
+func isSteampipeBackend(ctx context.Context, s *PostgresBackend, opts []ConnectOption) (bool, error) {
+	db, err := connectWithRetry(ctx, s, opts)
 	if err != nil {
-		return false
+		return false, err
 	}
 	defer db.Close()
 
@@ -84,13 +71,13 @@ func isSteampipeBackend(ctx context.Context, s *PostgresBackend) bool {
 
 	// Execute the query
 	var exists bool
-	err = db.QueryRow(query).Scan(&exists)
+	err = db.QueryRowContext(ctx, query).Scan(&exists)
 	if err != nil {
-		return false
+		return false, sperr.WrapWithMessage(err, "failed to query steampipe internal tables")
 	}
 
 	// Check if tables exist
-	return exists
+	return exists, nil
 }
 
 // IsPostgresConnectionString returns true if the connection string is for postgres
@@ -7,22 +7,53 @@ import (
 // BasicRowReader is a RowReader implementation for generic database/sql driver
 type BasicRowReader struct {
 	CellReader func(columnValue any, col *queryresult.ColumnDef) (any, error)
+	// DataTypes maps the database type names returned by the driver to canonical data types
+	DataTypes map[string]string
+	// BackendName is included in the errors returned for cells which cannot be read
+	BackendName string
 }
 
+// NewBasicRowReader returns a BasicRowReader which normalises values of columns with a canonical data type
+// and passes all other values through unchanged
 func NewBasicRowReader() *BasicRowReader {
+	return newBasicRowReader("", nil)
+}
+
+// newBasicRowReader returns a BasicRowReader which normalises all values using the given data type lookup
+func newBasicRowReader(backendName string, dataTypes map[string]string) *BasicRowReader {
 	return &BasicRowReader{
 		CellReader: func(columnValue any, col *queryresult.ColumnDef) (any, error) {
-			return columnValue, nil
+			return normaliseValue(columnValue, canonicalDataType(col.DataType, dataTypes))
 		},
+		DataTypes:   dataTypes,
+		BackendName: backendName,
 	}
 }
 
+// CanonicalDataType implements DataTypeMapper
+func (r *BasicRowReader) CanonicalDataType(databaseTypeName string) string {
+	return canonicalDataType(databaseTypeName, r.DataTypes)
+}
+
+// Read implements RowReader.
+// If any cells cannot be read, their values are set to nil and a CellErrors error is returned along with the row
 func (r *BasicRowReader) Read(columnValues []any, cols []*queryresult.ColumnDef) ([]any, error) {
 	result := make([]any, len(columnValues))
+	var cellErrors CellErrors
 	for i, columnValue := range columnValues {
-		if cellValue, err := r.CellReader(columnValue, cols[i]); err == nil {
-			result[i] = cellValue
+		cellValue, err := r.CellReader(columnValue, cols[i])
+		if err != nil {
+			cellErrors = append(cellErrors, &queryresult.CellError{
+				Column:  cols[i].Name,
+				Backend: r.BackendName,
+				Err:     err,
+			})
+			continue
 		}
+		result[i] = cellValue
+	}
+	if len(cellErrors) > 0 {
+		return result, cellErrors
 	}
 	return result, nil
 }
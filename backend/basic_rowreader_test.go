@@ -0,0 +1,62 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/queryresult"
+)
+
+func TestReadRowCellErrorPolicy(t *testing.T) {
+	cols := []*queryresult.ColumnDef{
+		{Name: "id", DataType: queryresult.DataTypeInt},
+		{Name: "doc", DataType: queryresult.DataTypeJSON},
+	}
+	// the doc column contains invalid json
+	values := []any{int64(1), "{not json"}
+	reader := newBasicRowReader("Test", nil)
+
+	t.Run("fail row", func(t *testing.T) {
+		res, err := ReadRow(reader, values, cols, CellErrorFailRow)
+		assert.NoError(t, err)
+		assert.Nil(t, res.Data)
+
+		var cellErr *queryresult.CellError
+		assert.True(t, errors.As(res.Error, &cellErr))
+		assert.Equal(t, "doc", cellErr.Column)
+		assert.Equal(t, "Test", cellErr.Backend)
+	})
+
+	t.Run("fail query", func(t *testing.T) {
+		res, err := ReadRow(reader, values, cols, CellErrorFailQuery)
+		assert.Nil(t, res)
+		assert.ErrorContains(t, err, "failed to read column 'doc' from Test backend")
+	})
+
+	t.Run("null with warning", func(t *testing.T) {
+		res, err := ReadRow(reader, values, cols, CellErrorNullWithWarning)
+		assert.NoError(t, err)
+		assert.NoError(t, res.Error)
+		assert.Equal(t, []any{int64(1), nil}, res.Data)
+		assert.Len(t, res.Warnings, 1)
+	})
+
+	t.Run("non integral float", func(t *testing.T) {
+		res, err := ReadRow(reader, []any{1.5, `{}`}, cols, CellErrorFailRow)
+		assert.NoError(t, err)
+		var cellErr *queryresult.CellError
+		assert.True(t, errors.As(res.Error, &cellErr))
+		assert.Equal(t, "id", cellErr.Column)
+
+		res, err = ReadRow(reader, []any{float64(2), `{}`}, cols, CellErrorFailRow)
+		assert.NoError(t, err)
+		assert.Equal(t, []any{int64(2), map[string]any{}}, res.Data)
+	})
+
+	t.Run("no errors", func(t *testing.T) {
+		res, err := ReadRow(reader, []any{int64(1), `{"a": 1}`}, cols, CellErrorFailQuery)
+		assert.NoError(t, err)
+		assert.Equal(t, []any{int64(1), map[string]any{"a": float64(1)}}, res.Data)
+	})
+}
@@ -0,0 +1,67 @@
+package backend
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/queryresult"
+)
+
+// CellErrorPolicy determines how ReadRow handles cells which cannot be read
+type CellErrorPolicy int
+
+const (
+	// CellErrorFailRow returns the row as an error, allowing the remaining rows to be read
+	CellErrorFailRow CellErrorPolicy = iota
+	// CellErrorFailQuery returns an error, which should cause the query to fail
+	CellErrorFailQuery
+	// CellErrorNullWithWarning sets the cells which cannot be read to null and returns the row with warnings
+	CellErrorNullWithWarning
+)
+
+// CellErrors is returned by BasicRowReader.Read if any cells of the row could not be read
+type CellErrors []*queryresult.CellError
+
+func (e CellErrors) Error() string {
+	errs := make([]string, len(e))
+	for i, err := range e {
+		errs[i] = err.Error()
+	}
+	return strings.Join(errs, "; ")
+}
+
+func (e CellErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// ReadRow reads the column values using the RowReader and builds a RowResult, applying the given policy
+// if any cells cannot be read.
+// An error is only returned if the policy is CellErrorFailQuery - otherwise errors are returned in the RowResult
+func ReadRow(rowReader RowReader, columnValues []any, cols []*queryresult.ColumnDef, policy CellErrorPolicy) (*queryresult.RowResult, error) {
+	data, err := rowReader.Read(columnValues, cols)
+	if err == nil {
+		return &queryresult.RowResult{Data: data}, nil
+	}
+
+	var cellErrors CellErrors
+	isCellError := errors.As(err, &cellErrors)
+
+	switch {
+	case policy == CellErrorFailQuery:
+		return nil, err
+	case policy == CellErrorNullWithWarning && isCellError:
+		res := &queryresult.RowResult{Data: data}
+		for _, cellErr := range cellErrors {
+			log.Printf("[WARN] %s - setting value to null", cellErr.Error())
+			res.Warnings = append(res.Warnings, cellErr)
+		}
+		return res, nil
+	default:
+		return &queryresult.RowResult{Error: err}, nil
+	}
+}
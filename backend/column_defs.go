@@ -0,0 +1,26 @@
+package backend
+
+import (
+	"database/sql"
+
+	"github.com/turbot/pipe-fittings/queryresult"
+)
+
+// ColumnDefsFromColumnTypes builds ColumnDefs from the column types returned by the driver.
+// If the RowReader implements DataTypeMapper, the database type names are mapped to canonical data types
+func ColumnDefsFromColumnTypes(rowReader RowReader, colTypes []*sql.ColumnType) []*queryresult.ColumnDef {
+	mapper, _ := rowReader.(DataTypeMapper)
+
+	cols := make([]*queryresult.ColumnDef, len(colTypes))
+	for i, c := range colTypes {
+		dataType := c.DatabaseTypeName()
+		if mapper != nil {
+			dataType = mapper.CanonicalDataType(dataType)
+		}
+		cols[i] = &queryresult.ColumnDef{
+			Name:     c.Name(),
+			DataType: dataType,
+		}
+	}
+	return cols
+}
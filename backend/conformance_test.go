@@ -0,0 +1,296 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/marcboeker/go-duckdb"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/queryresult"
+)
+
+// conformanceCase describes a column which every backend must read as the same canonical data type and value
+type conformanceCase struct {
+	name             string
+	expectedDataType string
+	expectedValue    any
+	// map of column type and SQL literal, keyed by backend name
+	// backends with no entry do not support the case
+	columns map[string]conformanceColumn
+}
+
+type conformanceColumn struct {
+	columnType string
+	literal    string
+}
+
+var conformanceCases = []conformanceCase{
+	{
+		name:             "text",
+		expectedDataType: queryresult.DataTypeText,
+		expectedValue:    "hello",
+		columns: map[string]conformanceColumn{
+			"sqlite": {"VARCHAR(20)", "'hello'"},
+			"duckdb": {"VARCHAR", "'hello'"},
+		},
+	},
+	{
+		name:             "int",
+		expectedDataType: queryresult.DataTypeInt,
+		expectedValue:    int64(42),
+		columns: map[string]conformanceColumn{
+			"sqlite": {"INTEGER", "42"},
+			"duckdb": {"INTEGER", "42"},
+		},
+	},
+	{
+		name:             "big_int",
+		expectedDataType: queryresult.DataTypeInt,
+		expectedValue:    int64(9007199254740993),
+		columns: map[string]conformanceColumn{
+			"sqlite": {"BIGINT", "9007199254740993"},
+			"duckdb": {"HUGEINT", "9007199254740993"},
+		},
+	},
+	{
+		name:             "float",
+		expectedDataType: queryresult.DataTypeFloat,
+		expectedValue:    2.5,
+		columns: map[string]conformanceColumn{
+			"sqlite": {"REAL", "2.5"},
+			"duckdb": {"FLOAT", "2.5"},
+		},
+	},
+	{
+		name:             "decimal",
+		expectedDataType: queryresult.DataTypeDecimal,
+		expectedValue:    "12.34",
+		columns: map[string]conformanceColumn{
+			"sqlite": {"DECIMAL(10,2)", "12.34"},
+			"duckdb": {"DECIMAL(10,2)", "12.34"},
+		},
+	},
+	{
+		name:             "precise_decimal",
+		expectedDataType: queryresult.DataTypeDecimal,
+		expectedValue:    "-12345678901234567890.000000001",
+		columns: map[string]conformanceColumn{
+			// NOTE: no sqlite case as sqlite stores decimals as floats
+			"duckdb": {"DECIMAL(38,9)", "-12345678901234567890.000000001"},
+		},
+	},
+	{
+		name:             "small_decimal",
+		expectedDataType: queryresult.DataTypeDecimal,
+		expectedValue:    "0.05",
+		columns: map[string]conformanceColumn{
+			"sqlite": {"NUMERIC", "0.05"},
+			"duckdb": {"DECIMAL(10,2)", "0.05"},
+		},
+	},
+	{
+		// sqlite stores text which cannot be converted to a number as text, even in a numeric column
+		name:             "numeric_text",
+		expectedDataType: queryresult.DataTypeDecimal,
+		expectedValue:    "n/a",
+		columns: map[string]conformanceColumn{
+			"sqlite": {"NUMERIC", "'n/a'"},
+		},
+	},
+	{
+		// sqlite allows any declared type - unrecognised types are read as text
+		name:             "unknown_type",
+		expectedDataType: queryresult.DataTypeText,
+		expectedValue:    "hello",
+		columns: map[string]conformanceColumn{
+			"sqlite": {"STRING", "'hello'"},
+		},
+	},
+	{
+		name:             "bool",
+		expectedDataType: queryresult.DataTypeBool,
+		expectedValue:    true,
+		columns: map[string]conformanceColumn{
+			"sqlite": {"BOOLEAN", "1"},
+			"duckdb": {"BOOLEAN", "true"},
+		},
+	},
+	{
+		name:             "json",
+		expectedDataType: queryresult.DataTypeJSON,
+		expectedValue:    map[string]any{"a": float64(1), "b": []any{"x", "y"}},
+		columns: map[string]conformanceColumn{
+			"sqlite": {"JSON", `'{"a": 1, "b": ["x", "y"]}'`},
+			// NOTE: no duckdb case as the duckdb driver reports JSON columns as VARCHAR
+		},
+	},
+	{
+		name:             "date",
+		expectedDataType: queryresult.DataTypeDate,
+		expectedValue:    time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		columns: map[string]conformanceColumn{
+			"sqlite": {"DATE", "'2024-01-02'"},
+			"duckdb": {"DATE", "'2024-01-02'"},
+		},
+	},
+	{
+		name:             "timestamp",
+		expectedDataType: queryresult.DataTypeTimestamp,
+		expectedValue:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		columns: map[string]conformanceColumn{
+			"sqlite": {"DATETIME", "'2024-01-02 03:04:05'"},
+			"duckdb": {"TIMESTAMP", "'2024-01-02 03:04:05'"},
+		},
+	},
+	{
+		name:             "time",
+		expectedDataType: queryresult.DataTypeTime,
+		expectedValue:    "10:11:12",
+		columns: map[string]conformanceColumn{
+			"sqlite": {"TIME", "'10:11:12'"},
+			"duckdb": {"TIME", "'10:11:12'"},
+		},
+	},
+	{
+		name:             "uuid",
+		expectedDataType: queryresult.DataTypeUUID,
+		expectedValue:    "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11",
+		columns: map[string]conformanceColumn{
+			"sqlite": {"UUID", "'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11'"},
+			"duckdb": {"UUID", "'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11'"},
+		},
+	},
+	{
+		name:             "bytes",
+		expectedDataType: queryresult.DataTypeBytes,
+		expectedValue:    []byte{0x01, 0x02},
+		columns: map[string]conformanceColumn{
+			"sqlite": {"BLOB", "x'0102'"},
+			"duckdb": {"BLOB", `'\x01\x02'::BLOB`},
+		},
+	},
+}
+
+func TestRowReaderConformance(t *testing.T) {
+	backends := map[string]func(path string) Backend{
+		"sqlite": func(path string) Backend { return NewSqliteBackend("sqlite:" + path) },
+		"duckdb": func(path string) Backend { return NewDuckDBBackend("duckdb:" + path) },
+	}
+
+	for backendName, newBackend := range backends {
+		t.Run(backendName, func(t *testing.T) {
+			b := newBackend(filepath.Join(t.TempDir(), "conformance.db"))
+			cases, cols, row := readConformanceRow(t, b, backendName)
+
+			for i, c := range cases {
+				assert.Equal(t, c.name, cols[i].Name)
+				assert.Equal(t, c.expectedDataType, cols[i].DataType, c.name)
+				assert.Equal(t, c.expectedValue, row[i], c.name)
+			}
+		})
+	}
+}
+
+func TestRowReaderConformanceDuckDBTypes(t *testing.T) {
+	b := NewDuckDBBackend("duckdb:" + filepath.Join(t.TempDir(), "conformance.db"))
+	db := openConformanceDB(t, b)
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT
+	INTERVAL '1 year 2 days 3 hours' AS "interval",
+	TIMESTAMPTZ '2024-01-02 03:04:05+00' AS "timestamptz",
+	['a', 'b'] AS "array",
+	{'x': 1} AS "struct",
+	MAP {'k': 1} AS "map"`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	colTypes, err := rows.ColumnTypes()
+	require.NoError(t, err)
+	cols := ColumnDefsFromColumnTypes(b.RowReader(), colTypes)
+	row := scanRow(t, rows, b, cols)
+
+	assert.Equal(t, queryresult.DataTypeInterval, cols[0].DataType)
+	assert.Equal(t, "1 year 2 days 03:00:00", row[0])
+	assert.Equal(t, queryresult.DataTypeTimestampTz, cols[1].DataType)
+	assert.True(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Equal(row[1].(time.Time)))
+	assert.Equal(t, queryresult.ArrayDataType(queryresult.DataTypeText), cols[2].DataType)
+	assert.Equal(t, []any{"a", "b"}, row[2])
+	assert.Equal(t, queryresult.DataTypeJSON, cols[3].DataType)
+	assert.Equal(t, map[string]any{"x": int32(1)}, row[3])
+	assert.Equal(t, queryresult.DataTypeJSON, cols[4].DataType)
+	assert.Equal(t, map[string]any{"k": int32(1)}, row[4])
+}
+
+// readConformanceRow creates a table containing a column for each conformance case supported by the backend,
+// inserts a row, then reads it back using the backend RowReader
+func readConformanceRow(t *testing.T, b Backend, backendName string) ([]conformanceCase, []*queryresult.ColumnDef, []any) {
+	ctx := context.Background()
+	db := openConformanceDB(t, b)
+	defer db.Close()
+
+	var cases []conformanceCase
+	var columnDefs, literals []string
+	for _, c := range conformanceCases {
+		col, ok := c.columns[backendName]
+		if !ok {
+			continue
+		}
+		cases = append(cases, c)
+		columnDefs = append(columnDefs, fmt.Sprintf("%s %s", c.name, col.columnType))
+		literals = append(literals, col.literal)
+	}
+	_, err := db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE conformance (%s)", strings.Join(columnDefs, ", ")))
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, fmt.Sprintf("INSERT INTO conformance VALUES (%s)", strings.Join(literals, ", ")))
+	require.NoError(t, err)
+
+	rows, err := db.QueryContext(ctx, "SELECT * FROM conformance")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	colTypes, err := rows.ColumnTypes()
+	require.NoError(t, err)
+	cols := ColumnDefsFromColumnTypes(b.RowReader(), colTypes)
+	return cases, cols, scanRow(t, rows, b, cols)
+}
+
+// openConformanceDB connects to the backend database
+// NOTE: duckdb extensions are loaded without installing, as installing requires network access
+// (the json extension is statically linked)
+func openConformanceDB(t *testing.T, b Backend) *sql.DB {
+	var opts []ConnectOption
+	if b.Name() == constants.DuckDBBackendName {
+		opts = append(opts, WithDuckDBConfig(DuckDBConfig{LoadExtensionsOnly: true}))
+	}
+	db, err := b.Connect(context.Background(), opts...)
+	require.NoError(t, err)
+	return db
+}
+
+type scanner interface {
+	Next() bool
+	Scan(dest ...any) error
+}
+
+func scanRow(t *testing.T, rows scanner, b Backend, cols []*queryresult.ColumnDef) []any {
+	require.True(t, rows.Next())
+	values := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	require.NoError(t, rows.Scan(ptrs...))
+
+	row, err := b.RowReader().Read(values, cols)
+	require.NoError(t, err)
+	return row
+}
@@ -1,15 +1,20 @@
 package backend
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/turbot/pipe-fittings/constants"
 )
 
 const (
 	DefaultMaxConnLifeTime = 10 * time.Minute
 	DefaultMaxConnIdleTime = 1 * time.Minute
 	DefaultMaxOpenConns    = 10
+	// DefaultMaxConnectBackoff is the maximum delay between connection retries
+	DefaultMaxConnectBackoff = 5 * time.Second
 )
 
 var ErrInvalidConfig = errors.New("invalid config")
@@ -39,11 +44,28 @@ func (c SearchPathConfig) String() string {
 	return fmt.Sprintf("search_path_prefix=%v", c.SearchPathPrefix)
 }
 
+// RetryConfig controls how failures to connect to the database are retried
+type RetryConfig struct {
+	// the number of retries after the initial attempt - if zero, failures are not retried
+	MaxRetries int
+	// the delay before the first retry - this is doubled for each subsequent retry, up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
 type ConnectConfig struct {
 	MaxConnLifeTime  time.Duration
 	MaxConnIdleTime  time.Duration
 	MaxOpenConns     int
 	SearchPathConfig SearchPathConfig
+	// only applies if the backend is duckdb
+	DuckDBConfig DuckDBConfig
+	// used when establishing the initial connection, i.e. for Ping and backend initialisation and detection
+	RetryConfig RetryConfig
+	// if set, connections are configured to reject statements which modify the database
+	ReadOnly bool
+	// only applies if the backend is mysql
+	TLSConfig *tls.Config
 }
 
 func NewConnectConfig(opts []ConnectOption) *ConnectConfig {
@@ -51,6 +73,10 @@ func NewConnectConfig(opts []ConnectOption) *ConnectConfig {
 		MaxConnLifeTime: DefaultMaxConnLifeTime,
 		MaxConnIdleTime: DefaultMaxConnIdleTime,
 		MaxOpenConns:    DefaultMaxOpenConns,
+		RetryConfig: RetryConfig{
+			Backoff:    constants.DBConnectionRetryBackoff,
+			MaxBackoff: DefaultMaxConnectBackoff,
+		},
 	}
 	for _, opt := range opts {
 		opt(c)
@@ -66,14 +92,63 @@ func WithConfig(other *ConnectConfig) ConnectOption {
 		c.MaxConnLifeTime = other.MaxConnLifeTime
 		c.MaxConnIdleTime = other.MaxConnIdleTime
 		c.MaxOpenConns = other.MaxOpenConns
+		c.DuckDBConfig = other.DuckDBConfig
+		c.RetryConfig = other.RetryConfig
+		c.ReadOnly = other.ReadOnly
+		c.TLSConfig = other.TLSConfig
 	}
 }
 
 // WithSearchPathConfig sets the search path to use when connecting to the database.
-// If a prefix is also set, the search path will be resolved to the first matching
-// schema in the search path. Only applies if the backend is postgres
+// If a prefix is set, it is prepended to the original search path of the database.
+// For postgres and duckdb, this sets the search_path of each connection. For sqlite, the search path
+// entries are the main and attached databases, and unqualified table names are resolved in search path order
 func WithSearchPathConfig(config SearchPathConfig) ConnectOption {
 	return func(c *ConnectConfig) {
 		c.SearchPathConfig = config
 	}
 }
+
+// WithDuckDBConfig sets the extensions, attached databases and settings to use when connecting to the database.
+// These are merged with any settings in the connection string. Only applies if the backend is duckdb
+func WithDuckDBConfig(config DuckDBConfig) ConnectOption {
+	return func(c *ConnectConfig) {
+		c.DuckDBConfig = config
+	}
+}
+
+// WithRetryConfig sets how failures to connect to the database are retried.
+// Zero durations are replaced with the defaults
+func WithRetryConfig(config RetryConfig) ConnectOption {
+	return func(c *ConnectConfig) {
+		if config.Backoff == 0 {
+			config.Backoff = constants.DBConnectionRetryBackoff
+		}
+		if config.MaxBackoff == 0 {
+			config.MaxBackoff = DefaultMaxConnectBackoff
+		}
+		c.RetryConfig = config
+	}
+}
+
+// WithReadOnly configures each connection to reject statements which modify the database:
+//   - postgres: sets default_transaction_read_only
+//   - sqlite: sets PRAGMA query_only
+//   - duckdb: opens the database (and any attached databases) with the read_only access mode
+//   - mysql: sets SET SESSION TRANSACTION READ ONLY
+//
+// NOTE: for postgres and mysql, this is a session setting, so may be changed by a query which sets it explicitly
+func WithReadOnly() ConnectOption {
+	return func(c *ConnectConfig) {
+		c.ReadOnly = true
+	}
+}
+
+// WithTLSConfig sets the tls config used to connect to the database, e.g. to use client certificates. This overrides
+// any tls, ssl-ca, ssl-cert and ssl-key parameters of the connection string. Only applies if the backend is mysql - for
+// postgres, certificates are set using the sslrootcert, sslcert and sslkey connection string parameters
+func WithTLSConfig(config *tls.Config) ConnectOption {
+	return func(c *ConnectConfig) {
+		c.TLSConfig = config
+	}
+}
@@ -0,0 +1,111 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+)
+
+// afterConnectConnector is a driver.Connector for a registered database/sql driver,
+// which executes a callback after each connection is established
+type afterConnectConnector struct {
+	driver.Connector
+	afterConnectFunc func(context.Context, driver.Conn) error
+}
+
+// newAfterConnectConnector creates a connector for the named database/sql driver
+func newAfterConnectConnector(driverName, dataSourceName string, afterConnectFunc func(context.Context, driver.Conn) error) (*afterConnectConnector, error) {
+	// sql.Open does not create any connections, it just looks up the driver
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	d := db.Driver()
+	db.Close()
+
+	var connector driver.Connector = &dsnConnector{driver: d, dataSourceName: dataSourceName}
+	if driverCtx, ok := d.(driver.DriverContext); ok {
+		connector, err = driverCtx.OpenConnector(dataSourceName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &afterConnectConnector{
+		Connector:        connector,
+		afterConnectFunc: afterConnectFunc,
+	}, nil
+}
+
+func (c *afterConnectConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.afterConnectFunc != nil {
+		if err = c.afterConnectFunc(ctx, conn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// dsnConnector is a driver.Connector for drivers which do not implement driver.DriverContext
+type dsnConnector struct {
+	driver         driver.Driver
+	dataSourceName string
+}
+
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dataSourceName)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// execDriverConn executes a statement on a driver connection
+func execDriverConn(ctx context.Context, conn driver.Conn, query string) error {
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		return fmt.Errorf("driver connection does not implement ExecerContext")
+	}
+	_, err := execer.ExecContext(ctx, query, nil)
+	return err
+}
+
+// queryDriverConnStrings executes a query returning a single string column on a driver connection and returns the values
+func queryDriverConnStrings(ctx context.Context, conn driver.Conn, query string) ([]string, error) {
+	queryer, ok := conn.(driver.QueryerContext)
+	if !ok {
+		return nil, fmt.Errorf("driver connection does not implement QueryerContext")
+	}
+	rows, err := queryer.QueryContext(ctx, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []string
+	dest := make([]driver.Value, 1)
+	for {
+		if err := rows.Next(dest); err != nil {
+			if err == io.EOF {
+				return res, nil
+			}
+			return nil, err
+		}
+		switch v := dest[0].(type) {
+		case string:
+			res = append(res, v)
+		case []byte:
+			res = append(res, string(v))
+		default:
+			return nil, fmt.Errorf("unexpected value type %T", v)
+		}
+	}
+}
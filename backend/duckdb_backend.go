@@ -3,53 +3,163 @@ package backend
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/queryresult"
 	"github.com/turbot/pipe-fittings/sperr"
 )
 
 const (
 	duckDBConnectionStringPrefix = "duckdb:"
+	duckDBDefaultSchema          = "main"
 )
 
 type DuckDBBackend struct {
+	infoSchemaIntrospector
 	connectionString string
 	rowreader        RowReader
+	// the database path and parameters passed to duckdb, and the config parsed from the connection string
+	path   string
+	params url.Values
+	config DuckDBConfig
+	// any error parsing the connection string - returned by Connect
+	parseErr           error
+	originalSearchPath []string
+	// if a custom search path or a prefix is used, store the resolved search path
+	requiredSearchPath []string
 }
 
 func NewDuckDBBackend(connString string) *DuckDBBackend {
 	connString = strings.TrimSpace(connString) // remove any leading or trailing whitespace
 	connString = strings.TrimPrefix(connString, duckDBConnectionStringPrefix)
+	path, config, params, err := parseDuckDBConnectionString(connString)
 	return &DuckDBBackend{
+		infoSchemaIntrospector: infoSchemaIntrospector{
+			placeholder:    questionPlaceholder,
+			dataTypeColumn: "data_type",
+			dataTypes:      duckDBDataTypes,
+		},
 		connectionString: connString,
 		rowreader:        newDuckDBRowReader(),
+		path:             path,
+		params:           params,
+		config:           config,
+		parseErr:         err,
 	}
 }
 
 // Connect implements Backend.
 func (b *DuckDBBackend) Connect(ctx context.Context, options ...ConnectOption) (*sql.DB, error) {
+	if b.parseErr != nil {
+		return nil, b.parseErr
+	}
 	config := NewConnectConfig(options)
-	db, err := sql.Open("duckdb", b.connectionString)
+	duckDBConfig := b.config.Merge(config.DuckDBConfig)
+	duckDBConfig.ReadOnly = duckDBConfig.ReadOnly || config.ReadOnly
+
+	// the search path is a per connection setting, so must be set for each new connection
+	// (this is set once the database has been configured)
+	var searchPathStatement string
+	connector, err := newAfterConnectConnector("duckdb", duckDBConfig.dsn(b.path, b.params), func(ctx context.Context, conn driver.Conn) error {
+		if searchPathStatement == "" {
+			return nil
+		}
+		return execDriverConn(ctx, conn, searchPathStatement)
+	})
 	if err != nil {
 		return nil, sperr.WrapWithMessage(err, "could not connect to duckdb backend")
 	}
+
+	db := sql.OpenDB(connector)
 	db.SetConnMaxIdleTime(config.MaxConnIdleTime)
 	db.SetConnMaxLifetime(config.MaxConnLifeTime)
 	db.SetMaxOpenConns(config.MaxOpenConns)
 
-	// Install and load the JSON extension
-	_, err = db.ExecContext(ctx, "INSTALL 'json';")
+	searchPathStatement, err = b.configure(ctx, db, duckDBConfig, config.SearchPathConfig)
 	if err != nil {
-		return nil, sperr.WrapWithMessage(err, "could not install json extension in duckdb")
+		db.Close()
+		return nil, err
 	}
+	return db, nil
+}
 
-	_, err = db.ExecContext(ctx, "LOAD 'json';")
+// configure installs and loads extensions, attaches databases and resolves the search path,
+// returning the statement used to set the search path (if any)
+func (b *DuckDBBackend) configure(ctx context.Context, db *sql.DB, duckDBConfig DuckDBConfig, searchPathConfig SearchPathConfig) (string, error) {
+	conn, err := db.Conn(ctx)
 	if err != nil {
-		return nil, sperr.WrapWithMessage(err, "could not load json extension in duckdb")
+		return "", sperr.WrapWithMessage(err, "could not connect to duckdb backend")
 	}
+	defer conn.Close()
 
-	return db, nil
+	// NOTE: these apply to the duckdb database instance, so are shared by all connections in the pool
+	for _, statement := range duckDBConfig.setupStatements() {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return "", sperr.WrapWithMessage(err, "could not configure duckdb: %s", statement)
+		}
+	}
+
+	// the search path can only be set once any databases it refers to are attached
+	if err := b.loadSearchPath(ctx, conn); err != nil {
+		return "", err
+	}
+	requiredSearchPath, err := resolveRequiredSearchPath(searchPathConfig, b.originalSearchPath)
+	if err != nil {
+		return "", err
+	}
+	b.requiredSearchPath = requiredSearchPath
+	if len(requiredSearchPath) == 0 {
+		return "", nil
+	}
+
+	statement := fmt.Sprintf("SET search_path = %s;", quoteLiteral(strings.Join(requiredSearchPath, ",")))
+	if _, err := conn.ExecContext(ctx, statement); err != nil {
+		return "", sperr.WrapWithMessage(err, "could not set duckdb search path")
+	}
+	return statement, nil
+}
+
+// loadSearchPath gets the current search path from the database
+func (b *DuckDBBackend) loadSearchPath(ctx context.Context, conn *sql.Conn) error {
+	var searchPathStr string
+	if err := conn.QueryRowContext(ctx, "SELECT current_setting('search_path');").Scan(&searchPathStr); err != nil {
+		return sperr.WrapWithMessage(err, "could not read duckdb search path")
+	}
+
+	// if no search path is set, duckdb resolves unqualified names using the main schema of the default database
+	if searchPathStr == "" {
+		b.originalSearchPath = []string{duckDBDefaultSchema}
+		return nil
+	}
+	b.originalSearchPath = strings.Split(searchPathStr, ",")
+	return nil
+}
+
+// OriginalSearchPath implements SearchPathProvider.
+func (b *DuckDBBackend) OriginalSearchPath() []string {
+	return b.originalSearchPath
+}
+
+// RequiredSearchPath implements SearchPathProvider
+func (b *DuckDBBackend) RequiredSearchPath() []string {
+	return b.requiredSearchPath
+}
+
+// ResolvedSearchPath implements SearchPathProvider
+func (b *DuckDBBackend) ResolvedSearchPath() []string {
+	if len(b.requiredSearchPath) != 0 {
+		return b.requiredSearchPath
+	}
+	return b.originalSearchPath
+}
+
+// Ping implements Backend.
+func (b *DuckDBBackend) Ping(ctx context.Context, options ...ConnectOption) error {
+	return ping(ctx, b, options)
 }
 
 func (b *DuckDBBackend) ConnectionString() string {
@@ -65,13 +175,40 @@ func (b *DuckDBBackend) RowReader() RowReader {
 	return b.rowreader
 }
 
+// duckDBDataTypes maps the duckdb driver database type names to canonical data types
+var duckDBDataTypes = map[string]string{
+	"BOOLEAN":                  queryresult.DataTypeBool,
+	"TINYINT":                  queryresult.DataTypeInt,
+	"SMALLINT":                 queryresult.DataTypeInt,
+	"INTEGER":                  queryresult.DataTypeInt,
+	"BIGINT":                   queryresult.DataTypeInt,
+	"HUGEINT":                  queryresult.DataTypeInt,
+	"UTINYINT":                 queryresult.DataTypeInt,
+	"USMALLINT":                queryresult.DataTypeInt,
+	"UINTEGER":                 queryresult.DataTypeInt,
+	"UBIGINT":                  queryresult.DataTypeInt,
+	"UHUGEINT":                 queryresult.DataTypeInt,
+	"FLOAT":                    queryresult.DataTypeFloat,
+	"DOUBLE":                   queryresult.DataTypeFloat,
+	"DECIMAL":                  queryresult.DataTypeDecimal,
+	"VARCHAR":                  queryresult.DataTypeText,
+	"ENUM":                     queryresult.DataTypeText,
+	"BLOB":                     queryresult.DataTypeBytes,
+	"TIMESTAMP_S":              queryresult.DataTypeTimestamp,
+	"TIMESTAMP_MS":             queryresult.DataTypeTimestamp,
+	"TIMESTAMP_NS":             queryresult.DataTypeTimestamp,
+	"TIMESTAMP WITH TIME ZONE": queryresult.DataTypeTimestampTz,
+	"JSON":                     queryresult.DataTypeJSON,
+	"STRUCT":                   queryresult.DataTypeJSON,
+	"MAP":                      queryresult.DataTypeJSON,
+}
+
 type duckdbRowReader struct {
 	BasicRowReader
 }
 
 func newDuckDBRowReader() *duckdbRowReader {
 	return &duckdbRowReader{
-		// use the generic row reader - there's no real difference between sqlite and duckdb
-		BasicRowReader: *NewBasicRowReader(),
+		BasicRowReader: *newBasicRowReader(constants.DuckDBBackendName, duckDBDataTypes),
 	}
 }
@@ -0,0 +1,156 @@
+package backend
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/sperr"
+)
+
+// connection string parameters handled by the duckdb backend rather than passed to duckdb
+const (
+	duckDBExtensionsParam        = "extensions"
+	duckDBInstallExtensionsParam = "install_extensions"
+	// these are duckdb configuration options, passed through to duckdb when opening the database
+	duckDBAccessModeParam  = "access_mode"
+	duckDBThreadsParam     = "threads"
+	duckDBMemoryLimitParam = "memory_limit"
+
+	duckDBReadOnlyAccessMode = "read_only"
+)
+
+// defaultDuckDBExtensions are the extensions loaded if no extensions are configured
+var defaultDuckDBExtensions = []string{"json"}
+
+// DuckDBConfig contains the duckdb specific connection configuration.
+// It may be set using connection string parameters, e.g.
+// duckdb:my.db?extensions=json,parquet&install_extensions=false&attach=other=other.db&access_mode=read_only&threads=4&memory_limit=1GB
+// or using the WithDuckDBConfig ConnectOption
+type DuckDBConfig struct {
+	// extensions to load when connecting - if nil, the json extension is loaded
+	Extensions []string
+	// if set, extensions are only loaded and never installed, e.g. for machines without internet access
+	// (the extensions must either be statically linked or already installed)
+	LoadExtensionsOnly bool
+	// databases to attach when connecting
+	Attach   []DatabaseAttachment
+	ReadOnly bool
+	// if zero, the duckdb default is used
+	Threads int
+	// e.g. 1GB - if empty, the duckdb default is used
+	MemoryLimit string
+}
+
+// Merge returns a copy of the config with all fields set in other overriding the values of this config
+func (c DuckDBConfig) Merge(other DuckDBConfig) DuckDBConfig {
+	if other.Extensions != nil {
+		c.Extensions = other.Extensions
+	}
+	c.LoadExtensionsOnly = c.LoadExtensionsOnly || other.LoadExtensionsOnly
+	c.Attach = slices.Concat(c.Attach, other.Attach)
+	c.ReadOnly = c.ReadOnly || other.ReadOnly
+	if other.Threads != 0 {
+		c.Threads = other.Threads
+	}
+	if other.MemoryLimit != "" {
+		c.MemoryLimit = other.MemoryLimit
+	}
+	return c
+}
+
+func (c DuckDBConfig) extensions() []string {
+	if c.Extensions == nil {
+		return defaultDuckDBExtensions
+	}
+	return c.Extensions
+}
+
+// dsn returns the data source name passed to the duckdb driver, adding the duckdb configuration options
+func (c DuckDBConfig) dsn(path string, params url.Values) string {
+	params = maps.Clone(params)
+	if params == nil {
+		params = url.Values{}
+	}
+	if c.ReadOnly {
+		params.Set(duckDBAccessModeParam, duckDBReadOnlyAccessMode)
+	}
+	if c.Threads != 0 {
+		params.Set(duckDBThreadsParam, strconv.Itoa(c.Threads))
+	}
+	if c.MemoryLimit != "" {
+		params.Set(duckDBMemoryLimitParam, c.MemoryLimit)
+	}
+	if len(params) == 0 {
+		return path
+	}
+	return path + "?" + params.Encode()
+}
+
+// setupStatements returns the statements to execute after opening the database
+func (c DuckDBConfig) setupStatements() []string {
+	var res []string
+	for _, extension := range c.extensions() {
+		if !c.LoadExtensionsOnly {
+			res = append(res, fmt.Sprintf("INSTALL %s;", quoteLiteral(extension)))
+		}
+		res = append(res, fmt.Sprintf("LOAD %s;", quoteLiteral(extension)))
+	}
+	for _, a := range c.Attach {
+		res = append(res, a.duckDBStatement(c.ReadOnly))
+	}
+	return res
+}
+
+// parseDuckDBConnectionString splits the connection string (without prefix) into the database path,
+// the duckdb config and any remaining parameters, which are passed through to duckdb
+func parseDuckDBConnectionString(connString string) (string, DuckDBConfig, url.Values, error) {
+	var config DuckDBConfig
+	path, query, _ := strings.Cut(connString, "?")
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return "", config, nil, sperr.WrapWithMessage(err, "invalid duckdb connection string parameters")
+	}
+
+	if params.Has(duckDBExtensionsParam) {
+		config.Extensions = []string{}
+		for _, e := range strings.Split(params.Get(duckDBExtensionsParam), ",") {
+			if e = strings.TrimSpace(e); e != "" {
+				config.Extensions = append(config.Extensions, e)
+			}
+		}
+		params.Del(duckDBExtensionsParam)
+	}
+	if params.Has(duckDBInstallExtensionsParam) {
+		install, err := strconv.ParseBool(params.Get(duckDBInstallExtensionsParam))
+		if err != nil {
+			return "", config, nil, sperr.WrapWithMessage(err, "invalid value for duckdb connection string parameter '%s'", duckDBInstallExtensionsParam)
+		}
+		config.LoadExtensionsOnly = !install
+		params.Del(duckDBInstallExtensionsParam)
+	}
+	config.Attach = parseDatabaseAttachments(params)
+
+	// the remaining settings are duckdb options - store in the config so they can be overridden
+	if params.Has(duckDBAccessModeParam) {
+		config.ReadOnly = strings.EqualFold(params.Get(duckDBAccessModeParam), duckDBReadOnlyAccessMode)
+		params.Del(duckDBAccessModeParam)
+	}
+	if params.Has(duckDBThreadsParam) {
+		threads, err := strconv.Atoi(params.Get(duckDBThreadsParam))
+		if err != nil {
+			return "", config, nil, sperr.WrapWithMessage(err, "invalid value for duckdb connection string parameter '%s'", duckDBThreadsParam)
+		}
+		config.Threads = threads
+		params.Del(duckDBThreadsParam)
+	}
+	if params.Has(duckDBMemoryLimitParam) {
+		config.MemoryLimit = params.Get(duckDBMemoryLimitParam)
+		params.Del(duckDBMemoryLimitParam)
+	}
+
+	return path, config, params, nil
+}
@@ -0,0 +1,83 @@
+package backend
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuckDBConnectionString(t *testing.T) {
+	path, config, params, err := parseDuckDBConnectionString("my.db?extensions=json,%20parquet&install_extensions=false&attach=other=other.db&attach=third.db&access_mode=READ_ONLY&threads=4&memory_limit=1GB&custom=x")
+	require.NoError(t, err)
+
+	assert.Equal(t, "my.db", path)
+	assert.Equal(t, DuckDBConfig{
+		Extensions:         []string{"json", "parquet"},
+		LoadExtensionsOnly: true,
+		Attach:             []DatabaseAttachment{{Alias: "other", Path: "other.db"}, {Path: "third.db"}},
+		ReadOnly:           true,
+		Threads:            4,
+		MemoryLimit:        "1GB",
+	}, config)
+	// unrecognised parameters are passed through to duckdb
+	assert.Equal(t, url.Values{"custom": []string{"x"}}, params)
+
+	_, _, _, err = parseDuckDBConnectionString("my.db?threads=many")
+	assert.Error(t, err)
+}
+
+func TestDuckDBConfigSetupStatements(t *testing.T) {
+	config := DuckDBConfig{
+		Extensions: []string{"parquet"},
+		Attach:     []DatabaseAttachment{{Alias: "o", Path: "o'1.db"}},
+		ReadOnly:   true,
+	}
+	assert.Equal(t, []string{
+		"INSTALL 'parquet';",
+		"LOAD 'parquet';",
+		`ATTACH 'o''1.db' AS "o" (READ_ONLY);`,
+	}, config.setupStatements())
+
+	config = DuckDBConfig{LoadExtensionsOnly: true}
+	assert.Equal(t, []string{"LOAD 'json';"}, config.setupStatements())
+	assert.Equal(t, "my.db?memory_limit=1GB&threads=2", DuckDBConfig{Threads: 2, MemoryLimit: "1GB"}.dsn("my.db", nil))
+}
+
+func TestDuckDBConnectAttachReadOnly(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	otherPath := filepath.Join(dir, "other.db")
+
+	// create a table in the database to attach
+	other, err := NewDuckDBBackend("duckdb:" + otherPath + "?install_extensions=false").Connect(ctx)
+	require.NoError(t, err)
+	_, err = other.ExecContext(ctx, "CREATE TABLE t (id INTEGER); INSERT INTO t VALUES (1);")
+	require.NoError(t, err)
+	require.NoError(t, other.Close())
+
+	b := NewDuckDBBackend("duckdb:" + filepath.Join(dir, "main.db") + "?install_extensions=false&attach=o=" + otherPath)
+	db, err := b.Connect(ctx, WithDuckDBConfig(DuckDBConfig{Threads: 2}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	var id int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT id FROM o.t").Scan(&id))
+	assert.Equal(t, 1, id)
+	var threads string
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT current_setting('threads')").Scan(&threads))
+	assert.Equal(t, "2", threads)
+	db.Close()
+
+	// reopen read only - writes should fail
+	db, err = b.Connect(ctx, WithDuckDBConfig(DuckDBConfig{ReadOnly: true}))
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.ExecContext(ctx, "INSERT INTO o.t VALUES (2)")
+	assert.Error(t, err)
+	_, err = db.ExecContext(ctx, "CREATE TABLE main_t (id INTEGER)")
+	assert.Error(t, err)
+}
@@ -0,0 +1,189 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/turbot/pipe-fittings/options"
+	"github.com/turbot/pipe-fittings/queryresult"
+	"github.com/turbot/pipe-fittings/sperr"
+)
+
+// QueryTiming records the timing of a query executed by Execute
+// it is populated before the result row channel is closed
+type QueryTiming struct {
+	StartTime    time.Time     `json:"start_time"`
+	Duration     time.Duration `json:"duration"`
+	RowsReturned int64         `json:"rows_returned"`
+}
+
+// GetTiming implements queryresult.TimingContainer
+func (t *QueryTiming) GetTiming() any {
+	return t
+}
+
+type ExecuteConfig struct {
+	// if zero, the query has no timeout
+	Timeout         time.Duration
+	CellErrorPolicy CellErrorPolicy
+	// if set, the query is executed using this database, otherwise the backend is connected to (and closed)
+	// for each query
+	DB             *sql.DB
+	ConnectOptions []ConnectOption
+}
+
+type ExecuteOption func(*ExecuteConfig)
+
+// WithTimeout sets the timeout for the query
+func WithTimeout(timeout time.Duration) ExecuteOption {
+	return func(c *ExecuteConfig) {
+		c.Timeout = timeout
+	}
+}
+
+// WithQueryOptions applies the query options, i.e. the query timeout
+func WithQueryOptions(opts *options.Query) ExecuteOption {
+	return func(c *ExecuteConfig) {
+		if opts != nil && opts.Timeout != nil {
+			c.Timeout = time.Duration(*opts.Timeout) * time.Second
+		}
+	}
+}
+
+// WithCellErrorPolicy sets the policy used if a cell cannot be read
+func WithCellErrorPolicy(policy CellErrorPolicy) ExecuteOption {
+	return func(c *ExecuteConfig) {
+		c.CellErrorPolicy = policy
+	}
+}
+
+// WithDB executes the query using an existing database connection pool
+func WithDB(db *sql.DB) ExecuteOption {
+	return func(c *ExecuteConfig) {
+		c.DB = db
+	}
+}
+
+// WithConnectOptions sets the options used to connect to the backend
+func WithConnectOptions(opts ...ConnectOption) ExecuteOption {
+	return func(c *ExecuteConfig) {
+		c.ConnectOptions = opts
+	}
+}
+
+// Executor executes queries against a backend, streaming the rows into a queryresult.Result
+type Executor struct {
+	backend Backend
+	config  ExecuteConfig
+}
+
+func NewExecutor(backend Backend, opts ...ExecuteOption) *Executor {
+	e := &Executor{backend: backend}
+	for _, opt := range opts {
+		opt(&e.config)
+	}
+	return e
+}
+
+// Execute executes a query against the backend using the default options
+func Execute(ctx context.Context, backend Backend, query string, args ...any) (*queryresult.Result[*QueryTiming], error) {
+	return NewExecutor(backend).Execute(ctx, query, args...)
+}
+
+// Execute executes the query and returns a Result whose rows are streamed on the RowChan.
+// An error is returned if the query cannot be started - errors which occur while reading rows are streamed.
+// If the context is cancelled, streaming stops and the RowChan is closed
+func (e *Executor) Execute(ctx context.Context, query string, args ...any) (_ *queryresult.Result[*QueryTiming], err error) {
+	timing := &QueryTiming{StartTime: time.Now()}
+
+	var queryCtx context.Context
+	var cancel context.CancelFunc
+	if e.config.Timeout > 0 {
+		queryCtx, cancel = context.WithTimeout(ctx, e.config.Timeout)
+	} else {
+		queryCtx, cancel = context.WithCancel(ctx)
+	}
+
+	db := e.config.DB
+	closeDB := func() {}
+	if db == nil {
+		db, err = e.backend.Connect(queryCtx, e.config.ConnectOptions...)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		closeDB = func() { db.Close() }
+	}
+
+	rows, err := db.QueryContext(queryCtx, query, args...)
+	if err != nil {
+		cancel()
+		closeDB()
+		return nil, sperr.WrapWithMessage(err, "failed to execute query")
+	}
+
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		rows.Close()
+		cancel()
+		closeDB()
+		return nil, sperr.WrapWithMessage(err, "failed to read column types")
+	}
+	cols := ColumnDefsFromColumnTypes(e.backend.RowReader(), colTypes)
+	result := queryresult.NewResult(cols, timing)
+
+	go func() {
+		defer func() {
+			rows.Close()
+			cancel()
+			closeDB()
+			timing.Duration = time.Since(timing.StartTime)
+			result.Close()
+		}()
+		e.streamRows(ctx, rows, result)
+	}()
+
+	return result, nil
+}
+
+// streamRows reads the rows and streams them on the result RowChan.
+// Streaming stops if the parent context is cancelled, i.e. the caller is no longer reading results
+func (e *Executor) streamRows(ctx context.Context, rows *sql.Rows, result *queryresult.Result[*QueryTiming]) {
+	send := func(rowResult *queryresult.RowResult) bool {
+		select {
+		case result.RowChan <- rowResult:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	columnValues := make([]any, len(result.Cols))
+	columnPointers := make([]any, len(result.Cols))
+	for i := range columnValues {
+		columnPointers[i] = &columnValues[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(columnPointers...); err != nil {
+			send(&queryresult.RowResult{Error: sperr.WrapWithMessage(err, "failed to scan row")})
+			return
+		}
+		rowResult, err := ReadRow(e.backend.RowReader(), columnValues, result.Cols, e.config.CellErrorPolicy)
+		if err != nil {
+			send(&queryresult.RowResult{Error: err})
+			return
+		}
+		if !send(rowResult) {
+			return
+		}
+		if rowResult.Error == nil {
+			result.Timing.RowsReturned++
+		}
+	}
+	// this will include any timeout or cancellation of the query context
+	if err := rows.Err(); err != nil {
+		send(&queryresult.RowResult{Error: err})
+	}
+}
@@ -0,0 +1,113 @@
+package backend
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/queryresult"
+)
+
+func newTestSqliteBackend(t *testing.T) *SqliteBackend {
+	b := NewSqliteBackend("sqlite:" + filepath.Join(t.TempDir(), "execute.db"))
+	db, err := b.Connect(context.Background())
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE TABLE item (id INTEGER, name TEXT, doc JSON);
+INSERT INTO item VALUES (1, 'a', '{"x": 1}'), (2, 'b', '{not json'), (3, 'c', '{"x": 3}');`)
+	require.NoError(t, err)
+	return b
+}
+
+func collectRows(result *queryresult.Result[*QueryTiming]) []*queryresult.RowResult {
+	var rows []*queryresult.RowResult
+	for row := range result.RowChan {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestExecute(t *testing.T) {
+	b := newTestSqliteBackend(t)
+
+	result, err := Execute(context.Background(), b, "SELECT id, name FROM item WHERE id < ? ORDER BY id", 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, []*queryresult.ColumnDef{
+		{Name: "id", DataType: queryresult.DataTypeInt},
+		{Name: "name", DataType: queryresult.DataTypeText},
+	}, result.Cols)
+
+	rows := collectRows(result)
+	require.Len(t, rows, 2)
+	assert.Equal(t, []any{int64(1), "a"}, rows[0].Data)
+	assert.Equal(t, []any{int64(2), "b"}, rows[1].Data)
+
+	assert.Equal(t, int64(2), result.Timing.RowsReturned)
+	assert.False(t, result.Timing.StartTime.IsZero())
+	assert.Greater(t, result.Timing.Duration, time.Duration(0))
+}
+
+func TestExecuteCellErrorPolicy(t *testing.T) {
+	b := newTestSqliteBackend(t)
+	query := "SELECT id, doc FROM item ORDER BY id"
+
+	result, err := NewExecutor(b).Execute(context.Background(), query)
+	require.NoError(t, err)
+	rows := collectRows(result)
+	require.Len(t, rows, 3)
+	assert.Error(t, rows[1].Error)
+	assert.Equal(t, int64(2), result.Timing.RowsReturned)
+
+	result, err = NewExecutor(b, WithCellErrorPolicy(CellErrorFailQuery)).Execute(context.Background(), query)
+	require.NoError(t, err)
+	rows = collectRows(result)
+	require.Len(t, rows, 2)
+	assert.Error(t, rows[1].Error)
+
+	result, err = NewExecutor(b, WithCellErrorPolicy(CellErrorNullWithWarning)).Execute(context.Background(), query)
+	require.NoError(t, err)
+	rows = collectRows(result)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []any{int64(2), nil}, rows[1].Data)
+	assert.Len(t, rows[1].Warnings, 1)
+}
+
+func TestExecuteCancel(t *testing.T) {
+	b := newTestSqliteBackend(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result, err := Execute(ctx, b, "SELECT id FROM item")
+	require.NoError(t, err)
+
+	// read a single row then cancel - the row channel must be closed
+	<-result.RowChan
+	cancel()
+
+	select {
+	case <-drain(result.RowChan):
+	case <-time.After(5 * time.Second):
+		t.Fatal("row channel was not closed after cancellation")
+	}
+}
+
+func drain(c chan *queryresult.RowResult) chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		for range c {
+		}
+		close(done)
+	}()
+	return done
+}
+
+func TestExecuteInvalidQuery(t *testing.T) {
+	b := newTestSqliteBackend(t)
+
+	_, err := Execute(context.Background(), b, "SELECT * FROM missing")
+	assert.ErrorContains(t, err, "failed to execute query")
+}
@@ -0,0 +1,81 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/turbot/pipe-fittings/sperr"
+)
+
+// DetectionError is returned if a Detector could not determine whether it recognises a backend,
+// for example because the database could not be queried.
+// This is distinct from the backend not being recognised, in which case the original backend is used
+type DetectionError struct {
+	// the name of the backend being detected, e.g. steampipe
+	BackendName string
+	Err         error
+}
+
+func (e *DetectionError) Error() string {
+	return fmt.Sprintf("failed to detect %s backend: %s", e.BackendName, e.Err.Error())
+}
+
+func (e *DetectionError) Unwrap() error {
+	return e.Err
+}
+
+// ping connects to the backend and pings the database, retrying using the configured RetryConfig
+func ping(ctx context.Context, b Backend, opts []ConnectOption) error {
+	db, err := connectWithRetry(ctx, b, opts)
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+// connectWithRetry connects to the backend and pings the database to verify the connection,
+// retrying with exponential backoff using the configured RetryConfig
+func connectWithRetry(ctx context.Context, b Backend, opts []ConnectOption) (*sql.DB, error) {
+	retryConfig := NewConnectConfig(opts).RetryConfig
+	backoff := retryConfig.Backoff
+
+	for attempt := 0; ; attempt++ {
+		db, err := connectAndPing(ctx, b, opts)
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= retryConfig.MaxRetries || !isRetryableConnectError(ctx, err) {
+			return nil, err
+		}
+
+		slog.Debug("failed to connect to backend - retrying", "backend", b.Name(), "attempt", attempt+1, "backoff", backoff, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, err
+		}
+		backoff = min(backoff*2, retryConfig.MaxBackoff)
+	}
+}
+
+func connectAndPing(ctx context.Context, b Backend, opts []ConnectOption) (*sql.DB, error) {
+	db, err := b.Connect(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, sperr.WrapWithMessage(err, "could not connect to %s backend", b.Name())
+	}
+	return db, nil
+}
+
+// isRetryableConnectError returns false for configuration errors and if the context is done,
+// as retrying will not help
+func isRetryableConnectError(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && !errors.Is(err, ErrInvalidConfig)
+}
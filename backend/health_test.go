@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyBackend is a sqlite backend which fails to connect the first failures times
+type flakyBackend struct {
+	*SqliteBackend
+	failures int
+	attempts int
+}
+
+func (b *flakyBackend) Connect(ctx context.Context, opts ...ConnectOption) (*sql.DB, error) {
+	b.attempts++
+	if b.attempts <= b.failures {
+		return nil, errors.New("connection refused")
+	}
+	return b.SqliteBackend.Connect(ctx, opts...)
+}
+
+func TestConnectWithRetry(t *testing.T) {
+	ctx := context.Background()
+	newBackend := func(failures int) *flakyBackend {
+		return &flakyBackend{SqliteBackend: NewSqliteBackend("sqlite:" + filepath.Join(t.TempDir(), "test.db")), failures: failures}
+	}
+	retry := WithRetryConfig(RetryConfig{MaxRetries: 2, Backoff: time.Millisecond})
+
+	// no retries by default
+	b := newBackend(1)
+	assert.Error(t, ping(ctx, b, nil))
+	assert.Equal(t, 1, b.attempts)
+
+	b = newBackend(2)
+	require.NoError(t, ping(ctx, b, []ConnectOption{retry}))
+	assert.Equal(t, 3, b.attempts)
+
+	b = newBackend(3)
+	assert.Error(t, ping(ctx, b, []ConnectOption{retry}))
+	assert.Equal(t, 3, b.attempts)
+
+	// configuration errors are not retried
+	b = newBackend(0)
+	err := ping(ctx, b, []ConnectOption{retry, WithSearchPathConfig(SearchPathConfig{SearchPath: []string{"a"}, SearchPathPrefix: []string{"b"}})})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.Equal(t, 1, b.attempts)
+
+	// retries stop when the context is cancelled
+	b = newBackend(3)
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.Error(t, ping(cancelledCtx, b, []ConnectOption{retry}))
+	assert.Equal(t, 1, b.attempts)
+}
+
+func TestPing(t *testing.T) {
+	b := NewSqliteBackend("sqlite:" + filepath.Join(t.TempDir(), "test.db"))
+	assert.NoError(t, b.Ping(context.Background()))
+
+	// the parent directory does not exist, so the database cannot be opened
+	b = NewSqliteBackend("sqlite:" + filepath.Join(t.TempDir(), "missing", "test.db"))
+	assert.Error(t, b.Ping(context.Background()))
+}
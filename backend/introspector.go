@@ -0,0 +1,137 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/queryresult"
+	"github.com/turbot/pipe-fittings/sperr"
+)
+
+// Introspector is implemented by backends which can describe the schemas, tables and columns of a database
+type Introspector interface {
+	ListSchemas(ctx context.Context, db *sql.DB) ([]string, error)
+	ListTables(ctx context.Context, db *sql.DB, schema string) ([]string, error)
+	// ListColumns returns the columns of the table, in ordinal order
+	// the column DataType is the canonical data type (see queryresult), as reported for query results
+	ListColumns(ctx context.Context, db *sql.DB, schema, table string) ([]*queryresult.ColumnDef, error)
+	// ListPrimaryKeys returns the names of the primary key columns of the table, in key order
+	ListPrimaryKeys(ctx context.Context, db *sql.DB, schema, table string) ([]string, error)
+}
+
+// infoSchemaIntrospector is an Introspector implementation for databases which support information_schema
+type infoSchemaIntrospector struct {
+	// placeholder returns the bind parameter placeholder for the given (1-based) argument index
+	placeholder func(int) string
+	// dataTypeColumn is the information_schema.columns column used to populate the ColumnDef DataType
+	dataTypeColumn string
+	// dataTypes maps the database type names to canonical data types, as for query results
+	dataTypes map[string]string
+}
+
+func (i infoSchemaIntrospector) ListSchemas(ctx context.Context, db *sql.DB) ([]string, error) {
+	query := `SELECT DISTINCT schema_name FROM information_schema.schemata ORDER BY schema_name;`
+
+	res, err := queryStrings(ctx, db, query)
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to read schema names from the database")
+	}
+	return res, nil
+}
+
+func (i infoSchemaIntrospector) ListTables(ctx context.Context, db *sql.DB, schema string) ([]string, error) {
+	query := fmt.Sprintf(`SELECT DISTINCT table_name FROM information_schema.tables WHERE table_schema = %s ORDER BY table_name;`, i.placeholder(1))
+
+	res, err := queryStrings(ctx, db, query, schema)
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to read table names for schema '%s'", schema)
+	}
+	return res, nil
+}
+
+func (i infoSchemaIntrospector) ListColumns(ctx context.Context, db *sql.DB, schema, table string) ([]*queryresult.ColumnDef, error) {
+	query := fmt.Sprintf(`SELECT column_name, %s FROM information_schema.columns WHERE table_schema = %s AND table_name = %s ORDER BY ordinal_position;`,
+		i.dataTypeColumn, i.placeholder(1), i.placeholder(2))
+
+	rows, err := db.QueryContext(ctx, query, schema, table)
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to read columns for table '%s.%s'", schema, table)
+	}
+	defer rows.Close()
+
+	var cols []*queryresult.ColumnDef
+	for rows.Next() {
+		var name, dataType string
+		if err := rows.Scan(&name, &dataType); err != nil {
+			return nil, sperr.WrapWithMessage(err, "failed to read columns for table '%s.%s'", schema, table)
+		}
+		cols = append(cols, &queryresult.ColumnDef{Name: name, DataType: canonicalDataType(dataType, i.dataTypes)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to read columns for table '%s.%s'", schema, table)
+	}
+	return cols, nil
+}
+
+func (i infoSchemaIntrospector) ListPrimaryKeys(ctx context.Context, db *sql.DB, schema, table string) ([]string, error) {
+	query := fmt.Sprintf(`SELECT kcu.column_name
+FROM information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu
+  ON tc.constraint_name = kcu.constraint_name
+  AND tc.table_schema = kcu.table_schema
+  AND tc.table_name = kcu.table_name
+WHERE tc.constraint_type = 'PRIMARY KEY'
+  AND tc.table_schema = %s
+  AND tc.table_name = %s
+ORDER BY kcu.ordinal_position;`, i.placeholder(1), i.placeholder(2))
+
+	res, err := queryStrings(ctx, db, query, schema, table)
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to read primary keys for table '%s.%s'", schema, table)
+	}
+	return res, nil
+}
+
+// queryStrings executes a query returning a single string column and returns the values
+func queryStrings(ctx context.Context, db *sql.DB, query string, args ...any) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// dollarPlaceholder returns a postgres style placeholder, e.g. $1
+func dollarPlaceholder(i int) string {
+	return fmt.Sprintf("$%d", i)
+}
+
+// questionPlaceholder returns a mysql/sqlite style placeholder, i.e. ?
+func questionPlaceholder(int) string {
+	return "?"
+}
+
+// quoteIdentifier quotes an identifier using double quotes, escaping any embedded quotes
+func quoteIdentifier(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// quoteLiteral quotes a string literal using single quotes, escaping any embedded quotes
+func quoteLiteral(s string) string {
+	return `'` + strings.ReplaceAll(s, `'`, `''`) + `'`
+}
@@ -0,0 +1,80 @@
+package backend
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/queryresult"
+)
+
+func TestIntrospector(t *testing.T) {
+	ctx := context.Background()
+	// the backends to introspect, with the name of their default schema, keyed by backend name
+	backends := map[string]struct {
+		backend Backend
+		schema  string
+	}{
+		"sqlite": {
+			backend: NewSqliteBackend("sqlite:" + filepath.Join(t.TempDir(), "test.db")),
+			schema:  "main",
+		},
+		"duckdb": {
+			backend: NewDuckDBBackend("duckdb:" + filepath.Join(t.TempDir(), "test.db") + "?install_extensions=false"),
+			schema:  "main",
+		},
+	}
+
+	for name, tc := range backends {
+		t.Run(name, func(t *testing.T) {
+			introspector, ok := tc.backend.(Introspector)
+			require.True(t, ok, "backend does not implement Introspector")
+
+			db, err := tc.backend.Connect(ctx)
+			require.NoError(t, err)
+			defer db.Close()
+
+			_, err = db.ExecContext(ctx, `CREATE TABLE orders (region VARCHAR, id INTEGER, amount DOUBLE, note TEXT, PRIMARY KEY (id, region));`)
+			require.NoError(t, err)
+			_, err = db.ExecContext(ctx, `CREATE TABLE notes (body TEXT);`)
+			require.NoError(t, err)
+
+			schemas, err := introspector.ListSchemas(ctx, db)
+			require.NoError(t, err)
+			assert.Contains(t, schemas, tc.schema)
+
+			tables, err := introspector.ListTables(ctx, db, tc.schema)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"notes", "orders"}, tables)
+
+			tables, err = introspector.ListTables(ctx, db, "missing")
+			if name == "sqlite" {
+				// sqlite schemas are attached databases, so querying a missing schema is an error
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Empty(t, tables)
+			}
+
+			cols, err := introspector.ListColumns(ctx, db, tc.schema, "orders")
+			require.NoError(t, err)
+			// the data types are the canonical data types, as reported for query results
+			assert.Equal(t, []*queryresult.ColumnDef{
+				{Name: "region", DataType: queryresult.DataTypeText},
+				{Name: "id", DataType: queryresult.DataTypeInt},
+				{Name: "amount", DataType: queryresult.DataTypeFloat},
+				{Name: "note", DataType: queryresult.DataTypeText},
+			}, cols)
+
+			pks, err := introspector.ListPrimaryKeys(ctx, db, tc.schema, "orders")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"id", "region"}, pks)
+
+			pks, err = introspector.ListPrimaryKeys(ctx, db, tc.schema, "notes")
+			require.NoError(t, err)
+			assert.Empty(t, pks)
+		})
+	}
+}
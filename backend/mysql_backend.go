@@ -2,22 +2,33 @@ package backend
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
-	"strconv"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"os"
 	"strings"
-	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/turbot/pipe-fittings/constants"
-
 	"github.com/turbot/pipe-fittings/queryresult"
 	"github.com/turbot/pipe-fittings/sperr"
 )
 
 const (
 	mysqlConnectionStringPrefix = "mysql://"
+
+	// connection string parameters naming the certificate files used to build the tls config - these are removed
+	// from the parameters passed to mysql
+	MySQLSslCaParam   = "ssl-ca"
+	MySQLSslCertParam = "ssl-cert"
+	MySQLSslKeyParam  = "ssl-key"
 )
 
 type MySQLBackend struct {
+	infoSchemaIntrospector
 	connectionString string
 	rowreader        RowReader
 }
@@ -27,6 +38,11 @@ func NewMySQLBackend(connString string) *MySQLBackend {
 	connString = strings.TrimPrefix(connString, mysqlConnectionStringPrefix)
 
 	return &MySQLBackend{
+		infoSchemaIntrospector: infoSchemaIntrospector{
+			placeholder:    questionPlaceholder,
+			dataTypeColumn: "data_type",
+			dataTypes:      mysqlDataTypes,
+		},
 		connectionString: connString,
 		rowreader:        newMySqlRowReader(),
 	}
@@ -35,16 +51,96 @@ func NewMySQLBackend(connString string) *MySQLBackend {
 // Connect implements Backend.
 func (b *MySQLBackend) Connect(_ context.Context, options ...ConnectOption) (*sql.DB, error) {
 	config := NewConnectConfig(options)
-	db, err := sql.Open("mysql", b.connectionString)
+	connector, err := newMySQLConnector(b.connectionString, config.TLSConfig, func(ctx context.Context, conn driver.Conn) error {
+		if config.ReadOnly {
+			return execDriverConn(ctx, conn, "SET SESSION TRANSACTION READ ONLY;")
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, sperr.WrapWithMessage(err, "could not connect to mysql backend")
 	}
+	db := sql.OpenDB(connector)
 	db.SetConnMaxIdleTime(config.MaxConnIdleTime)
 	db.SetConnMaxLifetime(config.MaxConnLifeTime)
 	db.SetMaxOpenConns(config.MaxOpenConns)
 	return db, nil
 }
 
+// newMySQLConnector creates a connector for the data source name - if tlsConfig is set, it is used rather than
+// any tls config named or built from the certificate files of the data source name
+func newMySQLConnector(dataSourceName string, tlsConfig *tls.Config, afterConnectFunc func(context.Context, driver.Conn) error) (driver.Connector, error) {
+	mysqlConfig, err := mysql.ParseDSN(dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	certificateTLSConfig, err := mysqlCertificateTLSConfig(mysqlConfig)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil {
+		tlsConfig = certificateTLSConfig
+	}
+	if tlsConfig != nil {
+		mysqlConfig.TLS = tlsConfig.Clone()
+	}
+	connector, err := mysql.NewConnector(mysqlConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &afterConnectConnector{
+		Connector:        connector,
+		afterConnectFunc: afterConnectFunc,
+	}, nil
+}
+
+// mysqlCertificateTLSConfig builds the tls config for the ssl-ca, ssl-cert and ssl-key parameters of the config, or
+// returns nil if none are set. The parameters are removed, so they are not sent to mysql as system variables
+func mysqlCertificateTLSConfig(mysqlConfig *mysql.Config) (*tls.Config, error) {
+	caFile := mysqlConfig.Params[MySQLSslCaParam]
+	certFile := mysqlConfig.Params[MySQLSslCertParam]
+	keyFile := mysqlConfig.Params[MySQLSslKeyParam]
+	delete(mysqlConfig.Params, MySQLSslCaParam)
+	delete(mysqlConfig.Params, MySQLSslCertParam)
+	delete(mysqlConfig.Params, MySQLSslKeyParam)
+	if caFile == "" && certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+
+	// start from the tls config of the tls parameter, e.g. to skip verification
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if mysqlConfig.TLS != nil {
+		config = mysqlConfig.TLS.Clone()
+		config.MinVersion = max(config.MinVersion, tls.VersionTLS12)
+	}
+	if config.ServerName == "" {
+		config.ServerName, _, _ = net.SplitHostPort(mysqlConfig.Addr)
+	}
+	if caFile != "" {
+		caCert, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s %s: %s", MySQLSslCaParam, caFile, err.Error())
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to parse %s %s", MySQLSslCaParam, caFile)
+		}
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s and %s: %s", MySQLSslCertParam, MySQLSslKeyParam, err.Error())
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// Ping implements Backend.
+func (b *MySQLBackend) Ping(ctx context.Context, options ...ConnectOption) error {
+	return ping(ctx, b, options)
+}
+
 func (b *MySQLBackend) ConnectionString() string {
 	return b.connectionString
 }
@@ -58,6 +154,41 @@ func (b *MySQLBackend) RowReader() RowReader {
 	return b.rowreader
 }
 
+// mysqlDataTypes maps the mysql driver database type names to canonical data types
+var mysqlDataTypes = map[string]string{
+	"TINYINT":            queryresult.DataTypeInt,
+	"SMALLINT":           queryresult.DataTypeInt,
+	"MEDIUMINT":          queryresult.DataTypeInt,
+	"INT":                queryresult.DataTypeInt,
+	"BIGINT":             queryresult.DataTypeInt,
+	"YEAR":               queryresult.DataTypeInt,
+	"UNSIGNED TINYINT":   queryresult.DataTypeInt,
+	"UNSIGNED SMALLINT":  queryresult.DataTypeInt,
+	"UNSIGNED MEDIUMINT": queryresult.DataTypeInt,
+	"UNSIGNED INT":       queryresult.DataTypeInt,
+	"UNSIGNED BIGINT":    queryresult.DataTypeInt,
+	"DECIMAL":            queryresult.DataTypeDecimal,
+	"FLOAT":              queryresult.DataTypeFloat,
+	"DOUBLE":             queryresult.DataTypeFloat,
+	"DATETIME":           queryresult.DataTypeTimestamp,
+	"JSON":               queryresult.DataTypeJSON,
+	"CHAR":               queryresult.DataTypeText,
+	"VARCHAR":            queryresult.DataTypeText,
+	"TINYTEXT":           queryresult.DataTypeText,
+	"MEDIUMTEXT":         queryresult.DataTypeText,
+	"LONGTEXT":           queryresult.DataTypeText,
+	"ENUM":               queryresult.DataTypeText,
+	"SET":                queryresult.DataTypeText,
+	"BINARY":             queryresult.DataTypeBytes,
+	"VARBINARY":          queryresult.DataTypeBytes,
+	"BLOB":               queryresult.DataTypeBytes,
+	"TINYBLOB":           queryresult.DataTypeBytes,
+	"MEDIUMBLOB":         queryresult.DataTypeBytes,
+	"LONGBLOB":           queryresult.DataTypeBytes,
+	"BIT":                queryresult.DataTypeBytes,
+	"GEOMETRY":           queryresult.DataTypeBytes,
+}
+
 type mysqlRowReader struct {
 	BasicRowReader
 }
@@ -65,36 +196,18 @@ type mysqlRowReader struct {
 func newMySqlRowReader() RowReader {
 	return &mysqlRowReader{
 		BasicRowReader: BasicRowReader{
-			CellReader: mysqlReadCell,
+			CellReader:  mysqlReadCell,
+			DataTypes:   mysqlDataTypes,
+			BackendName: constants.MySQLBackendName,
 		},
 	}
 }
 
-func mysqlReadCell(columnValue any, col *queryresult.ColumnDef) (result any, err error) {
-	if columnValue != nil {
-		switch t := columnValue.(type) {
-		case []byte:
-			asStr := string(t)
-			switch col.DataType {
-			case "INT", "TINYINT", "SMALLINT", "MEDIUMINT", "BIGINT", "YEAR":
-				result, err = strconv.ParseInt(asStr, 10, 64)
-			case "DECIMAL", "NUMERIC", "FLOAT", "DOUBLE":
-				result, err = strconv.ParseFloat(asStr, 64)
-			case "DATE":
-				result, err = time.Parse(time.DateOnly, asStr)
-			case "TIME":
-				result, err = time.Parse(time.TimeOnly, asStr)
-			case "DATETIME", "TIMESTAMP":
-				result, err = time.Parse(time.DateTime, asStr)
-			case "BIT":
-				result = columnValue.([]byte)
-			// case "CHAR", "VARCHAR", "TEXT", "BINARY", "VARBINARY", "ENUM", "SET":
-			default:
-				result = asStr
-			}
-		default:
-			return columnValue, nil
-		}
+func mysqlReadCell(columnValue any, col *queryresult.ColumnDef) (any, error) {
+	dataType := canonicalDataType(col.DataType, mysqlDataTypes)
+	// the mysql driver returns most values as []byte - treat values of unrecognised types as text
+	if b, ok := columnValue.([]byte); ok && !queryresult.IsCanonicalDataType(dataType) {
+		return string(b), nil
 	}
-	return result, err
+	return normaliseValue(columnValue, dataType)
 }
@@ -0,0 +1,86 @@
+package backend
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/sslio"
+)
+
+func TestNewMySQLConnectorTLSConfig(t *testing.T) {
+	// a tls config name which has not been registered with the driver is an error
+	_, err := newMySQLConnector("root@tcp(db.example.com:3306)/mysql?tls=unregistered", nil, nil)
+	assert.Error(t, err)
+
+	// the tls config is used instead, and the server name is set on a copy, leaving the caller's config unchanged
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	connector, err := newMySQLConnector("root@tcp(db.example.com:3306)/mysql?tls=true", tlsConfig, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, connector)
+	assert.Empty(t, tlsConfig.ServerName)
+}
+
+func TestMySQLCertificateTLSConfig(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t)
+	dsn := fmt.Sprintf("root@tcp(db.example.com:3306)/mysql?tls=true&ssl-ca=%s&ssl-cert=%s&ssl-key=%s",
+		url.QueryEscape(certFile), url.QueryEscape(certFile), url.QueryEscape(keyFile))
+	mysqlConfig, err := mysql.ParseDSN(dsn)
+	require.NoError(t, err)
+
+	tlsConfig, err := mysqlCertificateTLSConfig(mysqlConfig)
+	require.NoError(t, err)
+	require.NotNil(t, tlsConfig)
+	assert.Equal(t, "db.example.com", tlsConfig.ServerName)
+	assert.NotNil(t, tlsConfig.RootCAs)
+	assert.Len(t, tlsConfig.Certificates, 1)
+	// the certificate parameters are not sent to mysql
+	assert.Empty(t, mysqlConfig.Params)
+
+	_, err = newMySQLConnector(dsn, nil, nil)
+	require.NoError(t, err)
+
+	// the certificate files are read when connecting
+	_, err = newMySQLConnector("root@tcp(db.example.com:3306)/mysql?tls=true&ssl-ca="+url.QueryEscape(filepath.Join(t.TempDir(), "missing.crt")), nil, nil)
+	assert.Error(t, err)
+
+	// without certificate parameters, there is no certificate tls config
+	mysqlConfig, err = mysql.ParseDSN("root@tcp(db.example.com:3306)/mysql?tls=true")
+	require.NoError(t, err)
+	tlsConfig, err = mysqlCertificateTLSConfig(mysqlConfig)
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+}
+
+func writeTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "db.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	require.NoError(t, sslio.WriteCertificate(certFile, cert))
+	require.NoError(t, sslio.WritePrivateKey(keyFile, key))
+	return certFile, keyFile
+}
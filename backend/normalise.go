@@ -0,0 +1,396 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/turbot/pipe-fittings/queryresult"
+	"github.com/turbot/pipe-fittings/utils"
+)
+
+// DataTypeMapper is implemented by RowReaders which can map the database type names returned by the driver
+// to the canonical data types defined in the queryresult package
+type DataTypeMapper interface {
+	CanonicalDataType(databaseTypeName string) string
+}
+
+// matches duckdb style array suffixes, e.g. VARCHAR[] or INTEGER[3]
+var arraySuffixRegex = regexp.MustCompile(`\[\d*]$`)
+
+// canonicalDataType maps a database type name to a canonical data type using the backend specific lookup.
+// Type parameters are ignored, e.g. DECIMAL(10,2) is looked up as DECIMAL.
+// If the lookup does not recognise the type, the (upper case) database type name is returned
+func canonicalDataType(databaseTypeName string, lookup map[string]string) string {
+	t := strings.ToUpper(strings.TrimSpace(databaseTypeName))
+	if queryresult.IsCanonicalDataType(t) {
+		return t
+	}
+	// postgres style arrays, e.g. _TEXT
+	if elem, ok := queryresult.ArrayElementDataType(t); ok {
+		return queryresult.ArrayDataType(canonicalDataType(elem, lookup))
+	}
+	// duckdb style arrays, e.g. VARCHAR[]
+	if loc := arraySuffixRegex.FindStringIndex(t); loc != nil && loc[0] > 0 {
+		return queryresult.ArrayDataType(canonicalDataType(t[:loc[0]], lookup))
+	}
+	// strip type parameters
+	if idx := strings.Index(t, "("); idx > 0 {
+		t = strings.TrimSpace(t[:idx])
+	}
+	if res, ok := lookup[t]; ok {
+		return res
+	}
+	return t
+}
+
+// normaliseValue converts a value returned by a database/sql driver into the canonical Go representation
+// for the given canonical data type. Values of unrecognised data types are returned unchanged
+func normaliseValue(value any, dataType string) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	if elemType, ok := queryresult.ArrayElementDataType(dataType); ok {
+		arr, ok := value.([]any)
+		if !ok {
+			return value, nil
+		}
+		res := make([]any, len(arr))
+		for i, e := range arr {
+			v, err := normaliseValue(e, elemType)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = v
+		}
+		return res, nil
+	}
+
+	switch dataType {
+	case queryresult.DataTypeText:
+		return normaliseText(value), nil
+	case queryresult.DataTypeInt:
+		return normaliseInt(value)
+	case queryresult.DataTypeFloat:
+		return normaliseFloat(value)
+	case queryresult.DataTypeDecimal:
+		return normaliseDecimal(value)
+	case queryresult.DataTypeBool:
+		return normaliseBool(value)
+	case queryresult.DataTypeJSON:
+		return normaliseJSON(value)
+	case queryresult.DataTypeDate, queryresult.DataTypeTimestamp, queryresult.DataTypeTimestampTz:
+		return normaliseTimestamp(value)
+	case queryresult.DataTypeTime:
+		return normaliseTime(value), nil
+	case queryresult.DataTypeInterval:
+		return normaliseInterval(value), nil
+	case queryresult.DataTypeUUID:
+		return normaliseUUID(value)
+	case queryresult.DataTypeInet:
+		return normaliseText(value), nil
+	case queryresult.DataTypeBytes:
+		if s, ok := value.(string); ok {
+			return []byte(s), nil
+		}
+		return value, nil
+	}
+	return value, nil
+}
+
+func normaliseText(value any) any {
+	switch t := value.(type) {
+	case []byte:
+		return string(t)
+	case fmt.Stringer:
+		return t.String()
+	}
+	return value
+}
+
+func normaliseInt(value any) (any, error) {
+	switch t := value.(type) {
+	case []byte:
+		return strconv.ParseInt(string(t), 10, 64)
+	case string:
+		return strconv.ParseInt(t, 10, 64)
+	case bool:
+		if t {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case *big.Int:
+		if !t.IsInt64() {
+			return nil, fmt.Errorf("integer value %s is out of range", t.String())
+		}
+		return t.Int64(), nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("integer value %d is out of range", v.Uint())
+		}
+		return int64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		// only convert floats which are integral, so no part of the value is lost
+		f := v.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return nil, fmt.Errorf("value %v is not an integer", f)
+		}
+		return int64(f), nil
+	}
+	return nil, fmt.Errorf("cannot convert %T to an integer", value)
+}
+
+func normaliseFloat(value any) (any, error) {
+	switch t := value.(type) {
+	case []byte:
+		return strconv.ParseFloat(string(t), 64)
+	case string:
+		return strconv.ParseFloat(t, 64)
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(t).Float64()
+		return f, nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	}
+	return nil, fmt.Errorf("cannot convert %T to a number", value)
+}
+
+// matches the text representation of a decimal number, including the postgres special values
+var decimalRegex = regexp.MustCompile(`^([+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?|NaN|[+-]?Infinity)$`)
+
+// normaliseDecimal converts a decimal value to its exact text representation, e.g. 12.34, so that no precision
+// is lost (as would be the case if it were converted to a float64)
+func normaliseDecimal(value any) (any, error) {
+	var s string
+	switch t := value.(type) {
+	case []byte:
+		s = string(t)
+	case string:
+		s = t
+	case *big.Int:
+		return t.String(), nil
+	case fmt.Stringer:
+		s = t.String()
+	default:
+		v := reflect.ValueOf(value)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return strconv.FormatInt(v.Int(), 10), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return strconv.FormatUint(v.Uint(), 10), nil
+		case reflect.Float32, reflect.Float64:
+			// the value has already been converted to a float by the driver, so format it with the fewest
+			// digits which represent it exactly
+			return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+		case reflect.Struct:
+			// decimal types which store an unscaled big.Int value and a scale, e.g. duckdb.Decimal
+			if d, ok := scaledDecimalToString(v); ok {
+				return d, nil
+			}
+		}
+		return nil, fmt.Errorf("cannot convert %T to a decimal", value)
+	}
+	s = strings.TrimSpace(s)
+	if !decimalRegex.MatchString(s) {
+		return nil, fmt.Errorf("cannot parse '%s' as a decimal", s)
+	}
+	return s, nil
+}
+
+// scaledDecimalToString converts a struct with an unscaled Value *big.Int and an integer Scale to its exact text
+// representation
+func scaledDecimalToString(v reflect.Value) (string, bool) {
+	valueField := v.FieldByName("Value")
+	scaleField := v.FieldByName("Scale")
+	if !valueField.IsValid() || !scaleField.IsValid() || !scaleField.CanUint() {
+		return "", false
+	}
+	unscaled, ok := valueField.Interface().(*big.Int)
+	if !ok || unscaled == nil {
+		return "", false
+	}
+	digits := new(big.Int).Abs(unscaled).String()
+	scale := int(scaleField.Uint())
+	if scale > 0 {
+		// pad with leading zeros so there is at least one digit before the decimal point
+		if len(digits) <= scale {
+			digits = strings.Repeat("0", scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	}
+	if unscaled.Sign() < 0 {
+		digits = "-" + digits
+	}
+	return digits, true
+}
+
+func normaliseBool(value any) (any, error) {
+	switch t := value.(type) {
+	case bool:
+		return t, nil
+	case []byte:
+		return strconv.ParseBool(string(t))
+	case string:
+		return strconv.ParseBool(t)
+	}
+	i, err := normaliseInt(value)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %T to a boolean", value)
+	}
+	return i.(int64) != 0, nil
+}
+
+func normaliseJSON(value any) (any, error) {
+	var raw []byte
+	switch t := value.(type) {
+	case []byte:
+		raw = t
+	case string:
+		raw = []byte(t)
+	default:
+		// maps with non-string keys, e.g. duckdb.Map, are converted to map[string]any
+		v := reflect.ValueOf(value)
+		if v.Kind() == reflect.Map && v.Type().Key().Kind() != reflect.String {
+			res := make(map[string]any, v.Len())
+			iter := v.MapRange()
+			for iter.Next() {
+				res[fmt.Sprintf("%v", iter.Key().Interface())] = iter.Value().Interface()
+			}
+			return res, nil
+		}
+		// otherwise assume the driver has already decoded the value
+		return value, nil
+	}
+	var res any
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// layouts used to parse timestamps returned as text
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	time.DateOnly,
+}
+
+func normaliseTimestamp(value any) (any, error) {
+	var s string
+	switch t := value.(type) {
+	case time.Time:
+		return t, nil
+	case []byte:
+		s = string(t)
+	case string:
+		s = t
+	default:
+		return nil, fmt.Errorf("cannot convert %T to a timestamp", value)
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot parse '%s' as a timestamp", s)
+}
+
+func normaliseTime(value any) any {
+	switch t := value.(type) {
+	case time.Time:
+		return t.Format(time.TimeOnly)
+	case []byte:
+		return string(t)
+	}
+	return value
+}
+
+func normaliseInterval(value any) any {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	// interval types which store months, days and microseconds, e.g. duckdb.Interval
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Struct {
+		return value
+	}
+	months, days, micros := v.FieldByName("Months"), v.FieldByName("Days"), v.FieldByName("Micros")
+	if !months.CanInt() || !days.CanInt() || !micros.CanInt() {
+		return value
+	}
+	return formatInterval(months.Int(), days.Int(), micros.Int())
+}
+
+// formatInterval formats an interval in the same way as postgres, e.g. 1 year 2 mons 3 days 04:05:06
+func formatInterval(months, days, micros int64) string {
+	var sb strings.Builder
+	years := months / 12
+	months = months % 12
+	if years > 0 {
+		sb.WriteString(fmt.Sprintf("%d %s ", years, utils.Pluralize("year", int(years))))
+	}
+	if months > 0 {
+		sb.WriteString(fmt.Sprintf("%d %s ", months, utils.Pluralize("mon", int(months))))
+	}
+	if days > 0 {
+		sb.WriteString(fmt.Sprintf("%d %s ", days, utils.Pluralize("day", int(days))))
+	}
+	if micros > 0 {
+		d := time.Duration(micros) * time.Microsecond
+		formatStr := time.Unix(0, 0).UTC().Add(d).Format("15:04:05")
+		sb.WriteString(formatStr)
+	}
+	return sb.String()
+}
+
+func normaliseUUID(value any) (any, error) {
+	switch t := value.(type) {
+	case string:
+		return t, nil
+	case fmt.Stringer:
+		return t.String(), nil
+	case []byte:
+		if len(t) != 16 {
+			// assume this is the text representation
+			return string(t), nil
+		}
+		u, err := uuid.FromBytes(t)
+		if err != nil {
+			return nil, err
+		}
+		return u.String(), nil
+	}
+	// byte arrays, e.g. [16]uint8 or duckdb.UUID
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8 && v.Len() == 16 {
+		var u uuid.UUID
+		reflect.Copy(reflect.ValueOf(u[:]), v)
+		return u.String(), nil
+	}
+	return nil, fmt.Errorf("cannot convert %T to a uuid", value)
+}
@@ -0,0 +1,337 @@
+package backend
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/sperr"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ParamBinder binds query parameters for the SQL dialect of a backend.
+//
+// Queries are written using postgres style $1, $2 placeholders. For dialects which only support positional
+// ? placeholders (mysql and sqlite), the placeholders are rewritten and the args reordered to match.
+// Arrays and objects are bound as JSON text for all dialects other than postgres
+// (use the JSON functions of the dialect to read them, e.g. json_each)
+type ParamBinder struct {
+	// if set, $n placeholders are rewritten to ?
+	positional bool
+	// if set, backslashes in string literals are escape characters
+	backslashEscapes bool
+	// if nil, values are bound unchanged
+	bindValue func(any) (any, error)
+	literal   func(any) (string, error)
+	// if nil, cty values are converted to go values and passed to literal
+	ctyLiteral func(cty.Value) (string, error)
+}
+
+// NewParamBinder returns the ParamBinder for the backend with the given name, as returned by Backend.Name
+func NewParamBinder(backendName string) (*ParamBinder, error) {
+	b := &ParamBinder{}
+	switch backendName {
+	case constants.PostgresBackendName, constants.SteampipeBackendName:
+		b.literal = hclhelpers.GoToPostgresString
+		b.ctyLiteral = hclhelpers.CtyToPostgresString
+	case constants.DuckDBBackendName:
+		b.bindValue = jsonEncodeComposite
+		b.literal = duckDBLiteral
+	case constants.SQLiteBackendName:
+		b.positional = true
+		b.bindValue = jsonEncodeComposite
+		b.literal = sqliteLiteral
+	case constants.MySQLBackendName:
+		b.positional = true
+		b.backslashEscapes = true
+		b.bindValue = jsonEncodeComposite
+		b.literal = mysqlLiteral
+	default:
+		return nil, sperr.WrapWithMessage(ErrUnknownBackend, "no parameter binder for backend: %s", backendName)
+	}
+	return b, nil
+}
+
+// ParamBinderForBackend returns the ParamBinder for the dialect of the backend
+func ParamBinderForBackend(backend Backend) (*ParamBinder, error) {
+	return NewParamBinder(backend.Name())
+}
+
+// Bind rewrites the query placeholders for the dialect and converts the args into values accepted by the driver
+func (b *ParamBinder) Bind(query string, args ...any) (string, []any, error) {
+	if !b.positional {
+		values, err := b.bindValues(args)
+		if err != nil {
+			return "", nil, err
+		}
+		return query, values, nil
+	}
+
+	// build the positional args in placeholder order - an arg is repeated if its placeholder is
+	var positionalArgs []any
+	query, err := b.replacePlaceholders(query, len(args), func(i int) (string, error) {
+		positionalArgs = append(positionalArgs, args[i-1])
+		return "?", nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	values, err := b.bindValues(positionalArgs)
+	if err != nil {
+		return "", nil, err
+	}
+	return query, values, nil
+}
+
+// Inline returns the query with the placeholders replaced by SQL literals for the args
+func (b *ParamBinder) Inline(query string, args ...any) (string, error) {
+	return b.replacePlaceholders(query, len(args), func(i int) (string, error) {
+		return b.Literal(args[i-1])
+	})
+}
+
+// Literal converts a go value into a SQL literal for the dialect
+func (b *ParamBinder) Literal(v any) (string, error) {
+	return b.literal(v)
+}
+
+// CtyLiteral converts a cty value into a SQL literal for the dialect
+func (b *ParamBinder) CtyLiteral(v cty.Value) (string, error) {
+	if b.ctyLiteral != nil {
+		return b.ctyLiteral(v)
+	}
+	goVal, err := hclhelpers.CtyToGo(v)
+	if err != nil {
+		return "", err
+	}
+	return b.literal(goVal)
+}
+
+func (b *ParamBinder) bindValues(args []any) ([]any, error) {
+	if b.bindValue == nil {
+		return args, nil
+	}
+	res := make([]any, len(args))
+	for i, arg := range args {
+		v, err := b.bindValue(arg)
+		if err != nil {
+			return nil, sperr.WrapWithMessage(err, "failed to bind query parameter %d", i+1)
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+// replacePlaceholders replaces each $n placeholder in the query with the result of replace.
+// Placeholders inside string literals, quoted identifiers and comments are ignored
+func (b *ParamBinder) replacePlaceholders(query string, argCount int, replace func(int) (string, error)) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := b.quotedEnd(query, i)
+			sb.WriteString(query[i:end])
+			i = end
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end == -1 {
+				end = len(query) - i
+			}
+			sb.WriteString(query[i : i+end])
+			i += end
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end == -1 {
+				end = len(query) - i
+			} else {
+				end += 4
+			}
+			sb.WriteString(query[i : i+end])
+			i += end
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			end := i + 1
+			for end < len(query) && isDigit(query[end]) {
+				end++
+			}
+			index, err := strconv.Atoi(query[i+1 : end])
+			if err != nil {
+				return "", sperr.WrapWithMessage(err, "invalid query parameter placeholder '%s'", query[i:end])
+			}
+			if index < 1 || index > argCount {
+				return "", sperr.New("query parameter placeholder '%s' has no matching arg - %d args were provided", query[i:end], argCount)
+			}
+			str, err := replace(index)
+			if err != nil {
+				return "", sperr.WrapWithMessage(err, "failed to bind query parameter %d", index)
+			}
+			sb.WriteString(str)
+			i = end
+		case c == '$':
+			// may be a dollar quoted string, e.g. $tag$...$tag$
+			end := dollarQuotedEnd(query, i)
+			sb.WriteString(query[i:end])
+			i = end
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	return sb.String(), nil
+}
+
+// quotedEnd returns the index after the closing quote of the quoted string or identifier starting at start
+func (b *ParamBinder) quotedEnd(query string, start int) int {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if b.backslashEscapes && quote != '`' {
+				i++
+			}
+		case quote:
+			// a doubled quote is an escaped quote
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+// dollarQuotedEnd returns the index after the dollar quoted string starting at start,
+// or start+1 if this is not the start of a dollar quoted string
+func dollarQuotedEnd(query string, start int) int {
+	tagEnd := start + 1
+	for tagEnd < len(query) && isIdentifierChar(query[tagEnd]) {
+		tagEnd++
+	}
+	if tagEnd >= len(query) || query[tagEnd] != '$' {
+		return start + 1
+	}
+	tag := query[start : tagEnd+1]
+	end := strings.Index(query[tagEnd+1:], tag)
+	if end == -1 {
+		return len(query)
+	}
+	return tagEnd + 1 + end + len(tag)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// jsonEncodeComposite encodes arrays, maps and structs as JSON text - other values are returned unchanged
+func jsonEncodeComposite(v any) (any, error) {
+	if !isComposite(v) {
+		return v, nil
+	}
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(jsonBytes), nil
+}
+
+func isComposite(v any) bool {
+	switch v.(type) {
+	case nil, []byte, time.Time, driver.Valuer:
+		return false
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		return true
+	default:
+		return false
+	}
+}
+
+// scalarLiteral converts a non composite value into a SQL literal using the standard syntax supported by
+// duckdb, sqlite and mysql. If the value is not supported, false is returned
+func scalarLiteral(v any, quoteString func(string) string) (string, bool) {
+	switch arg := v.(type) {
+	case nil:
+		return "NULL", true
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(arg)), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", arg), true
+	case float32:
+		return strconv.FormatFloat(float64(arg), 'f', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(arg, 'f', -1, 64), true
+	case string:
+		return quoteString(arg), true
+	case time.Time:
+		return quoteString(arg.UTC().Format("2006-01-02 15:04:05.999999")), true
+	}
+	return "", false
+}
+
+func jsonLiteral(v any, quoteString func(string) string) (string, error) {
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return quoteString(string(jsonBytes)), nil
+}
+
+func duckDBLiteral(v any) (string, error) {
+	if str, ok := scalarLiteral(v, quoteLiteral); ok {
+		return str, nil
+	}
+	if bytes, ok := v.([]byte); ok {
+		var sb strings.Builder
+		for _, c := range bytes {
+			fmt.Fprintf(&sb, `\x%02X`, c)
+		}
+		return quoteLiteral(sb.String()) + "::BLOB", nil
+	}
+	// composite values are JSON text, as they are when bound, so the query has the same meaning whether
+	// the args are bound or inlined
+	return jsonLiteral(v, quoteLiteral)
+}
+
+func sqliteLiteral(v any) (string, error) {
+	if str, ok := scalarLiteral(v, quoteLiteral); ok {
+		return str, nil
+	}
+	if bytes, ok := v.([]byte); ok {
+		return "X'" + hex.EncodeToString(bytes) + "'", nil
+	}
+	// sqlite has no array or json type - json is stored as text
+	return jsonLiteral(v, quoteLiteral)
+}
+
+func mysqlLiteral(v any) (string, error) {
+	if str, ok := scalarLiteral(v, quoteMySQLLiteral); ok {
+		return str, nil
+	}
+	if bytes, ok := v.([]byte); ok {
+		return "X'" + hex.EncodeToString(bytes) + "'", nil
+	}
+	str, err := jsonLiteral(v, quoteMySQLLiteral)
+	if err != nil {
+		return "", err
+	}
+	return "CAST(" + str + " AS JSON)", nil
+}
+
+// quoteMySQLLiteral quotes a string literal using single quotes, escaping any embedded quotes and backslashes
+// (mysql treats backslash as an escape character unless the NO_BACKSLASH_ESCAPES sql mode is set)
+func quoteMySQLLiteral(s string) string {
+	return quoteLiteral(strings.ReplaceAll(s, `\`, `\\`))
+}
@@ -0,0 +1,153 @@
+package backend
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestParamBinderBind(t *testing.T) {
+	query := `SELECT $2, '$1', "$1" -- $1
+/* $1 */ FROM t WHERE a = $1 AND b = $2 AND c = $$ $1 $$`
+
+	sqlite, err := NewParamBinder(constants.SQLiteBackendName)
+	require.NoError(t, err)
+	res, args, err := sqlite.Bind(query, "x", []string{"y"})
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT ?, '$1', "$1" -- $1
+/* $1 */ FROM t WHERE a = ? AND b = ? AND c = $$ $1 $$`, res)
+	assert.Equal(t, []any{`["y"]`, "x", `["y"]`}, args)
+
+	postgres, err := NewParamBinder(constants.SteampipeBackendName)
+	require.NoError(t, err)
+	res, args, err = postgres.Bind(query, "x", []string{"y"})
+	require.NoError(t, err)
+	assert.Equal(t, query, res)
+	assert.Equal(t, []any{"x", []string{"y"}}, args)
+
+	_, _, err = sqlite.Bind("SELECT $3", "x")
+	assert.Error(t, err)
+	_, err = NewParamBinder("oracle")
+	assert.ErrorIs(t, err, ErrUnknownBackend)
+}
+
+func TestParamBinderLiteral(t *testing.T) {
+	tests := map[string]struct {
+		value    any
+		expected map[string]string
+	}{
+		"string": {
+			value: `it's a \ test`,
+			expected: map[string]string{
+				constants.PostgresBackendName: `'it''s a \ test'`,
+				constants.DuckDBBackendName:   `'it''s a \ test'`,
+				constants.SQLiteBackendName:   `'it''s a \ test'`,
+				constants.MySQLBackendName:    `'it''s a \\ test'`,
+			},
+		},
+		"bool": {
+			value: true,
+			expected: map[string]string{
+				constants.PostgresBackendName: "true",
+				constants.DuckDBBackendName:   "TRUE",
+				constants.SQLiteBackendName:   "TRUE",
+				constants.MySQLBackendName:    "TRUE",
+			},
+		},
+		"bytes": {
+			value: []byte{0x01, 0xab},
+			expected: map[string]string{
+				constants.PostgresBackendName: `'\x01ab'`,
+				constants.DuckDBBackendName:   `'\x01\xAB'::BLOB`,
+				constants.SQLiteBackendName:   `X'01ab'`,
+				constants.MySQLBackendName:    `X'01ab'`,
+			},
+		},
+		"array": {
+			value: []any{"a", "b"},
+			expected: map[string]string{
+				constants.PostgresBackendName: `array['a','b']::text[]`,
+				constants.DuckDBBackendName:   `'["a","b"]'`,
+				constants.SQLiteBackendName:   `'["a","b"]'`,
+				constants.MySQLBackendName:    `CAST('["a","b"]' AS JSON)`,
+			},
+		},
+		"object": {
+			value: map[string]any{"a": 1},
+			expected: map[string]string{
+				constants.PostgresBackendName: `'{"a":1}'::jsonb`,
+				constants.DuckDBBackendName:   `'{"a":1}'`,
+				constants.SQLiteBackendName:   `'{"a":1}'`,
+				constants.MySQLBackendName:    `CAST('{"a":1}' AS JSON)`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		for backendName, expected := range test.expected {
+			b, err := NewParamBinder(backendName)
+			require.NoError(t, err)
+			res, err := b.Literal(test.value)
+			require.NoError(t, err)
+			assert.Equal(t, expected, res, "%s: %s", name, backendName)
+		}
+	}
+
+	b, err := NewParamBinder(constants.DuckDBBackendName)
+	require.NoError(t, err)
+	res, err := b.CtyLiteral(cty.TupleVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2)}))
+	require.NoError(t, err)
+	assert.Equal(t, "'[1,2]'", res)
+}
+
+// TestParamBinderExecute verifies the same query and args can be executed against each backend
+func TestParamBinderExecute(t *testing.T) {
+	ctx := context.Background()
+	query := `SELECT $2 || '-' || $1 AS "value", $1 AS "repeated"`
+	backends := map[string]Backend{
+		"sqlite": NewSqliteBackend("sqlite:" + filepath.Join(t.TempDir(), "test.db")),
+		"duckdb": NewDuckDBBackend("duckdb:" + filepath.Join(t.TempDir(), "test.db") + "?install_extensions=false"),
+	}
+
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			binder, err := ParamBinderForBackend(b)
+			require.NoError(t, err)
+			db, err := b.Connect(ctx)
+			require.NoError(t, err)
+			defer db.Close()
+
+			boundQuery, args, err := binder.Bind(query, "a", "b")
+			require.NoError(t, err)
+			var value, repeated string
+			require.NoError(t, db.QueryRowContext(ctx, boundQuery, args...).Scan(&value, &repeated))
+			assert.Equal(t, "b-a", value)
+			assert.Equal(t, "a", repeated)
+
+			inlined, err := binder.Inline(query, "a", "b")
+			require.NoError(t, err)
+			require.NoError(t, db.QueryRowContext(ctx, inlined).Scan(&value, &repeated))
+			assert.Equal(t, "b-a", value)
+
+			// composite args have the same value whether they are bound or inlined
+			compositeQuery := `SELECT $1 AS "list", $2 AS "object"`
+			compositeArgs := []any{[]any{"a", 1}, map[string]any{"b": true}}
+			boundQuery, args, err = binder.Bind(compositeQuery, compositeArgs...)
+			require.NoError(t, err)
+			var boundList, boundObject string
+			require.NoError(t, db.QueryRowContext(ctx, boundQuery, args...).Scan(&boundList, &boundObject))
+			inlined, err = binder.Inline(compositeQuery, compositeArgs...)
+			require.NoError(t, err)
+			var inlinedList, inlinedObject string
+			require.NoError(t, db.QueryRowContext(ctx, inlined).Scan(&inlinedList, &inlinedObject))
+			assert.Equal(t, `["a",1]`, boundList)
+			assert.Equal(t, boundList, inlinedList)
+			assert.Equal(t, boundObject, inlinedObject)
+		})
+	}
+}
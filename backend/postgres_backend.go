@@ -4,24 +4,21 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
-	"encoding/json"
 	"fmt"
 	"net/netip"
 	"strings"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/turbot/go-kit/helpers"
 	"github.com/turbot/pipe-fittings/constants"
 	"github.com/turbot/pipe-fittings/queryresult"
 	"github.com/turbot/pipe-fittings/sperr"
-	"github.com/turbot/pipe-fittings/utils"
 )
 
 var postgresConnectionStringPrefixes = []string{"postgresql://", "postgres://"}
 
 type PostgresBackend struct {
+	infoSchemaIntrospector
 	originalConnectionString string
 	originalSearchPath       []string
 	schemaNames              []string
@@ -31,20 +28,24 @@ type PostgresBackend struct {
 	requiredSearchPath []string
 }
 
-func NewPostgresBackend(ctx context.Context, connString string) (*PostgresBackend, error) {
+// NewPostgresBackend creates a PostgresBackend, connecting to the database to load the search path and schema names.
+// The connection is retried using the RetryConfig of the options
+func NewPostgresBackend(ctx context.Context, connString string, opts ...ConnectOption) (*PostgresBackend, error) {
 	b := &PostgresBackend{
+		infoSchemaIntrospector:   newPostgresIntrospector(),
 		originalConnectionString: connString,
 		rowReader:                newPgxRowReader(),
 	}
 
-	if err := b.init(ctx); err != nil {
+	if err := b.init(ctx, opts); err != nil {
 		return nil, err
 	}
 	return b, nil
 }
 
-func (b *PostgresBackend) init(ctx context.Context) error {
-	db, err := b.Connect(ctx)
+func (b *PostgresBackend) init(ctx context.Context, opts []ConnectOption) error {
+	// only use the retry config - the search path has not been loaded yet, so cannot be resolved
+	db, err := connectWithRetry(ctx, b, []ConnectOption{WithRetryConfig(NewConnectConfig(opts).RetryConfig)})
 	if err != nil {
 		return err
 	}
@@ -54,31 +55,37 @@ func (b *PostgresBackend) init(ctx context.Context) error {
 		return err
 	}
 
-	return b.loadSchemaNames(db)
+	return b.loadSchemaNames(ctx, db)
 }
 
 // Connect implements Backend.
 func (b *PostgresBackend) Connect(ctx context.Context, opts ...ConnectOption) (*sql.DB, error) {
 	connString := b.originalConnectionString
-	connector, err := NewPgxConnector(connString, b.afterConnectFunc)
+	config := NewConnectConfig(opts)
+	connector, err := NewPgxConnector(connString, func(ctx context.Context, conn driver.Conn) error {
+		return b.afterConnectFunc(ctx, conn, config.ReadOnly)
+	})
 	if err != nil {
 		return nil, sperr.WrapWithMessage(err, "Unable to parse connection string")
 	}
 
-	config := NewConnectConfig(opts)
-
 	db := sql.OpenDB(connector)
 	db.SetConnMaxIdleTime(config.MaxConnIdleTime)
 	db.SetConnMaxLifetime(config.MaxConnLifeTime)
 	db.SetMaxOpenConns(config.MaxOpenConns)
 
 	// resolve the required search path
-	if err := b.resolveDesiredSearchPath(ctx, db, config.SearchPathConfig); err != nil {
+	if err := b.resolveDesiredSearchPath(config.SearchPathConfig); err != nil {
 		return nil, err
 	}
 	return db, nil
 }
 
+// Ping implements Backend.
+func (b *PostgresBackend) Ping(ctx context.Context, options ...ConnectOption) error {
+	return ping(ctx, b, options)
+}
+
 func (b *PostgresBackend) ConnectionString() string {
 	return b.originalConnectionString
 }
@@ -111,15 +118,29 @@ func (b *PostgresBackend) ResolvedSearchPath() []string {
 }
 
 // afterConnectFunc is called after the connection is established
-func (b *PostgresBackend) afterConnectFunc(ctx context.Context, conn driver.Conn) error {
-	if len(b.requiredSearchPath) == 0 {
-		return nil
+func (b *PostgresBackend) afterConnectFunc(ctx context.Context, conn driver.Conn, readOnly bool) error {
+	var statements []string
+	if len(b.requiredSearchPath) != 0 {
+		statements = append(statements, "SET search_path TO "+strings.Join(b.requiredSearchPath, ","))
+	}
+	if readOnly {
+		statements = append(statements, "SET default_transaction_read_only = on")
 	}
+
 	connPc, ok := conn.(driver.ConnPrepareContext)
-	if !ok {
+	if !ok && len(statements) > 0 {
 		return fmt.Errorf("stdlib driver does not implement ConnPrepareContext")
 	}
-	ps, err := connPc.PrepareContext(ctx, "SET search_path TO "+strings.Join(b.requiredSearchPath, ","))
+	for _, statement := range statements {
+		if err := execPrepared(ctx, connPc, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func execPrepared(ctx context.Context, connPc driver.ConnPrepareContext, statement string) error {
+	ps, err := connPc.PrepareContext(ctx, statement)
 	if err != nil {
 		return err
 	}
@@ -130,11 +151,7 @@ func (b *PostgresBackend) afterConnectFunc(ctx context.Context, conn driver.Conn
 	defer ps.Close()
 
 	_, err = ec.ExecContext(ctx, nil)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return err
 }
 
 // loadSearchPath gets the current search path from the database
@@ -170,80 +187,58 @@ func (b *PostgresBackend) loadSearchPath(ctx context.Context, db *sql.DB) error
 	return nil
 }
 
-func (b *PostgresBackend) loadSchemaNames(db *sql.DB) error {
-	// SQL query to select all schema names
-	query := `SELECT schema_name FROM information_schema.schemata;`
-
-	// Execute the query
-	rows, err := db.Query(query)
+func (b *PostgresBackend) loadSchemaNames(ctx context.Context, db *sql.DB) error {
+	schemaNames, err := b.ListSchemas(ctx, db)
 	if err != nil {
-		return sperr.WrapWithMessage(err, "failed to read schema names from the database")
-	}
-	defer rows.Close()
-
-	var schemaNames []string
-
-	// Iterate over the results
-	for rows.Next() {
-		var schemaName string
-		if err := rows.Scan(&schemaName); err != nil {
-			return sperr.WrapWithMessage(err, "failed to read a schema name from the database")
-		}
-		schemaNames = append(schemaNames, schemaName)
-	}
-
-	// Check for errors from iterating over rows
-	if err = rows.Err(); err != nil {
-		return sperr.WrapWithMessage(err, "error encountered while reading schema names from the database")
+		return err
 	}
-
-	// Here, you can use the schemaNames slice which contains all the schema names
-	// For example, storing the schema names in the struct
 	b.schemaNames = schemaNames
-
 	return nil
 }
 
 // resolveDesiredSearchPath resolves the desired search path from the prefix or the custom search path
-func (b *PostgresBackend) resolveDesiredSearchPath(ctx context.Context, db *sql.DB, cfg SearchPathConfig) error {
-	if len(cfg.SearchPath) > 0 && len(cfg.SearchPathPrefix) > 0 {
-		return sperr.WrapWithMessage(ErrInvalidConfig, "cannot specify both search_path and search_path_prefix")
-	}
-
-	if len(cfg.SearchPath) == 0 && len(cfg.SearchPathPrefix) == 0 {
-		return nil
-	}
-
-	if len(cfg.SearchPath) > 0 {
-		b.requiredSearchPath = b.cleanSearchPath(cfg.SearchPath)
-		return nil
-	}
-
-	// must be that the SearchPathPrefix is set
-	requiredSearchPath, err := b.constructSearchPathFromPrefix(ctx, db, cfg)
+func (b *PostgresBackend) resolveDesiredSearchPath(cfg SearchPathConfig) error {
+	requiredSearchPath, err := resolveRequiredSearchPath(cfg, b.originalSearchPath)
 	if err != nil {
 		return err
 	}
-	b.requiredSearchPath = requiredSearchPath
-
+	if requiredSearchPath != nil {
+		b.requiredSearchPath = requiredSearchPath
+	}
 	return nil
 }
 
-// constructSearchPathFromPrefix constructs the search path from the prefix and the original search path
-func (b *PostgresBackend) constructSearchPathFromPrefix(ctx context.Context, db *sql.DB, cfg SearchPathConfig) ([]string, error) {
-	searchPathPrefix := b.cleanSearchPath(cfg.SearchPathPrefix)
-	return append(searchPathPrefix, b.originalSearchPath...), nil
+func newPostgresIntrospector() infoSchemaIntrospector {
+	return infoSchemaIntrospector{
+		placeholder: dollarPlaceholder,
+		// use the udt name as this matches the type names returned by pgx, e.g. INT8, _TEXT
+		dataTypeColumn: "udt_name",
+		dataTypes:      postgresDataTypes,
+	}
 }
 
-// the prefix is prepended to the original search path
-func (b *PostgresBackend) cleanSearchPath(searchPath []string) []string {
-	return helpers.RemoveFromStringSlice(searchPath, "")
+// postgresDataTypes maps the pgx database type names to canonical data types
+var postgresDataTypes = map[string]string{
+	"INT2":    queryresult.DataTypeInt,
+	"INT4":    queryresult.DataTypeInt,
+	"OID":     queryresult.DataTypeInt,
+	"FLOAT4":  queryresult.DataTypeFloat,
+	"VARCHAR": queryresult.DataTypeText,
+	"BPCHAR":  queryresult.DataTypeText,
+	"CHAR":    queryresult.DataTypeText,
+	"NAME":    queryresult.DataTypeText,
+	"LTREE":   queryresult.DataTypeText,
+	"JSON":    queryresult.DataTypeJSON,
+	"TIMETZ":  queryresult.DataTypeTime,
+	"CIDR":    queryresult.DataTypeInet,
 }
 
 func newPgxRowReader() *pgxRowReader {
 	return &pgxRowReader{
 		BasicRowReader: BasicRowReader{
-			CellReader: pgxReadCell,
+			CellReader:  pgxReadCell,
+			DataTypes:   postgresDataTypes,
+			BackendName: constants.PostgresBackendName,
 		},
 	}
 }
@@ -254,67 +249,21 @@ type pgxRowReader struct {
 }
 
 func pgxReadCell(columnValue any, col *queryresult.ColumnDef) (any, error) {
-	var result any
-	if columnValue != nil {
-		result = columnValue
-
-		// add special handling for some types
-		switch col.DataType {
-		case "_TEXT":
-			if arr, ok := columnValue.([]interface{}); ok {
-				elements := utils.Map(arr, func(e interface{}) string { return e.(string) })
-				result = strings.Join(elements, ",")
-			}
-		case "INET":
-			if inet, ok := columnValue.(netip.Prefix); ok {
-				result = strings.TrimSuffix(inet.String(), "/32")
-			}
-		case "UUID":
-			if bytes, ok := columnValue.([16]uint8); ok {
-				if u, err := uuid.FromBytes(bytes[:]); err == nil {
-					result = u
-				}
-			}
-		case "TIME":
-			if t, ok := columnValue.(pgtype.Time); ok {
-				result = time.UnixMicro(t.Microseconds).UTC().Format("15:04:05")
-			}
-		case "INTERVAL":
-			if interval, ok := columnValue.(pgtype.Interval); ok {
-				var sb strings.Builder
-				years := interval.Months / 12
-				months := interval.Months % 12
-				if years > 0 {
-					sb.WriteString(fmt.Sprintf("%d %s ", years, utils.Pluralize("year", int(years))))
-				}
-				if months > 0 {
-					sb.WriteString(fmt.Sprintf("%d %s ", months, utils.Pluralize("mon", int(months))))
-				}
-				if interval.Days > 0 {
-					sb.WriteString(fmt.Sprintf("%d %s ", interval.Days, utils.Pluralize("day", int(interval.Days))))
-				}
-				if interval.Microseconds > 0 {
-					d := time.Duration(interval.Microseconds) * time.Microsecond
-					formatStr := time.Unix(0, 0).UTC().Add(d).Format("15:04:05")
-					sb.WriteString(formatStr)
-				}
-				result = sb.String()
-			}
-
-		case "NUMERIC":
-			if numeric, ok := columnValue.(pgtype.Numeric); ok {
-				if f, err := numeric.Float64Value(); err == nil {
-					result = f.Float64
-				}
-			}
-		case "JSON", "JSONB":
-			var dst any
-			err := json.Unmarshal(columnValue.([]byte), &dst)
-			if err != nil {
-				return nil, err
-			}
-			result = dst
+	// convert pgx specific types before normalising
+	switch t := columnValue.(type) {
+	case netip.Prefix:
+		columnValue = strings.TrimSuffix(t.String(), "/32")
+	case pgtype.Time:
+		columnValue = time.UnixMicro(t.Microseconds).UTC().Format(time.TimeOnly)
+	case pgtype.Interval:
+		columnValue = formatInterval(int64(t.Months), int64(t.Days), t.Microseconds)
+	case pgtype.Numeric:
+		// use the exact text representation, to avoid losing precision
+		v, err := t.Value()
+		if err != nil {
+			return nil, err
 		}
+		columnValue = v
 	}
-	return result, nil
+	return normaliseValue(columnValue, canonicalDataType(col.DataType, postgresDataTypes))
 }
@@ -0,0 +1,43 @@
+package backend
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnly(t *testing.T) {
+	ctx := context.Background()
+	backends := map[string]func(path string) Backend{
+		"sqlite": func(path string) Backend { return NewSqliteBackend("sqlite:" + path) },
+		"duckdb": func(path string) Backend { return NewDuckDBBackend("duckdb:" + path + "?install_extensions=false") },
+	}
+
+	for name, newBackend := range backends {
+		t.Run(name, func(t *testing.T) {
+			b := newBackend(filepath.Join(t.TempDir(), "test.db"))
+
+			db, err := b.Connect(ctx)
+			require.NoError(t, err)
+			_, err = db.ExecContext(ctx, "CREATE TABLE t (id INTEGER); INSERT INTO t VALUES (1);")
+			require.NoError(t, err)
+			require.NoError(t, db.Close())
+
+			db, err = b.Connect(ctx, WithReadOnly())
+			require.NoError(t, err)
+			defer db.Close()
+
+			var id int
+			require.NoError(t, db.QueryRowContext(ctx, "SELECT id FROM t").Scan(&id))
+			assert.Equal(t, 1, id)
+
+			for _, statement := range []string{"INSERT INTO t VALUES (2)", "DELETE FROM t", "DROP TABLE t", "CREATE TABLE u (id INTEGER)"} {
+				_, err = db.ExecContext(ctx, statement)
+				assert.Error(t, err, statement)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package backend
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/turbot/pipe-fittings/constants"
+)
+
+// Factory creates a Backend from a connection string.
+// The options are passed to FromConnectionString and should be used if the factory connects to the database
+type Factory func(ctx context.Context, connString string, opts ...ConnectOption) (Backend, error)
+
+// Detector inspects a Backend which has been created by a Factory and, if it recognises it,
+// returns a more specific Backend (for example a postgres database which is in fact Steampipe).
+// If the backend is not recognised, the Detector returns nil.
+// An error should only be returned if the Detector could not determine whether it recognises the backend
+type Detector func(ctx context.Context, b Backend, opts ...ConnectOption) (Backend, error)
+
+type registration struct {
+	prefix  string
+	name    string
+	factory Factory
+}
+
+type RegisterOption func(*registration)
+
+// WithBackendName sets the name of the backend created by the factory - this must match the value returned
+// by Backend.Name. If not set, the prefix (minus any trailing ':' or '://') is used
+func WithBackendName(name string) RegisterOption {
+	return func(r *registration) {
+		r.name = name
+	}
+}
+
+var (
+	registryLock sync.RWMutex
+	// map of registrations, keyed by connection string prefix
+	registrations = make(map[string]*registration)
+	// map of detectors, keyed by the name of the backend they are run against
+	detectors = make(map[string][]detectorRegistration)
+)
+
+type detectorRegistration struct {
+	name     string
+	detector Detector
+}
+
+func init() {
+	for _, prefix := range postgresConnectionStringPrefixes {
+		Register(prefix, newPostgresBackendFactory, WithBackendName(constants.PostgresBackendName))
+	}
+	Register(mysqlConnectionStringPrefix, newMySQLBackendFactory, WithBackendName(constants.MySQLBackendName))
+	Register(duckDBConnectionStringPrefix, newDuckDBBackendFactory, WithBackendName(constants.DuckDBBackendName))
+	Register(sqliteConnectionStringPrefix, newSqliteBackendFactory, WithBackendName(constants.SQLiteBackendName))
+
+	RegisterDetector(constants.PostgresBackendName, constants.SteampipeBackendName, detectSteampipeBackend)
+}
+
+// Register registers a backend factory for connection strings with the given prefix.
+// If a factory is already registered for the prefix, it is replaced
+func Register(prefix string, factory Factory, opts ...RegisterOption) {
+	r := &registration{
+		prefix:  prefix,
+		name:    strings.TrimSuffix(strings.TrimSuffix(prefix, "://"), ":"),
+		factory: factory,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	registrations[prefix] = r
+}
+
+// Unregister removes the backend factory registered for the given prefix
+func Unregister(prefix string) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	delete(registrations, prefix)
+}
+
+// RegisterDetector registers a detector which is run against backends named baseName after they are created.
+// detectedName is the name of the backend returned by the detector.
+// Detectors are run in the order they are registered - the first to return a backend wins
+func RegisterDetector(baseName, detectedName string, detector Detector) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	detectors[baseName] = append(detectors[baseName], detectorRegistration{name: detectedName, detector: detector})
+}
+
+// UnregisterDetector removes the detectors registered against backends named baseName which return backends
+// named detectedName
+func UnregisterDetector(baseName, detectedName string) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	ds := slices.DeleteFunc(detectors[baseName], func(d detectorRegistration) bool {
+		return d.name == detectedName
+	})
+	if len(ds) == 0 {
+		delete(detectors, baseName)
+		return
+	}
+	detectors[baseName] = ds
+}
+
+// RegisteredBackendNames returns the sorted names of all registered backends, including detected backends
+func RegisteredBackendNames() []string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	var names []string
+	for _, r := range registrations {
+		if !slices.Contains(names, r.name) {
+			names = append(names, r.name)
+		}
+	}
+	for _, ds := range detectors {
+		for _, d := range ds {
+			if !slices.Contains(names, d.name) {
+				names = append(names, d.name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// IsRegisteredBackendName returns true if a backend with the given name has been registered
+func IsRegisteredBackendName(name string) bool {
+	return slices.Contains(RegisteredBackendNames(), name)
+}
+
+// BackendNameForConnectionString returns the name of the backend registered for the connection string.
+// NOTE: this does not run detectors, so a Steampipe connection string will return the postgres backend name
+func BackendNameForConnectionString(str string) (string, bool) {
+	r := registrationForConnectionString(str)
+	if r == nil {
+		return "", false
+	}
+	return r.name, true
+}
+
+// registrationForConnectionString returns the registration with the longest prefix matching the connection string
+func registrationForConnectionString(str string) *registration {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	var res *registration
+	for prefix, r := range registrations {
+		if strings.HasPrefix(str, prefix) && (res == nil || len(prefix) > len(res.prefix)) {
+			res = r
+		}
+	}
+	return res
+}
+
+// runDetectors runs the detectors registered for the backend, returning the first detected backend,
+// or the original backend if no detector recognised it
+func runDetectors(ctx context.Context, b Backend, opts []ConnectOption) (Backend, error) {
+	registryLock.RLock()
+	ds := slices.Clone(detectors[b.Name()])
+	registryLock.RUnlock()
+
+	for _, d := range ds {
+		detected, err := d.detector(ctx, b, opts...)
+		if err != nil {
+			return nil, &DetectionError{BackendName: d.name, Err: err}
+		}
+		if detected != nil {
+			return detected, nil
+		}
+	}
+	return b, nil
+}
+
+func newPostgresBackendFactory(ctx context.Context, connString string, opts ...ConnectOption) (Backend, error) {
+	return NewPostgresBackend(ctx, connString, opts...)
+}
+
+func newMySQLBackendFactory(_ context.Context, connString string, _ ...ConnectOption) (Backend, error) {
+	return NewMySQLBackend(connString), nil
+}
+
+func newDuckDBBackendFactory(_ context.Context, connString string, _ ...ConnectOption) (Backend, error) {
+	return NewDuckDBBackend(connString), nil
+}
+
+func newSqliteBackendFactory(_ context.Context, connString string, _ ...ConnectOption) (Backend, error) {
+	return NewSqliteBackend(connString), nil
+}
+
+// detectSteampipeBackend returns a SteampipeBackend if the postgres backend is a Steampipe database
+func detectSteampipeBackend(ctx context.Context, b Backend, opts ...ConnectOption) (Backend, error) {
+	pgBackend, ok := b.(*PostgresBackend)
+	if !ok {
+		return nil, nil
+	}
+	isSteampipe, err := isSteampipeBackend(ctx, pgBackend, opts)
+	if err != nil || !isSteampipe {
+		return nil, err
+	}
+	return NewSteampipeBackend(ctx, *pgBackend, opts...)
+}
@@ -0,0 +1,104 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/constants"
+)
+
+type testBackend struct {
+	connectionString string
+	name             string
+}
+
+func (b *testBackend) Connect(context.Context, ...ConnectOption) (*sql.DB, error) { return nil, nil }
+func (b *testBackend) Ping(context.Context, ...ConnectOption) error               { return nil }
+func (b *testBackend) RowReader() RowReader                                       { return NewBasicRowReader() }
+func (b *testBackend) ConnectionString() string                                   { return b.connectionString }
+func (b *testBackend) Name() string                                               { return b.name }
+
+func TestRegister(t *testing.T) {
+	assert := assert.New(t)
+
+	const prefix = "clickhouse://"
+	Register(prefix, func(_ context.Context, connString string, _ ...ConnectOption) (Backend, error) {
+		return &testBackend{connectionString: connString, name: "ClickHouse"}, nil
+	}, WithBackendName("ClickHouse"))
+	defer Unregister(prefix)
+
+	assert.True(HasBackend("clickhouse://localhost:9000"))
+	assert.True(IsRegisteredBackendName("ClickHouse"))
+
+	name, ok := BackendNameForConnectionString("clickhouse://localhost:9000")
+	assert.True(ok)
+	assert.Equal("ClickHouse", name)
+
+	b, err := FromConnectionString(context.Background(), "clickhouse://localhost:9000")
+	assert.Nil(err)
+	assert.Equal("ClickHouse", b.Name())
+	assert.Equal("clickhouse://localhost:9000", b.ConnectionString())
+}
+
+func TestRegisterDetector(t *testing.T) {
+	assert := assert.New(t)
+
+	const prefix = "fake:"
+	Register(prefix, func(_ context.Context, connString string, _ ...ConnectOption) (Backend, error) {
+		return &testBackend{connectionString: connString, name: "fake"}, nil
+	})
+	defer Unregister(prefix)
+	RegisterDetector("fake", "fake-special", func(_ context.Context, b Backend, _ ...ConnectOption) (Backend, error) {
+		switch b.ConnectionString() {
+		case "fake:broken":
+			return nil, errors.New("connection refused")
+		case "fake:special":
+		default:
+			return nil, nil
+		}
+		return &testBackend{connectionString: b.ConnectionString(), name: "fake-special"}, nil
+	})
+	t.Cleanup(func() {
+		UnregisterDetector("fake", "fake-special")
+		assert.False(IsRegisteredBackendName("fake-special"))
+	})
+
+	b, err := FromConnectionString(context.Background(), "fake:plain")
+	assert.Nil(err)
+	assert.Equal("fake", b.Name())
+
+	b, err = FromConnectionString(context.Background(), "fake:special")
+	assert.Nil(err)
+	assert.Equal("fake-special", b.Name())
+
+	// a detector failure must be distinguishable from the backend not being recognised
+	_, err = FromConnectionString(context.Background(), "fake:broken")
+	var detectionErr *DetectionError
+	assert.ErrorAs(err, &detectionErr)
+	assert.Equal("fake-special", detectionErr.BackendName)
+}
+
+func TestBuiltinBackends(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := map[string]string{
+		"postgres://localhost:5432/db":   constants.PostgresBackendName,
+		"postgresql://localhost:5432/db": constants.PostgresBackendName,
+		"mysql://root@localhost/db":      constants.MySQLBackendName,
+		"duckdb:./my.db":                 constants.DuckDBBackendName,
+		"sqlite:./my.db":                 constants.SQLiteBackendName,
+	}
+	for connString, expected := range tests {
+		name, ok := BackendNameForConnectionString(connString)
+		assert.True(ok, connString)
+		assert.Equal(expected, name, connString)
+	}
+
+	assert.False(HasBackend("oracle://localhost"))
+	_, err := FromConnectionString(context.Background(), "oracle://localhost")
+	assert.ErrorIs(err, ErrUnknownBackend)
+	assert.True(IsRegisteredBackendName(constants.SteampipeBackendName))
+}
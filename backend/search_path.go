@@ -0,0 +1,28 @@
+package backend
+
+import (
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/sperr"
+)
+
+// resolveRequiredSearchPath resolves the search path required by the config:
+// either the custom search path, or the prefix prepended to the original search path of the database.
+// If neither is set, nil is returned
+func resolveRequiredSearchPath(cfg SearchPathConfig, originalSearchPath []string) ([]string, error) {
+	if len(cfg.SearchPath) > 0 && len(cfg.SearchPathPrefix) > 0 {
+		return nil, sperr.WrapWithMessage(ErrInvalidConfig, "cannot specify both search_path and search_path_prefix")
+	}
+
+	if len(cfg.SearchPath) > 0 {
+		return cleanSearchPath(cfg.SearchPath), nil
+	}
+	if len(cfg.SearchPathPrefix) > 0 {
+		return append(cleanSearchPath(cfg.SearchPathPrefix), originalSearchPath...), nil
+	}
+	return nil, nil
+}
+
+// cleanSearchPath removes any empty elements from the search path
+func cleanSearchPath(searchPath []string) []string {
+	return helpers.RemoveFromStringSlice(searchPath, "")
+}
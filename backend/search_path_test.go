@@ -0,0 +1,103 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRequiredSearchPath(t *testing.T) {
+	original := []string{"main"}
+
+	res, err := resolveRequiredSearchPath(SearchPathConfig{}, original)
+	require.NoError(t, err)
+	assert.Nil(t, res)
+
+	res, err = resolveRequiredSearchPath(SearchPathConfig{SearchPath: []string{"a", "", "b"}}, original)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, res)
+
+	res, err = resolveRequiredSearchPath(SearchPathConfig{SearchPathPrefix: []string{"a"}}, original)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "main"}, res)
+
+	_, err = resolveRequiredSearchPath(SearchPathConfig{SearchPath: []string{"a"}, SearchPathPrefix: []string{"b"}}, original)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+// createSearchPathDatabases creates main and other databases which both contain table t,
+// and returns the path of the other database
+func createSearchPathDatabases(t *testing.T, open func(path string) *sql.DB, dir string) string {
+	for name, value := range map[string]string{"main.db": "main", "other.db": "other"} {
+		db := open(filepath.Join(dir, name))
+		_, err := db.Exec("CREATE TABLE t (source VARCHAR); INSERT INTO t VALUES ('" + value + "');")
+		require.NoError(t, err)
+		if value == "other" {
+			_, err = db.Exec("CREATE TABLE only_other (id INTEGER);")
+			require.NoError(t, err)
+		}
+		require.NoError(t, db.Close())
+	}
+	return filepath.Join(dir, "other.db")
+}
+
+func TestSearchPath(t *testing.T) {
+	ctx := context.Background()
+	// map of functions to create a backend with optional query parameters, keyed by backend name
+	backends := map[string]func(path, query string) Backend{
+		"sqlite": func(path, query string) Backend {
+			return NewSqliteBackend("sqlite:" + path + "?" + query)
+		},
+		"duckdb": func(path, query string) Backend {
+			return NewDuckDBBackend("duckdb:" + path + "?install_extensions=false&" + query)
+		},
+	}
+
+	for name, newBackend := range backends {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			otherPath := createSearchPathDatabases(t, func(path string) *sql.DB {
+				db, err := newBackend(path, "").Connect(ctx)
+				require.NoError(t, err)
+				return db
+			}, dir)
+			b := newBackend(filepath.Join(dir, "main.db"), "attach=other="+otherPath)
+
+			// by default, unqualified names resolve to the main database
+			db, err := b.Connect(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "main", querySource(t, db))
+			require.NoError(t, db.Close())
+
+			db, err = b.Connect(ctx, WithSearchPathConfig(SearchPathConfig{SearchPathPrefix: []string{"other"}}))
+			require.NoError(t, err)
+			defer db.Close()
+			assert.Equal(t, "other", b.(SearchPathProvider).ResolvedSearchPath()[0])
+
+			// the search path must apply to every connection in the pool
+			conns := make([]*sql.Conn, 3)
+			for i := range conns {
+				conns[i], err = db.Conn(ctx)
+				require.NoError(t, err)
+			}
+			for _, conn := range conns {
+				var source string
+				require.NoError(t, conn.QueryRowContext(ctx, "SELECT source FROM t").Scan(&source))
+				assert.Equal(t, "other", source)
+				_, err = conn.ExecContext(ctx, "SELECT * FROM only_other")
+				assert.NoError(t, err)
+				require.NoError(t, conn.Close())
+			}
+		})
+	}
+}
+
+func querySource(t *testing.T, db *sql.DB) string {
+	var source string
+	require.NoError(t, db.QueryRow("SELECT source FROM t").Scan(&source))
+	return source
+}
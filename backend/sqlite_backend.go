@@ -3,43 +3,158 @@ package backend
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"slices"
 	"strings"
 
 	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/queryresult"
 	"github.com/turbot/pipe-fittings/sperr"
 )
 
 const (
 	sqliteConnectionStringPrefix = "sqlite:"
+	sqliteMainSchema             = "main"
 )
 
 type SqliteBackend struct {
 	connectionString string
 	rowReader        RowReader
+	// the data source name passed to the sqlite driver, with any attach parameters removed
+	dataSourceName string
+	// databases to attach to each connection, set using the attach connection string parameter, e.g.
+	// sqlite:my.db?attach=other=other.db
+	attach []DatabaseAttachment
+	// any error parsing the connection string - returned by Connect
+	parseErr           error
+	requiredSearchPath []string
 }
 
 func NewSqliteBackend(connString string) *SqliteBackend {
 	connString = strings.TrimSpace(connString) // remove any leading or trailing whitespace
 	connString = strings.TrimPrefix(connString, sqliteConnectionStringPrefix)
-	return &SqliteBackend{
+	b := &SqliteBackend{
 		connectionString: connString,
 		rowReader:        newSqliteRowReader(),
+		dataSourceName:   connString,
 	}
+
+	if path, query, ok := strings.Cut(connString, "?"); ok {
+		params, err := url.ParseQuery(query)
+		if err != nil {
+			b.parseErr = sperr.WrapWithMessage(err, "invalid sqlite connection string parameters")
+			return b
+		}
+		b.attach = parseDatabaseAttachments(params)
+		b.dataSourceName = path
+		if len(params) > 0 {
+			b.dataSourceName += "?" + params.Encode()
+		}
+	}
+	return b
 }
 
 // Connect implements Backend.
+// As sqlite has no search path, if a search path is configured it is emulated by creating temporary views
+// for the tables of each search path database, so unqualified names resolve in search path order.
+// Databases not in the search path are still searched, using the default sqlite resolution order.
+// NOTE: the temporary views are read only
 func (b *SqliteBackend) Connect(_ context.Context, options ...ConnectOption) (*sql.DB, error) {
+	if b.parseErr != nil {
+		return nil, b.parseErr
+	}
 	config := NewConnectConfig(options)
-	db, err := sql.Open("sqlite3", b.connectionString)
+
+	requiredSearchPath, err := resolveRequiredSearchPath(config.SearchPathConfig, b.OriginalSearchPath())
+	if err != nil {
+		return nil, err
+	}
+	b.requiredSearchPath = requiredSearchPath
+
+	// attached databases and temporary views are per connection, so must be created for each new connection
+	connector, err := newAfterConnectConnector("sqlite3", b.dataSourceName, func(ctx context.Context, conn driver.Conn) error {
+		for _, a := range b.attach {
+			if err := execDriverConn(ctx, conn, a.sqliteStatement()); err != nil {
+				return sperr.WrapWithMessage(err, "could not attach sqlite database '%s'", a.Path)
+			}
+		}
+		if err := applySqliteSearchPath(ctx, conn, requiredSearchPath); err != nil {
+			return err
+		}
+		if config.ReadOnly {
+			return execDriverConn(ctx, conn, "PRAGMA query_only = ON;")
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, sperr.WrapWithMessage(err, "could not connect to sqlite backend")
 	}
+
+	db := sql.OpenDB(connector)
 	db.SetConnMaxIdleTime(config.MaxConnIdleTime)
 	db.SetConnMaxLifetime(config.MaxConnLifeTime)
 	db.SetMaxOpenConns(config.MaxOpenConns)
 	return db, nil
 }
 
+// applySqliteSearchPath creates a temporary view for each table in the search path databases which would not
+// otherwise resolve to that database, i.e. tables in attached databases which are not shadowed by a table in
+// an earlier search path database
+func applySqliteSearchPath(ctx context.Context, conn driver.Conn, searchPath []string) error {
+	resolved := map[string]struct{}{}
+	for _, schema := range searchPath {
+		tables, err := queryDriverConnStrings(ctx, conn, sqliteListTablesQuery(schema))
+		if err != nil {
+			return sperr.WrapWithMessage(err, "failed to read table names for search path schema '%s'", schema)
+		}
+		for _, table := range tables {
+			if _, ok := resolved[table]; ok {
+				continue
+			}
+			resolved[table] = struct{}{}
+			// unqualified names already resolve to the main database, unless shadowed by a temporary view
+			if schema == sqliteMainSchema {
+				continue
+			}
+			statement := fmt.Sprintf("CREATE TEMP VIEW IF NOT EXISTS %s AS SELECT * FROM %s.%s;", quoteIdentifier(table), quoteIdentifier(schema), quoteIdentifier(table))
+			if err := execDriverConn(ctx, conn, statement); err != nil {
+				return sperr.WrapWithMessage(err, "could not apply search path for table '%s.%s'", schema, table)
+			}
+		}
+	}
+	return nil
+}
+
+// OriginalSearchPath implements SearchPathProvider.
+// This is the default sqlite resolution order, i.e. the main database followed by the attached databases
+func (b *SqliteBackend) OriginalSearchPath() []string {
+	searchPath := []string{sqliteMainSchema}
+	for _, a := range b.attach {
+		searchPath = append(searchPath, a.alias())
+	}
+	return searchPath
+}
+
+// RequiredSearchPath implements SearchPathProvider
+func (b *SqliteBackend) RequiredSearchPath() []string {
+	return b.requiredSearchPath
+}
+
+// ResolvedSearchPath implements SearchPathProvider
+func (b *SqliteBackend) ResolvedSearchPath() []string {
+	if len(b.requiredSearchPath) != 0 {
+		return b.requiredSearchPath
+	}
+	return b.OriginalSearchPath()
+}
+
+// Ping implements Backend.
+func (b *SqliteBackend) Ping(ctx context.Context, options ...ConnectOption) error {
+	return ping(ctx, b, options)
+}
+
 func (b *SqliteBackend) ConnectionString() string {
 	return b.connectionString
 }
@@ -53,13 +168,161 @@ func (b *SqliteBackend) RowReader() RowReader {
 	return b.rowReader
 }
 
+// ListSchemas implements Introspector.
+// For sqlite, the schemas are the main, temp and any attached databases
+func (b *SqliteBackend) ListSchemas(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "PRAGMA database_list;")
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to read schema names from the database")
+	}
+	defer rows.Close()
+
+	var schemaNames []string
+	for rows.Next() {
+		var seq int
+		var name, file string
+		if err := rows.Scan(&seq, &name, &file); err != nil {
+			return nil, sperr.WrapWithMessage(err, "failed to read a schema name from the database")
+		}
+		schemaNames = append(schemaNames, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, sperr.WrapWithMessage(err, "error encountered while reading schema names from the database")
+	}
+	return schemaNames, nil
+}
+
+// ListTables implements Introspector.
+func (b *SqliteBackend) ListTables(ctx context.Context, db *sql.DB, schema string) ([]string, error) {
+	res, err := queryStrings(ctx, db, sqliteListTablesQuery(schema))
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to read table names for schema '%s'", schema)
+	}
+	return res, nil
+}
+
+// ListColumns implements Introspector.
+func (b *SqliteBackend) ListColumns(ctx context.Context, db *sql.DB, schema, table string) ([]*queryresult.ColumnDef, error) {
+	info, err := b.tableInfo(ctx, db, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	cols := make([]*queryresult.ColumnDef, len(info))
+	for i, c := range info {
+		cols[i] = &queryresult.ColumnDef{Name: c.name, DataType: sqliteDataType(c.dataType)}
+	}
+	return cols, nil
+}
+
+// ListPrimaryKeys implements Introspector.
+func (b *SqliteBackend) ListPrimaryKeys(ctx context.Context, db *sql.DB, schema, table string) ([]string, error) {
+	info, err := b.tableInfo(ctx, db, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	// the pk field is the 1-based index of the column in the primary key, or 0 if not part of the key
+	info = slices.DeleteFunc(info, func(c sqliteColumnInfo) bool { return c.pk == 0 })
+	slices.SortFunc(info, func(a, b sqliteColumnInfo) int { return a.pk - b.pk })
+
+	var res []string
+	for _, c := range info {
+		res = append(res, c.name)
+	}
+	return res, nil
+}
+
+func sqliteListTablesQuery(schema string) string {
+	return fmt.Sprintf(`SELECT name FROM %s.sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%%' ORDER BY name;`, quoteIdentifier(schema))
+}
+
+type sqliteColumnInfo struct {
+	name     string
+	dataType string
+	pk       int
+}
+
+func (b *SqliteBackend) tableInfo(ctx context.Context, db *sql.DB, schema, table string) ([]sqliteColumnInfo, error) {
+	query := fmt.Sprintf("PRAGMA %s.table_info(%s);", quoteIdentifier(schema), quoteIdentifier(table))
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to read columns for table '%s.%s'", schema, table)
+	}
+	defer rows.Close()
+
+	var res []sqliteColumnInfo
+	for rows.Next() {
+		var c sqliteColumnInfo
+		var cid, notNull int
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &c.name, &c.dataType, &notNull, &defaultValue, &c.pk); err != nil {
+			return nil, sperr.WrapWithMessage(err, "failed to read columns for table '%s.%s'", schema, table)
+		}
+		res = append(res, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, sperr.WrapWithMessage(err, "failed to read columns for table '%s.%s'", schema, table)
+	}
+	return res, nil
+}
+
+// sqliteDataTypes maps sqlite declared type names, which do not follow the affinity rules, to canonical data types
+var sqliteDataTypes = map[string]string{
+	"BOOLEAN":  queryresult.DataTypeBool,
+	"DATETIME": queryresult.DataTypeTimestamp,
+	"JSON":     queryresult.DataTypeJSON,
+	"DECIMAL":  queryresult.DataTypeDecimal,
+}
+
 type sqliteRowReader struct {
 	BasicRowReader
 }
 
 func newSqliteRowReader() *sqliteRowReader {
 	return &sqliteRowReader{
-		// use the generic row reader - there's no real difference between sqlite and generic
-		BasicRowReader: *NewBasicRowReader(),
+		BasicRowReader: BasicRowReader{
+			CellReader:  sqliteReadCell,
+			DataTypes:   sqliteDataTypes,
+			BackendName: constants.SQLiteBackendName,
+		},
+	}
+}
+
+// CanonicalDataType implements DataTypeMapper
+func (r *sqliteRowReader) CanonicalDataType(databaseTypeName string) string {
+	return sqliteDataType(databaseTypeName)
+}
+
+// sqliteDataType maps a sqlite declared column type to a canonical data type.
+// As sqlite allows any declared type, this falls back to the sqlite type affinity rules
+// (see https://www.sqlite.org/datatype3.html#determination_of_column_affinity), except that
+// unrecognised declared types, which have numeric affinity but may contain any value, are mapped to text
+func sqliteDataType(declaredType string) string {
+	t := canonicalDataType(declaredType, sqliteDataTypes)
+	switch {
+	case t == "" || queryresult.IsCanonicalDataType(t):
+		return t
+	case strings.Contains(t, "INT"):
+		return queryresult.DataTypeInt
+	case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"):
+		return queryresult.DataTypeText
+	case strings.Contains(t, "BLOB"):
+		return queryresult.DataTypeBytes
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"):
+		return queryresult.DataTypeFloat
+	default:
+		return queryresult.DataTypeText
+	}
+}
+
+// sqliteReadCell normalises a sqlite value for the declared column type.
+// Columns with numeric affinity store values which cannot be converted to a number as text
+// (e.g. 'n/a' in a NUMERIC column), so these values are returned as text rather than as a cell error
+func sqliteReadCell(columnValue any, col *queryresult.ColumnDef) (any, error) {
+	dataType := sqliteDataType(col.DataType)
+	res, err := normaliseValue(columnValue, dataType)
+	if err != nil && dataType == queryresult.DataTypeDecimal {
+		return normaliseText(columnValue), nil
 	}
+	return res, err
 }
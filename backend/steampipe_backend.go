@@ -18,12 +18,12 @@ type SteampipeBackend struct {
 	PluginVersions map[string]*plugin.PluginVersionString
 }
 
-func NewSteampipeBackend(ctx context.Context, postgresBackend PostgresBackend) (*SteampipeBackend, error) {
+func NewSteampipeBackend(ctx context.Context, postgresBackend PostgresBackend, opts ...ConnectOption) (*SteampipeBackend, error) {
 	backend := &SteampipeBackend{
 		PostgresBackend: postgresBackend,
 	}
 
-	if err := backend.init(ctx); err != nil {
+	if err := backend.init(ctx, opts); err != nil {
 		return nil, err
 	}
 	return backend, nil
@@ -33,8 +33,8 @@ func (b *SteampipeBackend) Name() string {
 	return constants.SteampipeBackendName
 }
 
-func (b *SteampipeBackend) init(ctx context.Context) error {
-	db, err := b.Connect(ctx)
+func (b *SteampipeBackend) init(ctx context.Context, opts []ConnectOption) error {
+	db, err := connectWithRetry(ctx, b, []ConnectOption{WithRetryConfig(NewConnectConfig(opts).RetryConfig)})
 	if err != nil {
 		return err
 	}
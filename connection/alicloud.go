@@ -15,8 +15,8 @@ const AlicloudConnectionType = "alicloud"
 type AlicloudConnection struct {
 	ConnectionImpl
 
-	AccessKey *string `json:"access_key,omitempty" cty:"access_key" hcl:"access_key,optional"`
-	SecretKey *string `json:"secret_key,omitempty" cty:"secret_key" hcl:"secret_key,optional"`
+	AccessKey *string `json:"access_key,omitempty" cty:"access_key" hcl:"access_key,optional" secret:"true"`
+	SecretKey *string `json:"secret_key,omitempty" cty:"secret_key" hcl:"secret_key,optional" secret:"true"`
 }
 
 func NewAlicloudConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -29,6 +29,11 @@ func (c *AlicloudConnection) GetConnectionType() string {
 }
 
 func (c *AlicloudConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &AlicloudConnection{ConnectionImpl: c.ConnectionImpl})
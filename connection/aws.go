@@ -2,11 +2,17 @@ package connection
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -14,15 +20,34 @@ import (
 const (
 	AwsConnectionType = "aws"
 	defaultAwsTtl     = 5 * 60
+	// the region used for sts if none is set in the connection, environment or profile
+	defaultAwsRegion = "us-east-1"
+	// short-lived credentials are treated as expired this long before their actual expiry
+	awsCredentialExpiryWindow = 60
+	// the allowed range of the assume role duration, in seconds
+	minAwsAssumeRoleDuration = 15 * 60
+	maxAwsAssumeRoleDuration = 12 * 60 * 60
 )
 
 type AwsConnection struct {
 	ConnectionImpl
 
-	AccessKey    *string `json:"access_key,omitempty" cty:"access_key" hcl:"access_key,optional"`
-	SecretKey    *string `json:"secret_key,omitempty" cty:"secret_key" hcl:"secret_key,optional"`
-	SessionToken *string `json:"session_token,omitempty" cty:"session_token" hcl:"session_token,optional"`
-	Profile      *string `json:"profile,omitempty" cty:"profile" hcl:"profile,optional"`
+	AccessKey    *string `json:"access_key,omitempty" cty:"access_key" hcl:"access_key,optional" secret:"true"`
+	SecretKey    *string `json:"secret_key,omitempty" cty:"secret_key" hcl:"secret_key,optional" secret:"true"`
+	SessionToken *string `json:"session_token,omitempty" cty:"session_token" hcl:"session_token,optional" secret:"true"`
+	// profile may refer to a shared config profile which uses SSO, assume role or a credential process
+	Profile *string `json:"profile,omitempty" cty:"profile" hcl:"profile,optional"`
+	Region  *string `json:"region,omitempty" cty:"region" hcl:"region,optional"`
+
+	// if set, the role is assumed using STS, with the credentials from the other properties (or the default
+	// credential chain) as the source credentials
+	RoleArn     *string `json:"role_arn,omitempty" cty:"role_arn" hcl:"role_arn,optional"`
+	ExternalId  *string `json:"external_id,omitempty" cty:"external_id" hcl:"external_id,optional"`
+	SessionName *string `json:"session_name,omitempty" cty:"session_name" hcl:"session_name,optional"`
+	// the duration of the assumed role session, in seconds
+	Duration *int `json:"duration,omitempty" cty:"duration" hcl:"duration,optional"`
+	// if set, the role is assumed using the web identity token in this file (e.g. for EKS or CI OIDC)
+	WebIdentityTokenFile *string `json:"web_identity_token_file,omitempty" cty:"web_identity_token_file" hcl:"web_identity_token_file,optional"`
 }
 
 func NewAwsConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -39,53 +64,106 @@ func (c *AwsConnection) GetConnectionType() string {
 }
 
 func (c *AwsConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &AwsConnection{ConnectionImpl: c.ConnectionImpl})
 	}
 
-	// if access key and secret key are provided, just return it
-	if c.AccessKey != nil && c.SecretKey != nil {
+	// if access key and secret key are provided and there is no role to assume, just return it
+	if c.AccessKey != nil && c.SecretKey != nil && c.RoleArn == nil {
 		return c, nil
 	}
 
-	var cfg aws.Config
-	var err error
-
-	// Load the AWS configuration from the shared credentials file
-	if c.Profile != nil {
-		cfg, err = config.LoadDefaultConfig(ctx, config.WithSharedConfigProfile(*c.Profile), config.WithRegion("us-east-1"))
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		cfg, err = config.LoadDefaultConfig(context.TODO(), config.WithRegion("us-east-1"))
-		if err != nil {
-			return nil, err
-		}
-
+	cfg, err := c.loadConfig(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// Access the credentials from the configuration
-	creds, err := cfg.Credentials.Retrieve(context.TODO())
+	creds, err := cfg.Credentials.Retrieve(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// Don't modify existing credential, resolve to a new one
+	// NOTE: the role properties are not copied, as the resolved credentials are for the assumed role,
+	// and the region is only set if it was configured, so the ambient aws config is not overridden
 	newConnection := &AwsConnection{
 		ConnectionImpl: c.ConnectionImpl,
 		AccessKey:      &creds.AccessKeyID,
 		SecretKey:      &creds.SecretAccessKey,
+		Region:         c.Region,
 	}
 
 	if creds.SessionToken != "" {
 		newConnection.SessionToken = &creds.SessionToken
 	}
 
+	// if the credentials are short-lived, the connection must be resolved again before they expire
+	if creds.CanExpire {
+		ttl := int(time.Until(creds.Expires).Seconds()) - awsCredentialExpiryWindow
+		newConnection.SetTtl(max(ttl, 0))
+	}
+
 	return newConnection, nil
 }
 
+// loadConfig loads the aws config, using the static keys or profile (if set) and assuming the role (if set)
+func (c *AwsConnection) loadConfig(ctx context.Context) (aws.Config, error) {
+	// retrieving credentials may require a region, e.g. to assume a role using sts (including for a profile with
+	// a role_arn) - use the default if no region is configured
+	opts := []func(*config.LoadOptions) error{config.WithDefaultRegion(defaultAwsRegion)}
+	if c.Region != nil {
+		opts = append(opts, config.WithRegion(*c.Region))
+	}
+	if c.Profile != nil {
+		opts = append(opts, config.WithSharedConfigProfile(*c.Profile))
+	}
+	if c.AccessKey != nil && c.SecretKey != nil {
+		sessionToken := typehelpers.SafeString(c.SessionToken)
+		opts = append(opts, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(*c.AccessKey, *c.SecretKey, sessionToken)))
+	}
+
+	// Load the AWS configuration from the environment and shared config files
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+	if c.RoleArn == nil {
+		return cfg, nil
+	}
+
+	stsClient := sts.NewFromConfig(cfg)
+	var duration time.Duration
+	if c.Duration != nil {
+		duration = time.Duration(*c.Duration) * time.Second
+	}
+
+	if c.WebIdentityTokenFile != nil {
+		cfg.Credentials = aws.NewCredentialsCache(stscreds.NewWebIdentityRoleProvider(stsClient, *c.RoleArn, stscreds.IdentityTokenFile(*c.WebIdentityTokenFile), func(o *stscreds.WebIdentityRoleOptions) {
+			o.RoleSessionName = typehelpers.SafeString(c.SessionName)
+			o.Duration = duration
+		}))
+		return cfg, nil
+	}
+
+	cfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(stsClient, *c.RoleArn, func(o *stscreds.AssumeRoleOptions) {
+		o.ExternalID = c.ExternalId
+		if c.SessionName != nil {
+			o.RoleSessionName = *c.SessionName
+		}
+		if duration != 0 {
+			o.Duration = duration
+		}
+	}))
+	return cfg, nil
+}
+
 func (c *AwsConnection) Equals(otherConnection PipelingConnection) bool {
 	// If both pointers are nil, they are considered equal
 	if c == nil && helpers.IsNil(otherConnection) {
@@ -113,11 +191,20 @@ func (c *AwsConnection) Equals(otherConnection PipelingConnection) bool {
 		return false
 	}
 
+	if !utils.PtrEqual(c.Region, other.Region) ||
+		!utils.PtrEqual(c.RoleArn, other.RoleArn) ||
+		!utils.PtrEqual(c.ExternalId, other.ExternalId) ||
+		!utils.PtrEqual(c.SessionName, other.SessionName) ||
+		!utils.PtrEqual(c.Duration, other.Duration) ||
+		!utils.PtrEqual(c.WebIdentityTokenFile, other.WebIdentityTokenFile) {
+		return false
+	}
+
 	return c.GetConnectionImpl().Equals(otherConnection.GetConnectionImpl())
 }
 
 func (c *AwsConnection) Validate() hcl.Diagnostics {
-	if c.Pipes != nil && (c.AccessKey != nil || c.SecretKey != nil || c.Profile != nil || c.SessionToken != nil) {
+	if c.Pipes != nil && (c.AccessKey != nil || c.SecretKey != nil || c.Profile != nil || c.SessionToken != nil || c.RoleArn != nil || c.WebIdentityTokenFile != nil) {
 		return hcl.Diagnostics{
 			{
 				Severity: hcl.DiagError,
@@ -147,6 +234,26 @@ func (c *AwsConnection) Validate() hcl.Diagnostics {
 		}
 	}
 
+	if c.RoleArn == nil && (c.ExternalId != nil || c.SessionName != nil || c.Duration != nil || c.WebIdentityTokenFile != nil) {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "external_id, session_name, duration and web_identity_token_file require role_arn to be defined",
+				Subject:  c.DeclRange.HclRangePointer(),
+			},
+		}
+	}
+
+	if c.Duration != nil && (*c.Duration < minAwsAssumeRoleDuration || *c.Duration > maxAwsAssumeRoleDuration) {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("duration must be between %d and %d seconds", minAwsAssumeRoleDuration, maxAwsAssumeRoleDuration),
+				Subject:  c.DeclRange.HclRangePointer(),
+			},
+		}
+	}
+
 	return hcl.Diagnostics{}
 }
 
@@ -167,5 +274,9 @@ func (c *AwsConnection) GetEnv() map[string]cty.Value {
 	if c.SessionToken != nil {
 		env["AWS_SESSION_TOKEN"] = cty.StringVal(*c.SessionToken)
 	}
+	if c.Region != nil {
+		env["AWS_REGION"] = cty.StringVal(*c.Region)
+		env["AWS_DEFAULT_REGION"] = cty.StringVal(*c.Region)
+	}
 	return env
 }
@@ -16,7 +16,7 @@ type AzureConnection struct {
 	ConnectionImpl
 
 	ClientID     *string `json:"client_id,omitempty" cty:"client_id" hcl:"client_id,optional"`
-	ClientSecret *string `json:"client_secret,omitempty" cty:"client_secret" hcl:"client_secret,optional"`
+	ClientSecret *string `json:"client_secret,omitempty" cty:"client_secret" hcl:"client_secret,optional" secret:"true"`
 	TenantID     *string `json:"tenant_id,omitempty" cty:"tenant_id" hcl:"tenant_id,optional"`
 	Environment  *string `json:"environment,omitempty" cty:"environment" hcl:"environment,optional"`
 }
@@ -31,6 +31,11 @@ func (c *AzureConnection) GetConnectionType() string {
 }
 
 func (c *AzureConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &AzureConnection{ConnectionImpl: c.ConnectionImpl})
 	}
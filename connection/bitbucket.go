@@ -17,7 +17,7 @@ type BitbucketConnection struct {
 
 	BaseURL  *string `json:"base_url,omitempty" cty:"base_url" hcl:"base_url,optional"`
 	Username *string `json:"username,omitempty" cty:"username" hcl:"username,optional"`
-	Password *string `json:"password,omitempty" cty:"password" hcl:"password,optional"`
+	Password *string `json:"password,omitempty" cty:"password" hcl:"password,optional" secret:"true"`
 }
 
 func NewBitbucketConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -30,6 +30,11 @@ func (c *BitbucketConnection) GetConnectionType() string {
 }
 
 func (c *BitbucketConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &BitbucketConnection{ConnectionImpl: c.ConnectionImpl})
@@ -15,7 +15,7 @@ const ClickUpConnectionType = "clickup"
 type ClickUpConnection struct {
 	ConnectionImpl
 
-	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional"`
+	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional" secret:"true"`
 }
 
 func NewClickUpConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -28,6 +28,11 @@ func (c *ClickUpConnection) GetConnectionType() string {
 }
 
 func (c *ClickUpConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &ClickUpConnection{ConnectionImpl: c.ConnectionImpl})
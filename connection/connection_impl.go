@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/pipe-fittings/cty_helpers"
 	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/sanitize"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -81,6 +82,12 @@ func (c *ConnectionImpl) CustomType() {
 func (c *ConnectionImpl) LateBinding() {
 }
 
+// RedactedCtyValue returns the cty value of the connection with the values of secrets redacted (including any
+// secrets exported as env vars) - this should be used when displaying or logging a connection value
+func RedactedCtyValue(connection PipelingConnection) (cty.Value, error) {
+	return sanitize.RedactSecrets(connection).CtyValue()
+}
+
 func ctyValueForConnection(connection PipelingConnection) (cty.Value, error) {
 	ctyValue, err := cty_helpers.GetCtyValue(connection)
 	if err != nil {
@@ -0,0 +1,531 @@
+package connection
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/perr"
+	"gopkg.in/ini.v1"
+)
+
+// the types of source which connections may be imported from by a connection_import block
+const (
+	// ConnectionImportTypeSteampipe imports connections from Steampipe .spc files - these are imported by the
+	// application rather than by ImportConnections
+	ConnectionImportTypeSteampipe = "steampipe"
+	// ConnectionImportTypeAws imports aws connections from the profiles in an aws config or credentials file
+	ConnectionImportTypeAws = "aws"
+	// ConnectionImportTypeGcp imports gcp connections from gcloud configurations
+	ConnectionImportTypeGcp = "gcp"
+	// ConnectionImportTypeDotEnv imports a key_value connection from each .env file
+	ConnectionImportTypeDotEnv = "dotenv"
+	// ConnectionImportTypePgPass imports postgres connections from the entries of a .pgpass file
+	ConnectionImportTypePgPass = "pgpass"
+	// ConnectionImportTypePgService imports postgres connections from the services of a pg_service.conf file
+	ConnectionImportTypePgService = "pg_service"
+)
+
+var ConnectionImportTypes = []string{
+	ConnectionImportTypeSteampipe,
+	ConnectionImportTypeAws,
+	ConnectionImportTypeGcp,
+	ConnectionImportTypeDotEnv,
+	ConnectionImportTypePgPass,
+	ConnectionImportTypePgService,
+}
+
+// importedConnection is a connection read from an import source, and the function to create it
+type importedConnection struct {
+	name          string
+	newConnection func(shortName string, declRange hcl.Range) PipelingConnection
+}
+
+type connectionImporter func(source string) ([]importedConnection, error)
+
+var connectionImporters = map[string]connectionImporter{
+	ConnectionImportTypeAws:       importAwsConnections,
+	ConnectionImportTypeGcp:       importGcpConnections,
+	ConnectionImportTypeDotEnv:    importDotEnvConnections,
+	ConnectionImportTypePgPass:    importPgPassConnections,
+	ConnectionImportTypePgService: importPgServiceConnections,
+}
+
+type connectionImportConfig struct {
+	prefix    string
+	filter    []string
+	declRange hcl.Range
+}
+
+type ConnectionImportOption func(*connectionImportConfig)
+
+// WithImportPrefix prefixes the names of the imported connections
+func WithImportPrefix(prefix string) ConnectionImportOption {
+	return func(c *connectionImportConfig) {
+		c.prefix = prefix
+	}
+}
+
+// WithImportFilter only imports connections whose (unprefixed) name matches one of the glob patterns
+func WithImportFilter(patterns []string) ConnectionImportOption {
+	return func(c *connectionImportConfig) {
+		c.filter = patterns
+	}
+}
+
+// WithImportDeclRange sets the decl range of the imported connections, i.e. the range of the connection_import block
+func WithImportDeclRange(declRange hcl.Range) ConnectionImportOption {
+	return func(c *connectionImportConfig) {
+		c.declRange = declRange
+	}
+}
+
+// ImportConnections reads connections of the given import type from the source files, returning a map of connections
+// keyed by short name. Connection names are converted to valid identifiers, e.g. a profile named "my-profile.dev"
+// is imported as a connection named "my-profile_dev"
+func ImportConnections(importType string, sources []string, opts ...ConnectionImportOption) (map[string]PipelingConnection, error) {
+	importer, ok := connectionImporters[importType]
+	if !ok {
+		return nil, perr.BadRequestWithMessage(fmt.Sprintf("connections cannot be imported from source type %s", importType))
+	}
+
+	config := &connectionImportConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	res := make(map[string]PipelingConnection)
+	for _, source := range sources {
+		imported, err := importer(source)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range imported {
+			name := importedConnectionName(i.name)
+			if !config.matchesFilter(name) {
+				continue
+			}
+			shortName := config.prefix + name
+			if _, exists := res[shortName]; exists {
+				return nil, perr.BadRequestWithMessage(fmt.Sprintf("duplicate connection %s imported from %s", shortName, source))
+			}
+			res[shortName] = i.newConnection(shortName, config.declRange)
+		}
+	}
+	return res, nil
+}
+
+// DefaultConnectionImportSource returns the default source for the import type, e.g. ~/.aws/config,
+// or an empty string if the import type has no default source
+func DefaultConnectionImportSource(importType string) (string, error) {
+	switch importType {
+	case ConnectionImportTypeAws:
+		return envOrHomeFile("AWS_CONFIG_FILE", ".aws", "config")
+	case ConnectionImportTypeGcp:
+		if dir := os.Getenv("CLOUDSDK_CONFIG"); dir != "" {
+			return filepath.Join(dir, "configurations", "config_*"), nil
+		}
+		return envOrHomeFile("", ".config", "gcloud", "configurations", "config_*")
+	case ConnectionImportTypePgPass:
+		return envOrHomeFile("PGPASSFILE", ".pgpass")
+	case ConnectionImportTypePgService:
+		return envOrHomeFile("PGSERVICEFILE", ".pg_service.conf")
+	}
+	return "", nil
+}
+
+func envOrHomeFile(envVar string, pathElems ...string) (string, error) {
+	if envVar != "" {
+		if value := os.Getenv(envVar); value != "" {
+			return value, nil
+		}
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{homeDir}, pathElems...)...), nil
+}
+
+func (c *connectionImportConfig) matchesFilter(name string) bool {
+	if len(c.filter) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(c.filter, func(pattern string) bool {
+		match, _ := path.Match(pattern, name)
+		return match
+	})
+}
+
+// importedConnectionName converts the name to a valid hcl identifier, replacing invalid characters with underscores
+func importedConnectionName(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+			sb.WriteRune(r)
+		case unicode.IsDigit(r) || r == '-':
+			// identifiers must not start with a digit or hyphen
+			if i == 0 {
+				sb.WriteRune('_')
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
+
+// importAwsConnections imports an aws connection for each profile in an aws config or credentials file.
+// The connections reference the profile, rather than containing the credentials
+func importAwsConnections(source string) ([]importedConnection, error) {
+	file, err := loadIniFile(source)
+	if err != nil {
+		return nil, perr.BadRequestWithMessage(fmt.Sprintf("failed to load aws config %s: %s", source, err.Error()))
+	}
+
+	var res []importedConnection
+	for _, section := range file.Sections() {
+		// the config file names profiles "profile <name>" (other than the default profile), and the credentials
+		// file names profiles "<name>" - skip other sections, e.g. sso-session and services
+		profile := section.Name()
+		switch {
+		case profile == ini.DefaultSection:
+			continue
+		case strings.HasPrefix(profile, "profile "):
+			profile = strings.TrimSpace(strings.TrimPrefix(profile, "profile "))
+		case strings.Contains(profile, " "):
+			continue
+		}
+
+		region := section.Key("region").String()
+		res = append(res, importedConnection{
+			name: profile,
+			newConnection: func(shortName string, declRange hcl.Range) PipelingConnection {
+				conn := NewAwsConnection(shortName, declRange).(*AwsConnection)
+				conn.Profile = &profile
+				if region != "" {
+					conn.Region = &region
+				}
+				return conn
+			},
+		})
+	}
+	return res, nil
+}
+
+// importGcpConnections imports a gcp connection from a gcloud configuration file, e.g.
+// ~/.config/gcloud/configurations/config_dev, using the credentials stored by gcloud for the account
+// of the configuration. Configurations with no stored credentials are skipped
+func importGcpConnections(source string) ([]importedConnection, error) {
+	name, ok := strings.CutPrefix(filepath.Base(source), "config_")
+	if !ok {
+		return nil, nil
+	}
+
+	file, err := loadIniFile(source)
+	if err != nil {
+		return nil, perr.BadRequestWithMessage(fmt.Sprintf("failed to load gcloud configuration %s: %s", source, err.Error()))
+	}
+
+	account := file.Section("core").Key("account").String()
+	if account == "" {
+		slog.Debug("skipping gcloud configuration with no account", "source", source)
+		return nil, nil
+	}
+
+	// the gcloud config dir is the parent of the configurations dir
+	credentials := filepath.Join(filepath.Dir(filepath.Dir(source)), "legacy_credentials", account, "adc.json")
+	if _, err := os.Stat(credentials); err != nil {
+		slog.Debug("skipping gcloud configuration with no stored credentials", "source", source, "account", account)
+		return nil, nil
+	}
+
+	return []importedConnection{
+		{
+			name: name,
+			newConnection: func(shortName string, declRange hcl.Range) PipelingConnection {
+				conn := NewGcpConnection(shortName, declRange).(*GcpConnection)
+				conn.Credentials = &credentials
+				return conn
+			},
+		},
+	}, nil
+}
+
+// importDotEnvConnections imports a key_value connection from a .env file. The connection is named from the file name,
+// e.g. prod.env and .env.prod are both imported as a connection named prod, and .env as a connection named default
+func importDotEnvConnections(source string) ([]importedConnection, error) {
+	values, err := parseDotEnvFile(source)
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(source)
+	switch {
+	case name == ".env":
+		name = "default"
+	case strings.HasPrefix(name, ".env."):
+		name = strings.TrimPrefix(name, ".env.")
+	case strings.HasSuffix(name, ".env"):
+		name = strings.TrimSuffix(name, ".env")
+	}
+
+	return []importedConnection{
+		{
+			name: name,
+			newConnection: func(shortName string, declRange hcl.Range) PipelingConnection {
+				conn := NewKeyValueConnection(shortName, declRange).(*KeyValueConnection)
+				conn.Values = &values
+				return conn
+			},
+		},
+	}, nil
+}
+
+// parseDotEnvFile parses lines of the form [export] KEY=VALUE, where the value may be single or double quoted.
+// Blank lines and comments are ignored
+func parseDotEnvFile(source string) (map[string]string, error) {
+	f, err := os.Open(source)
+	if err != nil {
+		return nil, perr.BadRequestWithMessage(fmt.Sprintf("failed to open %s: %s", source, err.Error()))
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, perr.BadRequestWithMessage(fmt.Sprintf("invalid line %d in %s - expected KEY=VALUE", lineNumber, source))
+		}
+		value, err = parseDotEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, perr.BadRequestWithMessage(fmt.Sprintf("invalid value for %s on line %d in %s: %s", key, lineNumber, source, err.Error()))
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, perr.BadRequestWithMessage(fmt.Sprintf("failed to read %s: %s", source, err.Error()))
+	}
+	return values, nil
+}
+
+func parseDotEnvValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		// double quoted values support escape sequences, e.g. \n
+		end := strings.LastIndex(value, `"`)
+		if end == 0 {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+		return strconv.Unquote(value[:end+1])
+	case strings.HasPrefix(value, "'"):
+		end := strings.LastIndex(value, "'")
+		if end == 0 {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+		return value[1:end], nil
+	default:
+		// remove any trailing comment
+		if idx := strings.Index(value, " #"); idx != -1 {
+			value = strings.TrimSpace(value[:idx])
+		}
+		return value, nil
+	}
+}
+
+// importPgPassConnections imports a postgres connection for each entry of a .pgpass file, which has lines of the form
+// hostname:port:database:username:password. Entries with a wildcard host or username are skipped.
+// The connections are named <host>_<database>_<username>, omitting a wildcard database
+func importPgPassConnections(source string) ([]importedConnection, error) {
+	f, err := os.Open(source)
+	if err != nil {
+		return nil, perr.BadRequestWithMessage(fmt.Sprintf("failed to open %s: %s", source, err.Error()))
+	}
+	defer f.Close()
+
+	var res []importedConnection
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := splitPgPassLine(line)
+		if len(fields) != 5 {
+			return nil, perr.BadRequestWithMessage(fmt.Sprintf("invalid line %d in %s - expected hostname:port:database:username:password", lineNumber, source))
+		}
+		host, port, db, user, password := fields[0], fields[1], fields[2], fields[3], fields[4]
+		if host == "*" || user == "*" {
+			continue
+		}
+
+		conn := PostgresConnection{
+			Host:     &host,
+			UserName: &user,
+			Password: &password,
+		}
+		nameParts := []string{host}
+		if db != "*" {
+			conn.DbName = &db
+			nameParts = append(nameParts, db)
+		}
+		if port != "*" {
+			portNumber, err := strconv.Atoi(port)
+			if err != nil {
+				return nil, perr.BadRequestWithMessage(fmt.Sprintf("invalid port %s on line %d in %s", port, lineNumber, source))
+			}
+			conn.Port = &portNumber
+		}
+		nameParts = append(nameParts, user)
+
+		res = append(res, importedConnection{
+			name:          strings.Join(nameParts, "_"),
+			newConnection: newImportedPostgresConnection(conn),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, perr.BadRequestWithMessage(fmt.Sprintf("failed to read %s: %s", source, err.Error()))
+	}
+	return res, nil
+}
+
+// splitPgPassLine splits a .pgpass line on unescaped colons, unescaping \: and \\
+func splitPgPassLine(line string) []string {
+	var fields []string
+	var sb strings.Builder
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			sb.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':':
+			fields = append(fields, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return append(fields, sb.String())
+}
+
+// importPgServiceConnections imports a postgres connection for each service in a pg_service.conf file
+func importPgServiceConnections(source string) ([]importedConnection, error) {
+	file, err := loadIniFile(source)
+	if err != nil {
+		return nil, perr.BadRequestWithMessage(fmt.Sprintf("failed to load %s: %s", source, err.Error()))
+	}
+
+	var res []importedConnection
+	for _, section := range file.Sections() {
+		if section.Name() == ini.DefaultSection {
+			continue
+		}
+		conn, err := pgServiceConnection(section)
+		if err != nil {
+			return nil, perr.BadRequestWithMessage(fmt.Sprintf("invalid service %s in %s: %s", section.Name(), source, err.Error()))
+		}
+		res = append(res, importedConnection{
+			name:          section.Name(),
+			newConnection: newImportedPostgresConnection(*conn),
+		})
+	}
+	return res, nil
+}
+
+func pgServiceConnection(section *ini.Section) (*PostgresConnection, error) {
+	conn := &PostgresConnection{}
+	for _, param := range []struct {
+		key   string
+		value **string
+	}{
+		{"dbname", &conn.DbName},
+		{"user", &conn.UserName},
+		{"password", &conn.Password},
+		{"sslmode", &conn.SslMode},
+		{"sslrootcert", &conn.SslRootCert},
+		{"sslcert", &conn.SslCert},
+		{"sslkey", &conn.SslKey},
+		{"application_name", &conn.ApplicationName},
+		{"options", &conn.Options},
+		{"target_session_attrs", &conn.TargetSessionAttrs},
+	} {
+		if section.HasKey(param.key) {
+			value := section.Key(param.key).String()
+			*param.value = &value
+		}
+	}
+
+	if section.HasKey("connect_timeout") {
+		connectTimeout, err := section.Key("connect_timeout").Int()
+		if err != nil {
+			return nil, fmt.Errorf("invalid connect_timeout")
+		}
+		conn.ConnectTimeout = &connectTimeout
+	}
+
+	// host and port may be comma separated lists, for multi-host connections
+	hosts := section.Key("host").Strings(",")
+	ports := section.Key("port").Strings(",")
+	switch {
+	case len(hosts) > 1:
+		conn.Hosts = &[]string{}
+		for i, host := range hosts {
+			// as for libpq, a single port applies to all hosts
+			if len(ports) == 1 {
+				host = fmt.Sprintf("%s:%s", host, ports[0])
+			} else if i < len(ports) {
+				host = fmt.Sprintf("%s:%s", host, ports[i])
+			}
+			*conn.Hosts = append(*conn.Hosts, host)
+		}
+	case len(hosts) == 1:
+		conn.Host = &hosts[0]
+	}
+	if len(ports) == 1 && conn.Hosts == nil {
+		port, err := strconv.Atoi(ports[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %s", ports[0])
+		}
+		conn.Port = &port
+	}
+	return conn, nil
+}
+
+// newImportedPostgresConnection returns a function which creates a copy of the connection with the given name
+func newImportedPostgresConnection(conn PostgresConnection) func(string, hcl.Range) PipelingConnection {
+	return func(shortName string, declRange hcl.Range) PipelingConnection {
+		c := conn
+		c.ConnectionImpl = NewConnectionImpl(PostgresConnectionType, shortName, declRange)
+		return &c
+	}
+}
+
+// loadIniFile loads a config file such as an aws config file or pg_service.conf - these do not support inline
+// comments, so a # or ; within a value is part of the value
+func loadIniFile(source string) (*ini.File, error) {
+	return ini.LoadSources(ini.LoadOptions{
+		AllowNestedValues:   true,
+		IgnoreInlineComment: true,
+	}, source)
+}
@@ -2,12 +2,28 @@ package connection
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/cty_helpers"
+	"github.com/turbot/pipe-fittings/sanitize"
+	"github.com/turbot/pipe-fittings/sslio"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -19,76 +35,61 @@ import (
 func TestAbuseIPDBDefaultConnection(t *testing.T) {
 	assert := assert.New(t)
 
-	abuseIPDBConnection := AbuseIPDBConnection{
-		ConnectionImpl: ConnectionImpl{
-			ShortName: "default",
-		},
-	}
+	abuseIPDBConnection := NewAbuseIPDBConnection("default", hcl.Range{})
 
 	os.Unsetenv("ABUSEIPDB_API_KEY")
 	newConnection, err := abuseIPDBConnection.Resolve(context.TODO())
 	assert.Nil(err)
 
-	newAbuseIPDBConnection := newConnection.(*AbuseIPDBConnection)
-	assert.Equal("", *newAbuseIPDBConnection.APIKey)
+	apiKey, _ := newConnection.(*SpecConnection).GetAttribute("api_key")
+	assert.Equal(cty.StringVal(""), apiKey)
 
 	os.Setenv("ABUSEIPDB_API_KEY", "bfc6f1c42dsfsdfdxxxx26977977b2xxxsfsdda98f313c3d389126de0d")
 
 	newConnection, err = abuseIPDBConnection.Resolve(context.TODO())
 	assert.Nil(err)
 
-	newAbuseIPDBConnection = newConnection.(*AbuseIPDBConnection)
-	assert.Equal("bfc6f1c42dsfsdfdxxxx26977977b2xxxsfsdda98f313c3d389126de0d", *newAbuseIPDBConnection.APIKey)
+	apiKey, _ = newConnection.(*SpecConnection).GetAttribute("api_key")
+	assert.Equal(cty.StringVal("bfc6f1c42dsfsdfdxxxx26977977b2xxxsfsdda98f313c3d389126de0d"), apiKey)
 }
 
 func TestAbuseIPDBConnectionEquals(t *testing.T) {
 	assert := assert.New(t)
 
 	// Case 1: Both connections are nil
-	var conn1 *AbuseIPDBConnection
-	var conn2 *AbuseIPDBConnection
+	var conn1 *SpecConnection
+	var conn2 *SpecConnection
 	assert.True(conn1.Equals(conn2))
 
 	// Case 2: One connection is nil
-	conn1 = &AbuseIPDBConnection{
-		ConnectionImpl: ConnectionImpl{
-			ShortName: "default",
-		},
-	}
+	conn1 = NewAbuseIPDBConnection("default", hcl.Range{}).(*SpecConnection)
 	assert.False(conn1.Equals(nil))
 
 	// Case 3: Both connections have the same API key
-	apiKey := "bfc6f1c42dsfsdfdxxxx26977977b2xxxsfsdda98f313c3d389126de0d" // #nosec
-	conn1.APIKey = &apiKey
-	conn2 = &AbuseIPDBConnection{
-		ConnectionImpl: ConnectionImpl{
-			ShortName: "default",
-		},
-		APIKey: &apiKey,
-	}
+	apiKey := cty.StringVal("bfc6f1c42dsfsdfdxxxx26977977b2xxxsfsdda98f313c3d389126de0d") // #nosec
+	assert.Nil(conn1.SetAttribute("api_key", apiKey))
+	conn2 = NewAbuseIPDBConnection("default", hcl.Range{}).(*SpecConnection)
+	assert.Nil(conn2.SetAttribute("api_key", apiKey))
 	assert.True(conn1.Equals(conn2))
 
 	// Case 4: Connections have different API keys
-	apiKey2 := "bfc6f1c42dsfsdfdxxxx26977977b2xxxsfsdda98f313c3d389126de1d" // #nosec
-	conn2.APIKey = &apiKey2
+	apiKey2 := cty.StringVal("bfc6f1c42dsfsdfdxxxx26977977b2xxxsfsdda98f313c3d389126de1d") // #nosec
+	assert.Nil(conn2.SetAttribute("api_key", apiKey2))
 	assert.False(conn1.Equals(conn2))
 }
 
 func TestAbuseIPDBConnectionValidate(t *testing.T) {
 	assert := assert.New(t)
 
-	// Case 1: Validate an empty AbuseIPDBConnection, should pass with no diagnostics
-	conn := &AbuseIPDBConnection{}
+	// Case 1: Validate an empty AbuseIPDB connection, should pass with no diagnostics
+	conn := NewAbuseIPDBConnection("default", hcl.Range{}).(*SpecConnection)
 	diagnostics := conn.Validate()
-	assert.Len(diagnostics, 0, "Validation should pass with no diagnostics for an empty AbuseIPDBConnection")
+	assert.Len(diagnostics, 0, "Validation should pass with no diagnostics for an empty AbuseIPDB connection")
 
-	// Case 2: Validate a populated AbuseIPDBConnection, should pass with no diagnostics
-	apiKey := "some_api_key"
-	conn = &AbuseIPDBConnection{
-		APIKey: &apiKey,
-	}
+	// Case 2: Validate a populated AbuseIPDB connection, should pass with no diagnostics
+	assert.Nil(conn.SetAttribute("api_key", cty.StringVal("some_api_key")))
 	diagnostics = conn.Validate()
-	assert.Len(diagnostics, 0, "Validation should pass with no diagnostics for a populated AbuseIPDBConnection")
+	assert.Len(diagnostics, 0, "Validation should pass with no diagnostics for a populated AbuseIPDB connection")
 }
 
 // ------------------------------------------------------------
@@ -330,6 +331,185 @@ func TestAwsConnectionValidate(t *testing.T) {
 	}
 	diagnostics = conn.Validate()
 	assert.Len(diagnostics, 0, "Both AccessKey and SecretKey are defined, validation should pass")
+
+	// Case 5: Role properties are defined without RoleArn, should fail validation
+	externalId := "external_id_value"
+	conn = &AwsConnection{
+		ExternalId: &externalId,
+	}
+	diagnostics = conn.Validate()
+	assert.Len(diagnostics, 1, "ExternalId defined without RoleArn, should return an error")
+	assert.Equal("external_id, session_name, duration and web_identity_token_file require role_arn to be defined", diagnostics[0].Summary)
+
+	// Case 6: Duration is out of range, should fail validation
+	roleArn := "arn:aws:iam::123456789012:role/test"
+	duration := 60
+	conn = &AwsConnection{
+		RoleArn:  &roleArn,
+		Duration: &duration,
+	}
+	diagnostics = conn.Validate()
+	assert.Len(diagnostics, 1, "Duration out of range, should return an error")
+	assert.Equal("duration must be between 900 and 43200 seconds", diagnostics[0].Summary)
+}
+
+// newStsStub starts a stub STS endpoint which returns credentials expiring at the given time for
+// AssumeRole and AssumeRoleWithWebIdentity requests, and records the request form values, along with the region
+// the request was signed for as SigningRegion
+func newStsStub(t *testing.T, expiration time.Time) *[]url.Values {
+	t.Helper()
+	var requests []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// the credential scope of the signature is <key>/<date>/<region>/<service>/aws4_request
+		if _, scope, ok := strings.Cut(r.Header.Get("Authorization"), "Credential="); ok {
+			if parts := strings.Split(scope, "/"); len(parts) > 2 {
+				r.PostForm.Set("SigningRegion", parts[2])
+			}
+		}
+		requests = append(requests, r.PostForm)
+		action := r.PostForm.Get("Action")
+		w.Header().Set("Content-Type", "text/xml")
+		fmt.Fprintf(w, `<%[1]sResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <%[1]sResult>
+    <Credentials>
+      <AccessKeyId>ASIAASSUMED</AccessKeyId>
+      <SecretAccessKey>assumed_secret</SecretAccessKey>
+      <SessionToken>assumed_token</SessionToken>
+      <Expiration>%[2]s</Expiration>
+    </Credentials>
+  </%[1]sResult>
+</%[1]sResponse>`, action, expiration.UTC().Format(time.RFC3339))
+	}))
+	t.Cleanup(server.Close)
+
+	// isolate the test from any local aws config
+	t.Setenv("AWS_ENDPOINT_URL_STS", server.URL)
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_REGION", "")
+	return &requests
+}
+
+func TestAwsConnectionAssumeRole(t *testing.T) {
+	assert := assert.New(t)
+
+	expiration := time.Now().Add(time.Hour)
+	requests := newStsStub(t, expiration)
+
+	conn := &AwsConnection{
+		ConnectionImpl: ConnectionImpl{ShortName: "default"},
+		AccessKey:      utils.ToStringPointer("source_access_key"),
+		SecretKey:      utils.ToStringPointer("source_secret_key"),
+		Region:         utils.ToStringPointer("eu-west-2"),
+		RoleArn:        utils.ToStringPointer("arn:aws:iam::123456789012:role/test"),
+		ExternalId:     utils.ToStringPointer("external"),
+		SessionName:    utils.ToStringPointer("flowpipe"),
+		Duration:       utils.ToPointer(1800),
+	}
+	newConnection, err := conn.Resolve(context.Background())
+	if !assert.NoError(err) {
+		return
+	}
+
+	newAwsConnection := newConnection.(*AwsConnection)
+	assert.Equal("ASIAASSUMED", *newAwsConnection.AccessKey)
+	assert.Equal("assumed_secret", *newAwsConnection.SecretKey)
+	assert.Equal("assumed_token", *newAwsConnection.SessionToken)
+	assert.Equal("eu-west-2", *newAwsConnection.Region)
+	assert.Nil(newAwsConnection.RoleArn)
+
+	// the ttl is derived from the credential expiry
+	assert.InDelta(3600-awsCredentialExpiryWindow, newAwsConnection.GetTtl(), 5)
+
+	if assert.Len(*requests, 1) {
+		form := (*requests)[0]
+		assert.Equal("AssumeRole", form.Get("Action"))
+		assert.Equal("arn:aws:iam::123456789012:role/test", form.Get("RoleArn"))
+		assert.Equal("external", form.Get("ExternalId"))
+		assert.Equal("flowpipe", form.Get("RoleSessionName"))
+		assert.Equal("1800", form.Get("DurationSeconds"))
+	}
+}
+
+func TestAwsConnectionWebIdentity(t *testing.T) {
+	assert := assert.New(t)
+
+	requests := newStsStub(t, time.Now().Add(time.Hour))
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if !assert.NoError(os.WriteFile(tokenFile, []byte("web_identity_token"), 0600)) {
+		return
+	}
+
+	conn := &AwsConnection{
+		ConnectionImpl:       ConnectionImpl{ShortName: "default"},
+		RoleArn:              utils.ToStringPointer("arn:aws:iam::123456789012:role/test"),
+		SessionName:          utils.ToStringPointer("ci"),
+		WebIdentityTokenFile: &tokenFile,
+	}
+	newConnection, err := conn.Resolve(context.Background())
+	if !assert.NoError(err) {
+		return
+	}
+
+	newAwsConnection := newConnection.(*AwsConnection)
+	assert.Equal("ASIAASSUMED", *newAwsConnection.AccessKey)
+	// the default region is only used to retrieve the credentials, and is not exported
+	assert.Nil(newAwsConnection.Region)
+	assert.NotContains(newAwsConnection.GetEnv(), "AWS_REGION")
+
+	if assert.Len(*requests, 1) {
+		form := (*requests)[0]
+		assert.Equal("AssumeRoleWithWebIdentity", form.Get("Action"))
+		assert.Equal("web_identity_token", form.Get("WebIdentityToken"))
+		assert.Equal("ci", form.Get("RoleSessionName"))
+	}
+}
+
+func TestAwsConnectionProfileAssumeRoleWithoutRegion(t *testing.T) {
+	assert := assert.New(t)
+
+	requests := newStsStub(t, time.Now().Add(time.Hour))
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	// a profile which assumes a role using a source profile, with no region configured
+	configFile := filepath.Join(t.TempDir(), "config")
+	if !assert.NoError(os.WriteFile(configFile, []byte(`[profile source]
+aws_access_key_id = source_access_key
+aws_secret_access_key = source_secret_key
+
+[profile assumer]
+role_arn = arn:aws:iam::123456789012:role/test
+source_profile = source
+`), 0600)) {
+		return
+	}
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+
+	conn := &AwsConnection{
+		ConnectionImpl: ConnectionImpl{ShortName: "default"},
+		Profile:        utils.ToStringPointer("assumer"),
+	}
+	newConnection, err := conn.Resolve(context.Background())
+	if !assert.NoError(err) {
+		return
+	}
+
+	newAwsConnection := newConnection.(*AwsConnection)
+	assert.Equal("ASIAASSUMED", *newAwsConnection.AccessKey)
+	assert.Nil(newAwsConnection.Region)
+	if assert.Len(*requests, 1) {
+		assert.Equal("AssumeRole", (*requests)[0].Get("Action"))
+		assert.Equal(defaultAwsRegion, (*requests)[0].Get("SigningRegion"))
+	}
 }
 
 // ------------------------------------------------------------
@@ -2070,6 +2250,46 @@ func TestMysqlConnectionGetConnectionString(t *testing.T) {
 	}
 }
 
+func TestMysqlConnectionTls(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, t.TempDir())
+
+	conn := &MysqlConnection{
+		ConnectionImpl: ConnectionImpl{ShortName: "default", FullName: "mysql.default"},
+		Tls:            utils.ToStringPointer("true"),
+	}
+	require.Len(t, conn.Validate(), 0)
+	assert.Equal(t, "mysql://root@tcp(localhost:3306)/mysql?tls=true", conn.GetConnectionString())
+
+	// if certificates are set, they are passed to the mysql backend as connection string parameters
+	conn.SslCa = &certFile
+	conn.SslCert = &certFile
+	conn.SslKey = &keyFile
+	require.Len(t, conn.Validate(), 0)
+	expected := fmt.Sprintf("mysql://root@tcp(localhost:3306)/mysql?tls=true&ssl-ca=%s&ssl-cert=%s&ssl-key=%s",
+		url.QueryEscape(certFile), url.QueryEscape(certFile), url.QueryEscape(keyFile))
+	assert.Equal(t, expected, conn.GetConnectionString())
+	_, err := conn.Resolve(context.Background())
+	require.NoError(t, err)
+
+	// tls is required if certificates are set and tls is not
+	conn.Tls = nil
+	assert.Equal(t, expected, conn.GetConnectionString())
+
+	// certificates cannot be used if tls is disabled
+	conn.Tls = utils.ToStringPointer("false")
+	assert.Len(t, conn.Validate(), 1)
+
+	conn = &MysqlConnection{Tls: utils.ToStringPointer("required")}
+	assert.Len(t, conn.Validate(), 1)
+
+	// the certificate files are checked when the connection is resolved, rather than when it is parsed
+	missingFile := filepath.Join(t.TempDir(), "missing.crt")
+	conn = &MysqlConnection{SslCa: &missingFile}
+	assert.Len(t, conn.Validate(), 0)
+	_, err = conn.Resolve(context.Background())
+	assert.Error(t, err)
+}
+
 // ------------------------------------------------------------
 // Okta
 // ------------------------------------------------------------
@@ -2808,6 +3028,110 @@ func TestPostgresConnectionGetEnv(t *testing.T) {
 	}
 }
 
+// writeTestCertificate writes a self-signed certificate and private key to the directory, returning their paths
+func writeTestCertificate(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	require.NoError(t, sslio.WriteCertificate(certFile, cert))
+	require.NoError(t, sslio.WritePrivateKey(keyFile, key))
+	return certFile, keyFile
+}
+
+func TestPostgresConnectionTls(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir)
+
+	conn := &PostgresConnection{
+		Host:            utils.ToStringPointer("host"),
+		SslMode:         utils.ToStringPointer("verify-full"),
+		SslRootCert:     &certFile,
+		SslCert:         &certFile,
+		SslKey:          &keyFile,
+		ApplicationName: utils.ToStringPointer("app"),
+		ConnectTimeout:  utils.ToIntegerPointer(10),
+	}
+	require.Len(t, conn.Validate(), 0)
+
+	connString, err := url.Parse(conn.GetConnectionString())
+	require.NoError(t, err)
+	assert.Equal(t, "host:5432", connString.Host)
+	assert.Equal(t, url.Values{
+		"sslmode":          {"verify-full"},
+		"sslrootcert":      {certFile},
+		"sslcert":          {certFile},
+		"sslkey":           {keyFile},
+		"application_name": {"app"},
+		"connect_timeout":  {"10"},
+	}, connString.Query())
+
+	env := conn.GetEnv()
+	assert.Equal(t, cty.StringVal(certFile), env["PGSSLROOTCERT"])
+	assert.Equal(t, cty.StringVal(keyFile), env["PGSSLKEY"])
+	assert.Equal(t, cty.StringVal("app"), env["PGAPPNAME"])
+	assert.Equal(t, cty.StringVal("10"), env["PGCONNECT_TIMEOUT"])
+
+	// a client certificate requires a key
+	conn = &PostgresConnection{SslCert: &certFile}
+	assert.Len(t, conn.Validate(), 1)
+
+	// the certificate file must exist and be a certificate - this is checked when the connection is resolved,
+	// rather than when it is parsed
+	missingFile := filepath.Join(dir, "missing.crt")
+	conn = &PostgresConnection{SslRootCert: &missingFile}
+	assert.Len(t, conn.Validate(), 0)
+	_, err = conn.Resolve(context.Background())
+	assert.Error(t, err)
+	conn = &PostgresConnection{SslCert: &keyFile, SslKey: &keyFile}
+	_, err = conn.Resolve(context.Background())
+	assert.Error(t, err)
+	conn = &PostgresConnection{SslRootCert: &certFile, SslCert: &certFile, SslKey: &keyFile}
+	_, err = conn.Resolve(context.Background())
+	assert.NoError(t, err)
+
+	// the certificate and key are file paths, not secrets, so secret references are not dereferenced
+	conn = &PostgresConnection{SslRootCert: utils.ToStringPointer("vault://secret/app#ca")}
+	_, err = conn.Resolve(context.Background())
+	assert.Error(t, err)
+}
+
+func TestPostgresConnectionMultiHost(t *testing.T) {
+	conn := &PostgresConnection{
+		Hosts:              &[]string{"primary", "standby:5433", "[::1]:5434"},
+		Port:               utils.ToIntegerPointer(1234),
+		TargetSessionAttrs: utils.ToStringPointer("read-write"),
+	}
+	require.Len(t, conn.Validate(), 0)
+	assert.Equal(t, "postgresql://postgres@primary:1234,standby:5433,[::1]:5434/postgres?target_session_attrs=read-write", conn.GetConnectionString())
+
+	env := conn.GetEnv()
+	assert.Equal(t, cty.StringVal("primary,standby,::1"), env["PGHOST"])
+	assert.Equal(t, cty.StringVal("1234,5433,5434"), env["PGPORT"])
+	assert.Equal(t, cty.StringVal("read-write"), env["PGTARGETSESSIONATTRS"])
+
+	// host and hosts are mutually exclusive
+	conn = &PostgresConnection{Host: utils.ToStringPointer("host"), Hosts: &[]string{"primary"}}
+	assert.Len(t, conn.Validate(), 1)
+
+	conn = &PostgresConnection{Hosts: &[]string{"primary:port"}}
+	assert.Len(t, conn.Validate(), 1)
+
+	conn = &PostgresConnection{TargetSessionAttrs: utils.ToStringPointer("writable")}
+	assert.Len(t, conn.Validate(), 1)
+}
+
 // ------------------------------------------------------------
 // SendGrid
 // ------------------------------------------------------------
@@ -4322,3 +4646,61 @@ func TestZendeskConnectionValidate(t *testing.T) {
 	diagnostics = conn.Validate()
 	assert.Len(diagnostics, 0, "Validation should pass with no diagnostics for a populated ZendeskConnection")
 }
+
+func TestConnectionSecretRedaction(t *testing.T) {
+	conn := NewAwsConnection("default", hcl.Range{}).(*AwsConnection)
+	conn.AccessKey = utils.ToStringPointer("AKIA123")
+	conn.SecretKey = utils.ToStringPointer("secret")
+	conn.Region = utils.ToStringPointer("us-east-1")
+
+	// the connection cty value is used for execution so is not redacted
+	ctyValue, err := conn.CtyValue()
+	require.NoError(t, err)
+	assert.Equal(t, cty.StringVal("secret"), ctyValue.GetAttr("secret_key"))
+	assert.Equal(t, cty.StringVal("secret"), ctyValue.GetAttr("env").GetAttr("AWS_SECRET_ACCESS_KEY"))
+
+	// the redacted cty value redacts secrets, including the env
+	ctyValue, err = RedactedCtyValue(conn)
+	require.NoError(t, err)
+	assert.Equal(t, cty.StringVal(sanitize.RedactedStr), ctyValue.GetAttr("access_key"))
+	assert.Equal(t, cty.StringVal(sanitize.RedactedStr), ctyValue.GetAttr("secret_key"))
+	assert.True(t, ctyValue.GetAttr("session_token").IsNull())
+	assert.Equal(t, cty.StringVal("us-east-1"), ctyValue.GetAttr("region"))
+	env := ctyValue.GetAttr("env")
+	assert.Equal(t, cty.StringVal(sanitize.RedactedStr), env.GetAttr("AWS_SECRET_ACCESS_KEY"))
+	assert.Equal(t, cty.StringVal("us-east-1"), env.GetAttr("AWS_REGION"))
+
+	// GetCtyValue only redacts secrets if requested
+	ctyValue, err = cty_helpers.GetCtyValue(conn)
+	require.NoError(t, err)
+	assert.Equal(t, cty.StringVal("secret"), ctyValue.GetAttr("secret_key"))
+	ctyValue, err = cty_helpers.GetCtyValue(conn, cty_helpers.WithRedactedSecrets())
+	require.NoError(t, err)
+	assert.Equal(t, cty.StringVal(sanitize.RedactedStr), ctyValue.GetAttr("secret_key"))
+
+	redacted := sanitize.RedactSecrets(conn)
+	assert.Equal(t, sanitize.RedactedStr, *redacted.SecretKey)
+	assert.Equal(t, "us-east-1", *redacted.Region)
+	assert.Equal(t, "secret", *conn.SecretKey)
+
+	// spec connections redact the attributes declared as secrets
+	var specConn PipelingConnection = testConnectionSpec.NewConnection("default", hcl.Range{})
+	require.NoError(t, specConn.(*SpecConnection).SetAttribute("token", cty.StringVal("abc")))
+	require.NoError(t, specConn.(*SpecConnection).SetAttribute("region", cty.StringVal("us-east-1")))
+	redactedSpec := sanitize.RedactSecrets(specConn).(*SpecConnection)
+	token, _ := redactedSpec.GetAttribute("token")
+	region, _ := redactedSpec.GetAttribute("region")
+	assert.Equal(t, cty.StringVal(sanitize.RedactedStr), token)
+	assert.Equal(t, cty.StringVal("us-east-1"), region)
+	token, _ = specConn.(*SpecConnection).GetAttribute("token")
+	assert.Equal(t, cty.StringVal("abc"), token)
+
+	ctyValue, err = specConn.CtyValue()
+	require.NoError(t, err)
+	assert.Equal(t, cty.StringVal("abc"), ctyValue.GetAttr("token"))
+	assert.Equal(t, cty.StringVal("abc"), ctyValue.GetAttr("env").GetAttr("TEST_CONN_TOKEN"))
+	ctyValue, err = RedactedCtyValue(specConn)
+	require.NoError(t, err)
+	assert.Equal(t, cty.StringVal(sanitize.RedactedStr), ctyValue.GetAttr("token"))
+	assert.Equal(t, cty.StringVal(sanitize.RedactedStr), ctyValue.GetAttr("env").GetAttr("TEST_CONN_TOKEN"))
+}
@@ -3,20 +3,25 @@ package connection
 import (
 	"context"
 	"os"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
 )
 
-const DatadogConnectionType = "datadog"
+const (
+	DatadogConnectionType = "datadog"
+	defaultDatadogApiUrl  = "https://api.datadoghq.com"
+)
 
 type DatadogConnection struct {
 	ConnectionImpl
 
-	APIKey *string `json:"api_key,omitempty" cty:"api_key" hcl:"api_key,optional"`
-	AppKey *string `json:"app_key,omitempty" cty:"app_key" hcl:"app_key,optional"`
+	APIKey *string `json:"api_key,omitempty" cty:"api_key" hcl:"api_key,optional" secret:"true"`
+	AppKey *string `json:"app_key,omitempty" cty:"app_key" hcl:"app_key,optional" secret:"true"`
 	APIUrl *string `json:"api_url,omitempty" cty:"api_url" hcl:"api_url,optional"`
 }
 
@@ -30,6 +35,11 @@ func (c *DatadogConnection) GetConnectionType() string {
 }
 
 func (c *DatadogConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &DatadogConnection{ConnectionImpl: c.ConnectionImpl})
@@ -118,3 +128,17 @@ func (c *DatadogConnection) GetEnv() map[string]cty.Value {
 	}
 	return env
 }
+
+// Verify calls the Datadog API to validate the api key
+func (c *DatadogConnection) Verify(ctx context.Context) error {
+	apiUrl := defaultDatadogApiUrl
+	if c.APIUrl != nil && *c.APIUrl != "" {
+		apiUrl = strings.TrimSuffix(*c.APIUrl, "/")
+	}
+	req, err := newVerifyRequest(ctx, apiUrl+"/api/v1/validate")
+	if err != nil {
+		return err
+	}
+	req.Header.Set("DD-API-KEY", typehelpers.SafeString(c.APIKey))
+	return verifyHttpRequest(ctx, req, nil)
+}
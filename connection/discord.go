@@ -6,16 +6,20 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
 )
 
-const DiscordConnectionType = "discord"
+const (
+	DiscordConnectionType = "discord"
+	discordVerifyUrl      = "https://discord.com/api/v10/users/@me"
+)
 
 type DiscordConnection struct {
 	ConnectionImpl
 
-	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional"`
+	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional" secret:"true"`
 }
 
 func NewDiscordConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -27,6 +31,11 @@ func (c *DiscordConnection) GetConnectionType() string {
 	return DiscordConnectionType
 }
 func (c *DiscordConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &DiscordConnection{ConnectionImpl: c.ConnectionImpl})
@@ -94,3 +103,13 @@ func (c *DiscordConnection) GetEnv() map[string]cty.Value {
 	}
 	return env
 }
+
+// Verify calls the Discord API to get the bot user
+func (c *DiscordConnection) Verify(ctx context.Context) error {
+	req, err := newVerifyRequest(ctx, discordVerifyUrl)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bot "+typehelpers.SafeString(c.Token))
+	return verifyHttpRequest(ctx, req, nil)
+}
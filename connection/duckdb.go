@@ -3,7 +3,10 @@ package connection
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
@@ -16,6 +19,18 @@ const DuckDbConnectionType = "duckdb"
 type DuckDbConnection struct {
 	ConnectionImpl
 	FileName *string `json:"file_name,omitempty" cty:"file_name" hcl:"file_name,optional"`
+	// extensions to load - if not set, the json extension is loaded
+	Extensions *[]string `json:"extensions,omitempty" cty:"extensions" hcl:"extensions,optional"`
+	// if false, extensions are loaded but never installed
+	InstallExtensions *bool `json:"install_extensions,omitempty" cty:"install_extensions" hcl:"install_extensions,optional"`
+	// databases to attach, in the form [alias=]path
+	Attach      *[]string `json:"attach,omitempty" cty:"attach" hcl:"attach,optional"`
+	ReadOnly    *bool     `json:"read_only,omitempty" cty:"read_only" hcl:"read_only,optional"`
+	Threads     *int      `json:"threads,omitempty" cty:"threads" hcl:"threads,optional"`
+	MemoryLimit *string   `json:"memory_limit,omitempty" cty:"memory_limit" hcl:"memory_limit,optional"`
+	// schemas to search for unqualified names, e.g. main or an attached database alias
+	SearchPath       *[]string `json:"search_path,omitempty" cty:"search_path" hcl:"search_path,optional"`
+	SearchPathPrefix *[]string `json:"search_path_prefix,omitempty" cty:"search_path_prefix" hcl:"search_path_prefix,optional"`
 	// used only to set the connection string from command line variable value with a connection string
 	ConnectionString *string `json:"connection_string,omitempty" cty:"connection_string"`
 }
@@ -30,6 +45,11 @@ func (c *DuckDbConnection) GetConnectionType() string {
 }
 
 func (c *DuckDbConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &DuckDbConnection{ConnectionImpl: c.ConnectionImpl})
@@ -49,6 +69,16 @@ func (c *DuckDbConnection) Validate() hcl.Diagnostics {
 		}
 	}
 
+	if c.Threads != nil && *c.Threads < 1 {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "threads must be greater than zero",
+				Subject:  c.DeclRange.HclRangePointer(),
+			},
+		}
+	}
+
 	return hcl.Diagnostics{}
 }
 
@@ -56,6 +86,20 @@ func (c *DuckDbConnection) GetEnv() map[string]cty.Value {
 	return map[string]cty.Value{}
 }
 
+func (c *DuckDbConnection) GetSearchPath() []string {
+	if c.SearchPath != nil {
+		return *c.SearchPath
+	}
+	return []string{}
+}
+
+func (c *DuckDbConnection) GetSearchPathPrefix() []string {
+	if c.SearchPathPrefix != nil {
+		return *c.SearchPathPrefix
+	}
+	return []string{}
+}
+
 func (c *DuckDbConnection) Equals(otherConnection PipelingConnection) bool {
 	// If both pointers are nil, they are considered equal
 	if c == nil && helpers.IsNil(otherConnection) {
@@ -71,7 +115,15 @@ func (c *DuckDbConnection) Equals(otherConnection PipelingConnection) bool {
 		return false
 	}
 
-	return utils.PtrEqual(c.FileName, other.FileName)
+	return utils.PtrEqual(c.FileName, other.FileName) &&
+		utils.SlicePtrEqual(c.Extensions, other.Extensions) &&
+		utils.BoolPtrEqual(c.InstallExtensions, other.InstallExtensions) &&
+		utils.SlicePtrEqual(c.Attach, other.Attach) &&
+		utils.BoolPtrEqual(c.ReadOnly, other.ReadOnly) &&
+		utils.PtrEqual(c.Threads, other.Threads) &&
+		utils.PtrEqual(c.MemoryLimit, other.MemoryLimit) &&
+		utils.SlicePtrEqual(c.SearchPath, other.SearchPath) &&
+		utils.SlicePtrEqual(c.SearchPathPrefix, other.SearchPathPrefix)
 
 }
 
@@ -84,7 +136,35 @@ func (c *DuckDbConnection) GetConnectionString() string {
 		return *c.ConnectionString
 	}
 
-	return fmt.Sprintf("duckdb://%s", c.getFileName())
+	connString := fmt.Sprintf("duckdb://%s", c.getFileName())
+	if params := c.connectionStringParams(); len(params) > 0 {
+		connString += "?" + params.Encode()
+	}
+	return connString
+}
+
+// connectionStringParams returns the duckdb backend connection string parameters for the connection settings
+func (c *DuckDbConnection) connectionStringParams() url.Values {
+	params := url.Values{}
+	if c.Extensions != nil {
+		params.Set("extensions", strings.Join(*c.Extensions, ","))
+	}
+	if c.InstallExtensions != nil {
+		params.Set("install_extensions", strconv.FormatBool(*c.InstallExtensions))
+	}
+	if c.Attach != nil {
+		params["attach"] = *c.Attach
+	}
+	if c.ReadOnly != nil && *c.ReadOnly {
+		params.Set("access_mode", "read_only")
+	}
+	if c.Threads != nil {
+		params.Set("threads", strconv.Itoa(*c.Threads))
+	}
+	if c.MemoryLimit != nil {
+		params.Set("memory_limit", *c.MemoryLimit)
+	}
+	return params
 }
 
 func (c *DuckDbConnection) getFileName() any {
@@ -93,3 +173,8 @@ func (c *DuckDbConnection) getFileName() any {
 	}
 	return os.Getenv("DUCKDB_FILENAME")
 }
+
+// Verify connects to the database and runs a test query
+func (c *DuckDbConnection) Verify(ctx context.Context) error {
+	return verifyDatabaseConnection(ctx, c.GetConnectionString())
+}
@@ -15,7 +15,7 @@ const FreshdeskConnectionType = "freshdesk"
 type FreshdeskConnection struct {
 	ConnectionImpl
 
-	APIKey    *string `json:"api_key,omitempty" cty:"api_key" hcl:"api_key,optional"`
+	APIKey    *string `json:"api_key,omitempty" cty:"api_key" hcl:"api_key,optional" secret:"true"`
 	Subdomain *string `json:"subdomain,omitempty" cty:"subdomain" hcl:"subdomain,optional"`
 }
 
@@ -29,6 +29,11 @@ func (c *FreshdeskConnection) GetConnectionType() string {
 }
 
 func (c *FreshdeskConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &FreshdeskConnection{ConnectionImpl: c.ConnectionImpl})
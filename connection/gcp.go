@@ -22,8 +22,8 @@ const (
 type GcpConnection struct {
 	ConnectionImpl
 
-	Credentials *string `json:"credentials,omitempty" cty:"credentials" hcl:"credentials,optional"`
-	AccessToken *string `json:"access_token,omitempty" cty:"access_token" hcl:"access_token,optional"`
+	Credentials *string `json:"credentials,omitempty" cty:"credentials" hcl:"credentials,optional" secret:"true"`
+	AccessToken *string `json:"access_token,omitempty" cty:"access_token" hcl:"access_token,optional" secret:"true"`
 }
 
 func NewGcpConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -39,6 +39,11 @@ func (c *GcpConnection) GetConnectionType() string {
 }
 
 func (c *GcpConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &GcpConnection{ConnectionImpl: c.ConnectionImpl})
@@ -6,19 +6,28 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
 )
 
-const GithubConnectionType = "github"
+const (
+	GithubConnectionType = "github"
+	githubVerifyUrl      = "https://api.github.com/user"
+)
 
 type GithubConnection struct {
 	ConnectionImpl
 
-	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional"`
+	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional" secret:"true"`
 }
 
 func (c *GithubConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &GithubConnection{ConnectionImpl: c.ConnectionImpl})
@@ -95,3 +104,13 @@ func (c *GithubConnection) GetEnv() map[string]cty.Value {
 	}
 	return env
 }
+
+// Verify calls the GitHub API to get the authenticated user
+func (c *GithubConnection) Verify(ctx context.Context) error {
+	req, err := newVerifyRequest(ctx, githubVerifyUrl)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+typehelpers.SafeString(c.Token))
+	return verifyHttpRequest(ctx, req, nil)
+}
@@ -6,16 +6,20 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
 )
 
-const GitLabConnectionType = "gitlab"
+const (
+	GitLabConnectionType = "gitlab"
+	gitlabVerifyUrl      = "https://gitlab.com/api/v4/user"
+)
 
 type GitLabConnection struct {
 	ConnectionImpl
 
-	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional"`
+	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional" secret:"true"`
 }
 
 func NewGitLabConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -28,6 +32,11 @@ func (c *GitLabConnection) GetConnectionType() string {
 }
 
 func (c *GitLabConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &GitLabConnection{ConnectionImpl: c.ConnectionImpl})
@@ -93,3 +102,13 @@ func (c *GitLabConnection) GetEnv() map[string]cty.Value {
 	// https://github.com/xanzy/go-gitlab
 	return nil
 }
+
+// Verify calls the GitLab API to get the authenticated user
+func (c *GitLabConnection) Verify(ctx context.Context) error {
+	req, err := newVerifyRequest(ctx, gitlabVerifyUrl)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", typehelpers.SafeString(c.Token))
+	return verifyHttpRequest(ctx, req, nil)
+}
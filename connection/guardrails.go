@@ -15,8 +15,8 @@ const GuardrailsConnectionType = "guardrails"
 type GuardrailsConnection struct {
 	ConnectionImpl
 
-	AccessKey *string `json:"access_key,omitempty" cty:"access_key" hcl:"access_key,optional"`
-	SecretKey *string `json:"secret_key,omitempty" cty:"secret_key" hcl:"secret_key,optional"`
+	AccessKey *string `json:"access_key,omitempty" cty:"access_key" hcl:"access_key,optional" secret:"true"`
+	SecretKey *string `json:"secret_key,omitempty" cty:"secret_key" hcl:"secret_key,optional" secret:"true"`
 	Workspace *string `json:"workspace,omitempty" cty:"workspace" hcl:"workspace,optional"`
 }
 
@@ -30,6 +30,11 @@ func (c *GuardrailsConnection) GetConnectionType() string {
 }
 
 func (c *GuardrailsConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &GuardrailsConnection{ConnectionImpl: c.ConnectionImpl})
@@ -15,7 +15,7 @@ const IP2LocationIOConnectionType = "ip2locationio"
 type IP2LocationIOConnection struct {
 	ConnectionImpl
 
-	APIKey *string `json:"api_key,omitempty" cty:"api_key" hcl:"api_key,optional"`
+	APIKey *string `json:"api_key,omitempty" cty:"api_key" hcl:"api_key,optional" secret:"true"`
 }
 
 func NewIP2LocationIOConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -29,6 +29,11 @@ func (c *IP2LocationIOConnection) GetConnectionType() string {
 }
 
 func (c *IP2LocationIOConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &IP2LocationIOConnection{ConnectionImpl: c.ConnectionImpl})
@@ -15,7 +15,7 @@ const IPstackConnectionType = "ipstack"
 type IPstackConnection struct {
 	ConnectionImpl
 
-	AccessKey *string `json:"access_key,omitempty" cty:"access_key" hcl:"access_key,optional"`
+	AccessKey *string `json:"access_key,omitempty" cty:"access_key" hcl:"access_key,optional" secret:"true"`
 }
 
 func NewIPstackConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -28,6 +28,11 @@ func (c *IPstackConnection) GetConnectionType() string {
 }
 
 func (c *IPstackConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &IPstackConnection{ConnectionImpl: c.ConnectionImpl})
@@ -3,9 +3,12 @@ package connection
 import (
 	"context"
 	"os"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/pipe-fittings/perr"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -15,9 +18,14 @@ const JiraConnectionType = "jira"
 type JiraConnection struct {
 	ConnectionImpl
 
-	APIToken *string `json:"api_token,omitempty" cty:"api_token" hcl:"api_token,optional"`
+	APIToken *string `json:"api_token,omitempty" cty:"api_token" hcl:"api_token,optional" secret:"true"`
 	BaseURL  *string `json:"base_url,omitempty" cty:"base_url" hcl:"base_url,optional"`
 	Username *string `json:"username,omitempty" cty:"username" hcl:"username,optional"`
+
+	// if set, the oauth2 credentials are exchanged for an access token when the connection is resolved
+	OAuth2 *OAuth2Config `json:"oauth2,omitempty" cty:"oauth2" hcl:"oauth2,block"`
+	// the oauth2 access token is populated by Resolve
+	AccessToken *string `json:"access_token,omitempty" cty:"access_token" secret:"true"`
 }
 
 func NewJiraConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -30,11 +38,28 @@ func (c *JiraConnection) GetConnectionType() string {
 }
 
 func (c *JiraConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &JiraConnection{ConnectionImpl: c.ConnectionImpl})
 	}
 
+	// if an oauth2 block is defined, exchange the oauth2 credentials for an access token
+	if c.OAuth2 != nil {
+		newConnection := *c
+		oauth2, err := c.OAuth2.Resolve(ctx, &newConnection.ConnectionImpl)
+		if err != nil {
+			return nil, err
+		}
+		newConnection.OAuth2 = oauth2
+		newConnection.AccessToken = oauth2.AccessToken
+		return &newConnection, nil
+	}
+
 	if c.APIToken == nil && c.BaseURL == nil && c.Username == nil {
 		// The order of precedence for the Jira API token environment variable
 		// 1. JIRA_API_TOKEN
@@ -87,11 +112,15 @@ func (c *JiraConnection) Equals(otherConnection PipelingConnection) bool {
 		return false
 	}
 
+	if !c.OAuth2.Equals(other.OAuth2) {
+		return false
+	}
+
 	return c.GetConnectionImpl().Equals(otherConnection.GetConnectionImpl())
 }
 
 func (c *JiraConnection) Validate() hcl.Diagnostics {
-	if c.Pipes != nil && (c.APIToken != nil || c.BaseURL != nil || c.Username != nil) {
+	if c.Pipes != nil && (c.APIToken != nil || c.BaseURL != nil || c.Username != nil || c.OAuth2 != nil) {
 		return hcl.Diagnostics{
 			{
 				Severity: hcl.DiagError,
@@ -100,6 +129,9 @@ func (c *JiraConnection) Validate() hcl.Diagnostics {
 			},
 		}
 	}
+	if c.OAuth2 != nil {
+		return c.OAuth2.Validate(c.DeclRange.HclRangePointer())
+	}
 	return hcl.Diagnostics{}
 }
 
@@ -110,5 +142,28 @@ func (c *JiraConnection) CtyValue() (cty.Value, error) {
 }
 
 func (c *JiraConnection) GetEnv() map[string]cty.Value {
-	return nil
+	env := map[string]cty.Value{}
+	if c.AccessToken != nil {
+		env["JIRA_PERSONAL_ACCESS_TOKEN"] = cty.StringVal(*c.AccessToken)
+	}
+	return env
+}
+
+// Verify calls the Jira API to get the authenticated user
+func (c *JiraConnection) Verify(ctx context.Context) error {
+	if typehelpers.SafeString(c.BaseURL) == "" {
+		return perr.BadRequestWithMessage("base_url is required to verify a jira connection")
+	}
+	req, err := newVerifyRequest(ctx, strings.TrimSuffix(*c.BaseURL, "/")+"/rest/api/2/myself")
+	if err != nil {
+		return err
+	}
+	if c.OAuth2 != nil {
+		if err := c.OAuth2.setAuthorization(req); err != nil {
+			return err
+		}
+	} else {
+		req.SetBasicAuth(typehelpers.SafeString(c.Username), typehelpers.SafeString(c.APIToken))
+	}
+	return verifyHttpRequest(ctx, req, nil)
 }
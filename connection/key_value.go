@@ -0,0 +1,93 @@
+package connection
+
+import (
+	"context"
+	"maps"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const KeyValueConnectionType = "key_value"
+
+// KeyValueConnection is a generic connection containing arbitrary string values, e.g. imported from a .env file.
+// The values are exported as env vars
+type KeyValueConnection struct {
+	ConnectionImpl
+
+	Values *map[string]string `json:"values,omitempty" cty:"values" hcl:"values,optional" secret:"true"`
+}
+
+func NewKeyValueConnection(shortName string, declRange hcl.Range) PipelingConnection {
+	return &KeyValueConnection{
+		ConnectionImpl: NewConnectionImpl(KeyValueConnectionType, shortName, declRange),
+	}
+}
+
+func (c *KeyValueConnection) GetConnectionType() string {
+	return KeyValueConnectionType
+}
+
+func (c *KeyValueConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	// if pipes metadata is set, call pipes to retrieve the creds
+	if c.Pipes != nil {
+		return c.Pipes.Resolve(ctx, &KeyValueConnection{ConnectionImpl: c.ConnectionImpl})
+	}
+	return c, nil
+}
+
+func (c *KeyValueConnection) Equals(otherConnection PipelingConnection) bool {
+	// If both pointers are nil, they are considered equal
+	if c == nil && helpers.IsNil(otherConnection) {
+		return true
+	}
+
+	if (c == nil && !helpers.IsNil(otherConnection)) || (c != nil && helpers.IsNil(otherConnection)) {
+		return false
+	}
+
+	other, ok := otherConnection.(*KeyValueConnection)
+	if !ok {
+		return false
+	}
+
+	if (c.Values == nil) != (other.Values == nil) || (c.Values != nil && !maps.Equal(*c.Values, *other.Values)) {
+		return false
+	}
+
+	return c.GetConnectionImpl().Equals(otherConnection.GetConnectionImpl())
+}
+
+func (c *KeyValueConnection) Validate() hcl.Diagnostics {
+	if c.Pipes != nil && c.Values != nil {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "if pipes block is defined, no other auth properties should be set",
+				Subject:  c.DeclRange.HclRangePointer(),
+			},
+		}
+	}
+	return hcl.Diagnostics{}
+}
+
+func (c *KeyValueConnection) CtyValue() (cty.Value, error) {
+	return ctyValueForConnection(c)
+}
+
+func (c *KeyValueConnection) GetEnv() map[string]cty.Value {
+	env := map[string]cty.Value{}
+	if c.Values == nil {
+		return env
+	}
+	for key, value := range *c.Values {
+		env[key] = cty.StringVal(value)
+	}
+	return env
+}
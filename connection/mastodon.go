@@ -14,7 +14,7 @@ const MastodonConnectionType = "mastodon"
 type MastodonConnection struct {
 	ConnectionImpl
 
-	AccessToken *string `json:"access_token,omitempty" cty:"access_token" hcl:"access_token,optional"`
+	AccessToken *string `json:"access_token,omitempty" cty:"access_token" hcl:"access_token,optional" secret:"true"`
 	Server      *string `json:"server,omitempty" cty:"server" hcl:"server,optional"`
 }
 
@@ -28,6 +28,11 @@ func (c *MastodonConnection) GetConnectionType() string {
 }
 
 func (c *MastodonConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &MastodonConnection{ConnectionImpl: c.ConnectionImpl})
@@ -6,16 +6,24 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
 )
 
-const MicrosoftTeamsConnectionType = "teams"
+const (
+	MicrosoftTeamsConnectionType = "teams"
+	// the organization endpoint may be called with both delegated and application (client credentials) tokens
+	microsoftTeamsVerifyUrl = "https://graph.microsoft.com/v1.0/organization"
+)
 
 type MicrosoftTeamsConnection struct {
 	ConnectionImpl
 
-	AccessToken *string `json:"access_token,omitempty" cty:"access_token" hcl:"access_token,optional"`
+	AccessToken *string `json:"access_token,omitempty" cty:"access_token" hcl:"access_token,optional" secret:"true"`
+
+	// if set, the oauth2 credentials are exchanged for an access token when the connection is resolved
+	OAuth2 *OAuth2Config `json:"oauth2,omitempty" cty:"oauth2" hcl:"oauth2,block"`
 }
 
 func NewMicrosoftTeamsConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -28,11 +36,28 @@ func (c *MicrosoftTeamsConnection) GetConnectionType() string {
 }
 
 func (c *MicrosoftTeamsConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &MicrosoftTeamsConnection{ConnectionImpl: c.ConnectionImpl})
 	}
 
+	// if an oauth2 block is defined, exchange the oauth2 credentials for an access token
+	if c.OAuth2 != nil {
+		newConnection := *c
+		oauth2, err := c.OAuth2.Resolve(ctx, &newConnection.ConnectionImpl)
+		if err != nil {
+			return nil, err
+		}
+		newConnection.OAuth2 = oauth2
+		newConnection.AccessToken = newConnection.OAuth2.AccessToken
+		return &newConnection, nil
+	}
+
 	if c.AccessToken == nil {
 		msTeamsAccessTokenEnvVar := os.Getenv("TEAMS_ACCESS_TOKEN")
 
@@ -66,11 +91,15 @@ func (c *MicrosoftTeamsConnection) Equals(otherConnection PipelingConnection) bo
 		return false
 	}
 
+	if !c.OAuth2.Equals(other.OAuth2) {
+		return false
+	}
+
 	return c.GetConnectionImpl().Equals(otherConnection.GetConnectionImpl())
 }
 
 func (c *MicrosoftTeamsConnection) Validate() hcl.Diagnostics {
-	if c.Pipes != nil && (c.AccessToken != nil) {
+	if c.Pipes != nil && (c.AccessToken != nil || c.OAuth2 != nil) {
 		return hcl.Diagnostics{
 			{
 				Severity: hcl.DiagError,
@@ -79,6 +108,9 @@ func (c *MicrosoftTeamsConnection) Validate() hcl.Diagnostics {
 			},
 		}
 	}
+	if c.OAuth2 != nil {
+		return c.OAuth2.Validate(c.DeclRange.HclRangePointer())
+	}
 	return hcl.Diagnostics{}
 }
 
@@ -95,3 +127,19 @@ func (c *MicrosoftTeamsConnection) GetEnv() map[string]cty.Value {
 	}
 	return env
 }
+
+// Verify calls the Microsoft Graph API to get the organization of the token
+func (c *MicrosoftTeamsConnection) Verify(ctx context.Context) error {
+	req, err := newVerifyRequest(ctx, microsoftTeamsVerifyUrl)
+	if err != nil {
+		return err
+	}
+	if c.OAuth2 != nil {
+		if err := c.OAuth2.setAuthorization(req); err != nil {
+			return err
+		}
+	} else {
+		req.Header.Set("Authorization", "Bearer "+typehelpers.SafeString(c.AccessToken))
+	}
+	return verifyHttpRequest(ctx, req, nil)
+}
@@ -6,9 +6,13 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
 	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/pipe-fittings/backend"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 )
 
 const MysqlConnectionType = "mysql"
@@ -20,14 +24,22 @@ var (
 	defaultMysqlHost   = "localhost"
 )
 
+// the tls modes supported by the mysql driver
+var mysqlTlsModes = []string{"true", "false", "skip-verify", "preferred"}
+
 type MysqlConnection struct {
 	ConnectionImpl
-	DbName           *string `json:"db,omitempty" cty:"db" hcl:"db,optional"`
-	UserName         *string `json:"username,omitempty" cty:"username" hcl:"username,optional"`
-	Host             *string `json:"host,omitempty" cty:"host" hcl:"host,optional"`
-	Port             *int    `json:"port,omitempty" cty:"port" hcl:"port,optional"`
-	Password         *string `json:"password,omitempty" cty:"password" hcl:"password,optional"`
-	ConnectionString *string `json:"connection_string,omitempty" cty:"connection_string"`
+	DbName   *string `json:"db,omitempty" cty:"db" hcl:"db,optional"`
+	UserName *string `json:"username,omitempty" cty:"username" hcl:"username,optional"`
+	Host     *string `json:"host,omitempty" cty:"host" hcl:"host,optional"`
+	Port     *int    `json:"port,omitempty" cty:"port" hcl:"port,optional"`
+	Password *string `json:"password,omitempty" cty:"password" hcl:"password,optional" secret:"true"`
+	// the tls mode, one of true, false, skip-verify or preferred
+	Tls              *string `json:"tls,omitempty" cty:"tls" hcl:"tls,optional"`
+	SslCa            *string `json:"ssl_ca,omitempty" cty:"ssl_ca" hcl:"ssl_ca,optional"`
+	SslCert          *string `json:"ssl_cert,omitempty" cty:"ssl_cert" hcl:"ssl_cert,optional"`
+	SslKey           *string `json:"ssl_key,omitempty" cty:"ssl_key" hcl:"ssl_key,optional"`
+	ConnectionString *string `json:"connection_string,omitempty" cty:"connection_string" secret:"true"`
 }
 
 func NewMysqlConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -40,11 +52,21 @@ func (c *MysqlConnection) GetConnectionType() string {
 }
 
 func (c *MysqlConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &AwsConnection{ConnectionImpl: c.ConnectionImpl})
 	}
 
+	// the certificate files are checked when the connection is resolved, rather than when it is parsed
+	if err := checkSslFiles(c.SslCa, c.SslCert, c.SslKey); err != nil {
+		return nil, err
+	}
+
 	// we must have a connection string or validaiton would have failed
 	return c, nil
 }
@@ -52,7 +74,7 @@ func (c *MysqlConnection) Resolve(ctx context.Context) (PipelingConnection, erro
 func (c *MysqlConnection) Validate() hcl.Diagnostics {
 	// if pipes metadata is set, no other properties should be sets
 	if c.Pipes != nil {
-		if c.UserName != nil || c.Host != nil || c.Port != nil || c.Password != nil {
+		if c.UserName != nil || c.Host != nil || c.Port != nil || c.Password != nil || c.SslCa != nil || c.SslCert != nil || c.SslKey != nil {
 			return hcl.Diagnostics{
 				{
 					Severity: hcl.DiagError,
@@ -82,7 +104,23 @@ func (c *MysqlConnection) Validate() hcl.Diagnostics {
 		}
 	}
 
-	return nil
+	var diags hcl.Diagnostics
+	if c.Tls != nil && !slices.Contains(mysqlTlsModes, *c.Tls) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("tls must be one of %s", strings.Join(mysqlTlsModes, ", ")),
+			Subject:  c.DeclRange.HclRangePointer(),
+		})
+	}
+	if c.hasCustomTlsConfig() && typehelpers.SafeString(c.Tls) == "false" {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "ssl_ca, ssl_cert and ssl_key cannot be set if tls is false",
+			Subject:  c.DeclRange.HclRangePointer(),
+		})
+	}
+	diags = append(diags, validateSslClientCertificate(c.SslCert, c.SslKey, c.DeclRange.HclRangePointer())...)
+	return diags
 }
 
 func (c *MysqlConnection) GetConnectionString() string {
@@ -106,9 +144,34 @@ func (c *MysqlConnection) GetConnectionString() string {
 	}
 	connString := fmt.Sprintf("mysql://%s@tcp(%s:%d)/%s", userString, host, port, db)
 
+	// if certificates are set, tls is required - the certificate files are passed to the mysql backend as
+	// connection string parameters, from which it builds the tls config
+	var params []string
+	if c.Tls != nil {
+		params = append(params, "tls="+*c.Tls)
+	} else if c.hasCustomTlsConfig() {
+		params = append(params, "tls=true")
+	}
+	if c.SslCa != nil {
+		params = append(params, backend.MySQLSslCaParam+"="+url.QueryEscape(*c.SslCa))
+	}
+	if c.SslCert != nil {
+		params = append(params, backend.MySQLSslCertParam+"="+url.QueryEscape(*c.SslCert))
+	}
+	if c.SslKey != nil {
+		params = append(params, backend.MySQLSslKeyParam+"="+url.QueryEscape(*c.SslKey))
+	}
+	if len(params) > 0 {
+		connString += "?" + strings.Join(params, "&")
+	}
+
 	return connString
 }
 
+func (c *MysqlConnection) hasCustomTlsConfig() bool {
+	return c.SslCa != nil || c.SslCert != nil || c.SslKey != nil
+}
+
 func (c *MysqlConnection) GetEnv() map[string]cty.Value {
 	return map[string]cty.Value{
 		"MYSQL_TCP_PORT": cty.StringVal(strconv.Itoa(c.getPort())),
@@ -135,6 +198,10 @@ func (c *MysqlConnection) Equals(otherConnection PipelingConnection) bool {
 		utils.PtrEqual(c.Host, other.Host) &&
 		utils.PtrEqual(c.Port, other.Port) &&
 		utils.PtrEqual(c.Password, other.Password) &&
+		utils.PtrEqual(c.Tls, other.Tls) &&
+		utils.PtrEqual(c.SslCa, other.SslCa) &&
+		utils.PtrEqual(c.SslCert, other.SslCert) &&
+		utils.PtrEqual(c.SslKey, other.SslKey) &&
 		c.GetConnectionImpl().Equals(other.GetConnectionImpl())
 }
 
@@ -169,3 +236,8 @@ func (c *MysqlConnection) getUserName() string {
 	}
 	return defaultMysqlUser
 }
+
+// Verify connects to the database and runs a test query
+func (c *MysqlConnection) Verify(ctx context.Context) error {
+	return verifyDatabaseConnection(ctx, c.GetConnectionString())
+}
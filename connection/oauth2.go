@@ -0,0 +1,254 @@
+package connection
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/utils"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/sync/singleflight"
+)
+
+// OAuth2Config is an oauth2 block which may be embedded in a connection.
+//
+// When the connection is resolved, the credentials are exchanged for an access token using the refresh token grant
+// (if refresh_token is set) or the client credentials grant. Access tokens are cached until they expire, and the
+// ttl of the resolved connection is set so it is resolved again before the access token expires.
+//
+//	oauth2 {
+//	  token_url     = "https://example.com/oauth2/token"
+//	  client_id     = "..."
+//	  client_secret = "..."
+//	  scopes        = ["read"]
+//	}
+type OAuth2Config struct {
+	TokenUrl     *string   `json:"token_url,omitempty" cty:"token_url" hcl:"token_url"`
+	ClientId     *string   `json:"client_id,omitempty" cty:"client_id" hcl:"client_id"`
+	ClientSecret *string   `json:"client_secret,omitempty" cty:"client_secret" hcl:"client_secret,optional" secret:"true"`
+	Scopes       *[]string `json:"scopes,omitempty" cty:"scopes" hcl:"scopes,optional"`
+	RefreshToken *string   `json:"refresh_token,omitempty" cty:"refresh_token" hcl:"refresh_token,optional" secret:"true"`
+
+	// the access token is populated by Resolve
+	AccessToken *string `json:"access_token,omitempty" cty:"access_token" secret:"true"`
+}
+
+// the maximum duration of a token request
+var oauth2TokenRequestTimeout = 30 * time.Second
+
+// the cache of oauth2 tokens shared by all connections
+var oauth2Tokens = &oauth2TokenCache{tokens: make(map[string]*oauth2.Token)}
+
+// oauth2TokenCache caches tokens keyed by a hash of the credentials. Concurrent requests for a token for the
+// same credentials are de-duplicated, so result in a single token request
+type oauth2TokenCache struct {
+	// the lock guards the map only - it is not held while fetching a token
+	mu     sync.Mutex
+	tokens map[string]*oauth2.Token
+	group  singleflight.Group
+}
+
+// Resolve returns a copy of the config with the access token populated, using the cached access token if it has
+// not expired. The ttl of the connection is set to expire with the access token
+func (c *OAuth2Config) Resolve(ctx context.Context, connectionImpl *ConnectionImpl) (*OAuth2Config, error) {
+	// dereference any secret references, e.g. env://, file:// or vault://
+	resolved, err := c.resolveSecretRefs(ctx)
+	if err != nil {
+		return nil, secretRefError(err, fmt.Sprintf("failed to resolve oauth2 for connection %s", connectionImpl.Name()))
+	}
+
+	token, err := oauth2Tokens.token(ctx, resolved)
+	if err != nil {
+		return nil, err
+	}
+	resolved.AccessToken = &token.AccessToken
+
+	if !token.Expiry.IsZero() {
+		ttl := int(time.Until(token.Expiry).Seconds())
+		connectionImpl.SetTtl(max(ttl, 0))
+	}
+	return resolved, nil
+}
+
+func (c *OAuth2Config) Equals(other *OAuth2Config) bool {
+	if c == nil || other == nil {
+		return c == nil && other == nil
+	}
+	return utils.PtrEqual(c.TokenUrl, other.TokenUrl) &&
+		utils.PtrEqual(c.ClientId, other.ClientId) &&
+		utils.PtrEqual(c.ClientSecret, other.ClientSecret) &&
+		utils.SlicePtrEqual(c.Scopes, other.Scopes) &&
+		utils.PtrEqual(c.RefreshToken, other.RefreshToken)
+}
+
+func (c *OAuth2Config) Validate(declRange *hcl.Range) hcl.Diagnostics {
+	if typehelpers.SafeString(c.TokenUrl) == "" || typehelpers.SafeString(c.ClientId) == "" {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "oauth2 block requires token_url and client_id to be set",
+				Subject:  declRange,
+			},
+		}
+	}
+	return nil
+}
+
+// resolveSecretRefs returns a copy of the config with any secret references in the secret attributes
+// (client_secret and refresh_token) dereferenced
+func (c *OAuth2Config) resolveSecretRefs(ctx context.Context) (*OAuth2Config, error) {
+	resolved := *c
+	for _, field := range []**string{&resolved.ClientSecret, &resolved.RefreshToken} {
+		if *field == nil {
+			continue
+		}
+		value, err := ResolveSecretRef(ctx, **field)
+		if err != nil {
+			return nil, err
+		}
+		*field = &value
+	}
+	return &resolved, nil
+}
+
+// setAuthorization sets the bearer authorization header of the request to the access token - this fails if the
+// config has not been resolved
+func (c *OAuth2Config) setAuthorization(req *http.Request) error {
+	if typehelpers.SafeString(c.AccessToken) == "" {
+		return perr.BadRequestWithMessage("the oauth2 access token is not set - the connection must be resolved")
+	}
+	req.Header.Set("Authorization", "Bearer "+*c.AccessToken)
+	return nil
+}
+
+func (c *OAuth2Config) scopes() []string {
+	if c.Scopes == nil {
+		return nil
+	}
+	return *c.Scopes
+}
+
+// cacheKey returns a hash of the credentials, so tokens are not shared between different credentials
+func (c *OAuth2Config) cacheKey() string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{
+		typehelpers.SafeString(c.TokenUrl),
+		typehelpers.SafeString(c.ClientId),
+		typehelpers.SafeString(c.ClientSecret),
+		typehelpers.SafeString(c.RefreshToken),
+		strings.Join(c.scopes(), " "),
+	}, "\x00")))
+	return hex.EncodeToString(hash[:])
+}
+
+// token returns the cached token for the config if it is still valid, otherwise it fetches a new token
+func (t *oauth2TokenCache) token(ctx context.Context, c *OAuth2Config) (*oauth2.Token, error) {
+	key := c.cacheKey()
+	if cached := t.get(key); cached.Valid() {
+		return cached, nil
+	}
+
+	// NOTE: the token request is shared by all concurrent callers, so should not be cancelled if the context of
+	// the first caller is cancelled - each caller instead stops waiting when its own context is done
+	resultChan := t.group.DoChan(key, func() (any, error) {
+		// another caller may have populated the cache since we checked
+		cached := t.get(key)
+		if cached.Valid() {
+			return cached, nil
+		}
+		// the request is not cancelled with the caller, so is bounded by a timeout instead
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), oauth2TokenRequestTimeout)
+		defer cancel()
+		token, err := c.fetchToken(fetchCtx, cached)
+		if err != nil {
+			return nil, err
+		}
+		t.set(key, token)
+		return token, nil
+	})
+
+	select {
+	case res := <-resultChan:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*oauth2.Token), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (t *oauth2TokenCache) get(key string) *oauth2.Token {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tokens[key]
+}
+
+// set caches the token, and evicts the expired tokens which cannot be refreshed. Expired tokens with a refresh
+// token are kept, as the token endpoint may have rotated the refresh token of the config
+func (t *oauth2TokenCache) set(key string, token *oauth2.Token) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for k, cached := range t.tokens {
+		if !cached.Valid() && cached.RefreshToken == "" {
+			delete(t.tokens, k)
+		}
+	}
+	t.tokens[key] = token
+}
+
+// fetchToken requests a new token from the token endpoint - if the previous token for the config has a refresh
+// token, this is used rather than the refresh token of the config
+func (c *OAuth2Config) fetchToken(ctx context.Context, previous *oauth2.Token) (*oauth2.Token, error) {
+	var source oauth2.TokenSource
+	if c.RefreshToken != nil {
+		config := &oauth2.Config{
+			ClientID:     typehelpers.SafeString(c.ClientId),
+			ClientSecret: typehelpers.SafeString(c.ClientSecret),
+			Endpoint:     oauth2.Endpoint{TokenURL: typehelpers.SafeString(c.TokenUrl)},
+			Scopes:       c.scopes(),
+		}
+		// the token endpoint may rotate the refresh token, so refresh using the latest refresh token if there is one
+		refreshToken := &oauth2.Token{RefreshToken: *c.RefreshToken}
+		if previous != nil && previous.RefreshToken != "" {
+			refreshToken = &oauth2.Token{RefreshToken: previous.RefreshToken}
+		}
+		source = config.TokenSource(ctx, refreshToken)
+	} else {
+		config := &clientcredentials.Config{
+			ClientID:     typehelpers.SafeString(c.ClientId),
+			ClientSecret: typehelpers.SafeString(c.ClientSecret),
+			TokenURL:     typehelpers.SafeString(c.TokenUrl),
+			Scopes:       c.scopes(),
+		}
+		source = config.TokenSource(ctx)
+	}
+
+	token, err := source.Token()
+	if err != nil {
+		return nil, oauth2Error(err)
+	}
+	return token, nil
+}
+
+// invalidate removes all cached tokens
+func (t *oauth2TokenCache) invalidate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clear(t.tokens)
+}
+
+func oauth2Error(err error) error {
+	if retrieveErr, ok := err.(*oauth2.RetrieveError); ok && retrieveErr.Response != nil {
+		return perr.FromHttpError(fmt.Errorf("oauth2 token request failed: %s", retrieveErr.Error()), retrieveErr.Response.StatusCode)
+	}
+	return perr.InternalWithMessage(fmt.Sprintf("oauth2 token request failed: %s", err.Error()))
+}
@@ -0,0 +1,319 @@
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/oauth2"
+)
+
+// mockTokenEndpoint is a stub oauth2 token endpoint supporting the client credentials and refresh token grants.
+// Each token request returns a new access token and rotates the refresh token
+type mockTokenEndpoint struct {
+	mu        sync.Mutex
+	requests  []map[string]string
+	expiresIn int
+}
+
+func (m *mockTokenEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clientId, clientSecret, _ := r.BasicAuth()
+	if err := r.ParseForm(); err != nil || clientId != "client" || clientSecret != "secret" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+		return
+	}
+	m.requests = append(m.requests, map[string]string{
+		"grant_type":    r.PostForm.Get("grant_type"),
+		"refresh_token": r.PostForm.Get("refresh_token"),
+		"scope":         r.PostForm.Get("scope"),
+	})
+
+	n := len(m.requests)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"access_token":  fmt.Sprintf("access_token_%d", n),
+		"refresh_token": fmt.Sprintf("refresh_token_%d", n),
+		"token_type":    "Bearer",
+		"expires_in":    m.expiresIn,
+	})
+}
+
+func newMockTokenEndpoint(t *testing.T, expiresIn int) (*mockTokenEndpoint, string) {
+	t.Helper()
+	oauth2Tokens.invalidate()
+	t.Cleanup(oauth2Tokens.invalidate)
+
+	endpoint := &mockTokenEndpoint{expiresIn: expiresIn}
+	server := httptest.NewServer(endpoint)
+	t.Cleanup(server.Close)
+	return endpoint, server.URL + "/token"
+}
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	endpoint, tokenUrl := newMockTokenEndpoint(t, 3600)
+	config := &OAuth2Config{
+		TokenUrl:     &tokenUrl,
+		ClientId:     utils.ToStringPointer("client"),
+		ClientSecret: utils.ToStringPointer("secret"),
+		Scopes:       &[]string{"read", "write"},
+	}
+
+	impl := NewConnectionImpl(MicrosoftTeamsConnectionType, "default", hcl.Range{})
+	resolved, err := config.Resolve(context.Background(), &impl)
+	require.NoError(t, err)
+	assert.Equal(t, "access_token_1", *resolved.AccessToken)
+	assert.InDelta(t, 3600, impl.GetTtl(), 5)
+	// the original config is not modified
+	assert.Nil(t, config.AccessToken)
+
+	// the cached token is used until it expires
+	resolved, err = config.Resolve(context.Background(), &impl)
+	require.NoError(t, err)
+	assert.Equal(t, "access_token_1", *resolved.AccessToken)
+
+	require.Len(t, endpoint.requests, 1)
+	assert.Equal(t, "client_credentials", endpoint.requests[0]["grant_type"])
+	assert.Equal(t, "read write", endpoint.requests[0]["scope"])
+}
+
+func TestOAuth2ConcurrentResolve(t *testing.T) {
+	endpoint, tokenUrl := newMockTokenEndpoint(t, 3600)
+	config := &OAuth2Config{
+		TokenUrl:     &tokenUrl,
+		ClientId:     utils.ToStringPointer("client"),
+		ClientSecret: utils.ToStringPointer("secret"),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			impl := NewConnectionImpl(MicrosoftTeamsConnectionType, "default", hcl.Range{})
+			resolved, err := config.Resolve(context.Background(), &impl)
+			assert.NoError(t, err)
+			assert.Equal(t, "access_token_1", *resolved.AccessToken)
+		}()
+	}
+	wg.Wait()
+
+	// concurrent resolves of the same credentials result in a single token request
+	assert.Len(t, endpoint.requests, 1)
+}
+
+func TestOAuth2TokenCacheEviction(t *testing.T) {
+	cache := &oauth2TokenCache{tokens: make(map[string]*oauth2.Token)}
+	expired := time.Now().Add(-time.Hour)
+	cache.tokens["expired"] = &oauth2.Token{AccessToken: "a", Expiry: expired}
+	cache.tokens["refreshable"] = &oauth2.Token{AccessToken: "b", RefreshToken: "r", Expiry: expired}
+
+	cache.set("new", &oauth2.Token{AccessToken: "c", Expiry: time.Now().Add(time.Hour)})
+
+	// expired tokens are evicted, unless they have a refresh token which may have been rotated
+	assert.Nil(t, cache.get("expired"))
+	assert.NotNil(t, cache.get("refreshable"))
+	assert.Equal(t, "c", cache.get("new").AccessToken)
+}
+
+func TestOAuth2RefreshToken(t *testing.T) {
+	// tokens expire within the expiry delta of the oauth2 library, so every resolve refreshes the token
+	endpoint, tokenUrl := newMockTokenEndpoint(t, 1)
+	config := &OAuth2Config{
+		TokenUrl:     &tokenUrl,
+		ClientId:     utils.ToStringPointer("client"),
+		ClientSecret: utils.ToStringPointer("secret"),
+		RefreshToken: utils.ToStringPointer("initial_refresh_token"),
+	}
+
+	impl := NewConnectionImpl(MicrosoftTeamsConnectionType, "default", hcl.Range{})
+	for i := 1; i <= 2; i++ {
+		resolved, err := config.Resolve(context.Background(), &impl)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("access_token_%d", i), *resolved.AccessToken)
+	}
+
+	// the rotated refresh token is used for the second refresh
+	require.Len(t, endpoint.requests, 2)
+	assert.Equal(t, "refresh_token", endpoint.requests[0]["grant_type"])
+	assert.Equal(t, "initial_refresh_token", endpoint.requests[0]["refresh_token"])
+	assert.Equal(t, "refresh_token_1", endpoint.requests[1]["refresh_token"])
+}
+
+func TestOAuth2InvalidClient(t *testing.T) {
+	_, tokenUrl := newMockTokenEndpoint(t, 3600)
+	config := &OAuth2Config{
+		TokenUrl:     &tokenUrl,
+		ClientId:     utils.ToStringPointer("client"),
+		ClientSecret: utils.ToStringPointer("wrong_secret"),
+	}
+
+	impl := NewConnectionImpl(MicrosoftTeamsConnectionType, "default", hcl.Range{})
+	_, err := config.Resolve(context.Background(), &impl)
+	require.Error(t, err)
+	assert.Equal(t, perr.ErrorCodeUnauthorized, err.(perr.ErrorModel).Type)
+}
+
+func TestMicrosoftTeamsConnectionOAuth2(t *testing.T) {
+	_, tokenUrl := newMockTokenEndpoint(t, 3600)
+	t.Setenv("TEAMS_CLIENT_SECRET", "secret")
+
+	conn := NewMicrosoftTeamsConnection("default", hcl.Range{}).(*MicrosoftTeamsConnection)
+	conn.OAuth2 = &OAuth2Config{
+		TokenUrl:     &tokenUrl,
+		ClientId:     utils.ToStringPointer("client"),
+		ClientSecret: utils.ToStringPointer("env://TEAMS_CLIENT_SECRET"),
+	}
+	assert.Empty(t, conn.Validate())
+
+	resolved, err := conn.Resolve(context.Background())
+	require.NoError(t, err)
+	resolvedConnection := resolved.(*MicrosoftTeamsConnection)
+	assert.Equal(t, "access_token_1", *resolvedConnection.AccessToken)
+	assert.Equal(t, "secret", *resolvedConnection.OAuth2.ClientSecret)
+	assert.InDelta(t, 3600, resolvedConnection.GetTtl(), 5)
+	// the original connection is not modified
+	assert.Nil(t, conn.AccessToken)
+	assert.Equal(t, -1, conn.GetTtl())
+
+	// token_url and client_id are required
+	conn.OAuth2 = &OAuth2Config{TokenUrl: &tokenUrl}
+	assert.Len(t, conn.Validate(), 1)
+}
+
+func TestOAuth2TokenRequestTimeout(t *testing.T) {
+	oauth2Tokens.invalidate()
+	t.Cleanup(oauth2Tokens.invalidate)
+
+	// the token endpoint never responds
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	timeout := oauth2TokenRequestTimeout
+	oauth2TokenRequestTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { oauth2TokenRequestTimeout = timeout })
+
+	config := &OAuth2Config{
+		TokenUrl:     utils.ToStringPointer(server.URL + "/token"),
+		ClientId:     utils.ToStringPointer("client"),
+		ClientSecret: utils.ToStringPointer("secret"),
+	}
+	impl := NewConnectionImpl(MicrosoftTeamsConnectionType, "default", hcl.Range{})
+	_, err := config.Resolve(context.Background(), &impl)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "context deadline exceeded")
+}
+
+func TestOAuth2SecretRefs(t *testing.T) {
+	_, tokenUrl := newMockTokenEndpoint(t, 3600)
+	t.Setenv("OAUTH2_CLIENT_SECRET", "secret")
+
+	// only the secret attributes are dereferenced
+	config := &OAuth2Config{
+		TokenUrl:     &tokenUrl,
+		ClientId:     utils.ToStringPointer("env://OAUTH2_CLIENT_ID"),
+		ClientSecret: utils.ToStringPointer("env://OAUTH2_CLIENT_SECRET"),
+	}
+	resolved, err := config.resolveSecretRefs(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "env://OAUTH2_CLIENT_ID", *resolved.ClientId)
+	assert.Equal(t, "secret", *resolved.ClientSecret)
+}
+
+func TestOktaConnectionOAuth2(t *testing.T) {
+	_, tokenUrl := newMockTokenEndpoint(t, 3600)
+
+	conn := NewOktaConnection("default", hcl.Range{}).(*OktaConnection)
+	conn.Domain = utils.ToStringPointer("https://example.okta.com")
+	conn.OAuth2 = newTestOAuth2Config(tokenUrl)
+
+	resolved, err := conn.Resolve(context.Background())
+	require.NoError(t, err)
+	resolvedConnection := resolved.(*OktaConnection)
+	// the access token is used as a bearer token
+	assert.Equal(t, "access_token_1", *resolvedConnection.Token)
+	env := resolvedConnection.GetEnv()
+	assert.Equal(t, cty.StringVal("access_token_1"), env["OKTA_CLIENT_TOKEN"])
+	assert.Equal(t, cty.StringVal("Bearer"), env["OKTA_CLIENT_AUTHORIZATIONMODE"])
+	assertCtyAccessToken(t, resolvedConnection, "token")
+}
+
+func TestZendeskConnectionOAuth2(t *testing.T) {
+	_, tokenUrl := newMockTokenEndpoint(t, 3600)
+
+	conn := NewZendeskConnection("default", hcl.Range{}).(*ZendeskConnection)
+	conn.Subdomain = utils.ToStringPointer("example")
+	conn.OAuth2 = newTestOAuth2Config(tokenUrl)
+
+	resolved, err := conn.Resolve(context.Background())
+	require.NoError(t, err)
+	resolvedConnection := resolved.(*ZendeskConnection)
+	assert.Equal(t, "access_token_1", *resolvedConnection.AccessToken)
+	assert.Equal(t, cty.StringVal("access_token_1"), resolvedConnection.GetEnv()["ZENDESK_ACCESS_TOKEN"])
+	assertCtyAccessToken(t, resolvedConnection, "access_token")
+}
+
+func TestJiraConnectionOAuth2(t *testing.T) {
+	_, tokenUrl := newMockTokenEndpoint(t, 3600)
+
+	conn := NewJiraConnection("default", hcl.Range{}).(*JiraConnection)
+	conn.BaseURL = utils.ToStringPointer("https://example.atlassian.net")
+	conn.OAuth2 = newTestOAuth2Config(tokenUrl)
+
+	resolved, err := conn.Resolve(context.Background())
+	require.NoError(t, err)
+	resolvedConnection := resolved.(*JiraConnection)
+	assert.Equal(t, "access_token_1", *resolvedConnection.AccessToken)
+	assert.Equal(t, cty.StringVal("access_token_1"), resolvedConnection.GetEnv()["JIRA_PERSONAL_ACCESS_TOKEN"])
+	assertCtyAccessToken(t, resolvedConnection, "access_token")
+}
+
+func TestServiceNowConnectionOAuth2(t *testing.T) {
+	_, tokenUrl := newMockTokenEndpoint(t, 3600)
+
+	conn := NewServiceNowConnection("default", hcl.Range{}).(*ServiceNowConnection)
+	conn.InstanceURL = utils.ToStringPointer("https://example.service-now.com")
+	conn.OAuth2 = newTestOAuth2Config(tokenUrl)
+
+	resolved, err := conn.Resolve(context.Background())
+	require.NoError(t, err)
+	resolvedConnection := resolved.(*ServiceNowConnection)
+	assert.Equal(t, "access_token_1", *resolvedConnection.AccessToken)
+	assert.Equal(t, cty.StringVal("access_token_1"), resolvedConnection.GetEnv()["SERVICENOW_ACCESS_TOKEN"])
+	assertCtyAccessToken(t, resolvedConnection, "access_token")
+}
+
+func newTestOAuth2Config(tokenUrl string) *OAuth2Config {
+	return &OAuth2Config{
+		TokenUrl:     &tokenUrl,
+		ClientId:     utils.ToStringPointer("client"),
+		ClientSecret: utils.ToStringPointer("secret"),
+	}
+}
+
+// assertCtyAccessToken asserts the access token is set as the attribute of the cty value of the connection
+func assertCtyAccessToken(t *testing.T, conn PipelingConnection, attribute string) {
+	t.Helper()
+	ctyValue, err := conn.CtyValue()
+	require.NoError(t, err)
+	assert.Equal(t, cty.StringVal("access_token_1"), ctyValue.GetAttr(attribute))
+}
@@ -3,9 +3,12 @@ package connection
 import (
 	"context"
 	"os"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/pipe-fittings/perr"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -16,7 +19,10 @@ type OktaConnection struct {
 	ConnectionImpl
 
 	Domain *string `json:"domain,omitempty" cty:"domain" hcl:"domain,optional"`
-	Token  *string `json:"token,omitempty" cty:"token" hcl:"token,optional"`
+	Token  *string `json:"token,omitempty" cty:"token" hcl:"token,optional" secret:"true"`
+
+	// if set, the oauth2 credentials are exchanged for an access token when the connection is resolved
+	OAuth2 *OAuth2Config `json:"oauth2,omitempty" cty:"oauth2" hcl:"oauth2,block"`
 }
 
 func NewOktaConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -29,11 +35,29 @@ func (c *OktaConnection) GetConnectionType() string {
 }
 
 func (c *OktaConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &OktaConnection{ConnectionImpl: c.ConnectionImpl})
 	}
 
+	// if an oauth2 block is defined, exchange the oauth2 credentials for an access token
+	if c.OAuth2 != nil {
+		newConnection := *c
+		oauth2, err := c.OAuth2.Resolve(ctx, &newConnection.ConnectionImpl)
+		if err != nil {
+			return nil, err
+		}
+		newConnection.OAuth2 = oauth2
+		// the access token is used as a bearer token
+		newConnection.Token = oauth2.AccessToken
+		return &newConnection, nil
+	}
+
 	if c.Token == nil && c.Domain == nil {
 		apiTokenEnvVar := os.Getenv("OKTA_CLIENT_TOKEN")
 		domainEnvVar := os.Getenv("OKTA_ORGURL")
@@ -74,11 +98,15 @@ func (c *OktaConnection) Equals(otherConnection PipelingConnection) bool {
 		return false
 	}
 
+	if !c.OAuth2.Equals(other.OAuth2) {
+		return false
+	}
+
 	return c.GetConnectionImpl().Equals(otherConnection.GetConnectionImpl())
 }
 
 func (c *OktaConnection) Validate() hcl.Diagnostics {
-	if c.Pipes != nil && (c.Token != nil || c.Domain != nil) {
+	if c.Pipes != nil && (c.Token != nil || c.Domain != nil || c.OAuth2 != nil) {
 		return hcl.Diagnostics{
 			{
 				Severity: hcl.DiagError,
@@ -88,6 +116,9 @@ func (c *OktaConnection) Validate() hcl.Diagnostics {
 		}
 	}
 
+	if c.OAuth2 != nil {
+		return c.OAuth2.Validate(c.DeclRange.HclRangePointer())
+	}
 	return hcl.Diagnostics{}
 }
 
@@ -105,5 +136,27 @@ func (c *OktaConnection) GetEnv() map[string]cty.Value {
 	if c.Domain != nil {
 		env["OKTA_ORGURL"] = cty.StringVal(*c.Domain)
 	}
+	if c.OAuth2 != nil {
+		env["OKTA_CLIENT_AUTHORIZATIONMODE"] = cty.StringVal("Bearer")
+	}
 	return env
 }
+
+// Verify calls the Okta API to get the authenticated user
+func (c *OktaConnection) Verify(ctx context.Context) error {
+	if typehelpers.SafeString(c.Domain) == "" {
+		return perr.BadRequestWithMessage("domain is required to verify an okta connection")
+	}
+	req, err := newVerifyRequest(ctx, strings.TrimSuffix(*c.Domain, "/")+"/api/v1/users/me")
+	if err != nil {
+		return err
+	}
+	if c.OAuth2 != nil {
+		if err := c.OAuth2.setAuthorization(req); err != nil {
+			return err
+		}
+	} else {
+		req.Header.Set("Authorization", "SSWS "+typehelpers.SafeString(c.Token))
+	}
+	return verifyHttpRequest(ctx, req, nil)
+}
@@ -6,16 +6,20 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
 )
 
-const OpenAIConnectionType = "openai"
+const (
+	OpenAIConnectionType = "openai"
+	openAIVerifyUrl      = "https://api.openai.com/v1/models"
+)
 
 type OpenAIConnection struct {
 	ConnectionImpl
 
-	APIKey *string `json:"api_key,omitempty" cty:"api_key" hcl:"api_key,optional"`
+	APIKey *string `json:"api_key,omitempty" cty:"api_key" hcl:"api_key,optional" secret:"true"`
 }
 
 func NewOpenAIConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -28,6 +32,11 @@ func (c *OpenAIConnection) GetConnectionType() string {
 }
 
 func (c *OpenAIConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &OpenAIConnection{ConnectionImpl: c.ConnectionImpl})
@@ -95,3 +104,13 @@ func (c *OpenAIConnection) GetEnv() map[string]cty.Value {
 	}
 	return env
 }
+
+// Verify calls the OpenAI API to list the available models
+func (c *OpenAIConnection) Verify(ctx context.Context) error {
+	req, err := newVerifyRequest(ctx, openAIVerifyUrl)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+typehelpers.SafeString(c.APIKey))
+	return verifyHttpRequest(ctx, req, nil)
+}
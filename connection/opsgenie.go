@@ -15,8 +15,8 @@ const OpsgenieConnectionType = "opsgenie"
 type OpsgenieConnection struct {
 	ConnectionImpl
 
-	AlertAPIKey    *string `json:"alert_api_key,omitempty" cty:"alert_api_key" hcl:"alert_api_key,optional"`
-	IncidentAPIKey *string `json:"incident_api_key,omitempty" cty:"incident_api_key" hcl:"incident_api_key,optional"`
+	AlertAPIKey    *string `json:"alert_api_key,omitempty" cty:"alert_api_key" hcl:"alert_api_key,optional" secret:"true"`
+	IncidentAPIKey *string `json:"incident_api_key,omitempty" cty:"incident_api_key" hcl:"incident_api_key,optional" secret:"true"`
 }
 
 func NewOpsgenieConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -29,6 +29,11 @@ func (c *OpsgenieConnection) GetConnectionType() string {
 }
 
 func (c *OpsgenieConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &OpsgenieConnection{ConnectionImpl: c.ConnectionImpl})
@@ -15,7 +15,7 @@ const PagerDutyConnectionType = "pagerduty"
 type PagerDutyConnection struct {
 	ConnectionImpl
 
-	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional"`
+	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional" secret:"true"`
 }
 
 func NewPagerDutyConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -28,6 +28,11 @@ func (c *PagerDutyConnection) GetConnectionType() string {
 }
 
 func (c *PagerDutyConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &PagerDutyConnection{ConnectionImpl: c.ConnectionImpl})
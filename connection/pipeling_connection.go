@@ -38,6 +38,17 @@ type SearchPathProvider interface {
 	GetSearchPathPrefix() []string
 }
 
+// BodyDecoder is implemented by connections which decode their own hcl body, rather than being decoded using gohcl
+type BodyDecoder interface {
+	DecodeBody(body hcl.Body, evalCtx *hcl.EvalContext) hcl.Diagnostics
+}
+
+// CtyValueDecoder is implemented by connections which decode their own attributes from a cty value,
+// rather than being decoded using gocty
+type CtyValueDecoder interface {
+	FromCtyValue(value cty.Value) error
+}
+
 func ConnectionTypeMeetsRequiredType(requiredType, actualResourceType, actualType string) bool {
 	// handle type connection and connection.<subtype>
 	requiredTypeParts := strings.Split(requiredType, ".")
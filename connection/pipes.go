@@ -15,7 +15,7 @@ const PipesConnectionType = "pipes"
 type PipesConnection struct {
 	ConnectionImpl
 
-	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional"`
+	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional" secret:"true"`
 }
 
 func NewPipesConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -28,6 +28,11 @@ func (c *PipesConnection) GetConnectionType() string {
 }
 
 func (c *PipesConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &PipesConnection{ConnectionImpl: c.ConnectionImpl})
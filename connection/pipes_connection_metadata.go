@@ -1,19 +1,24 @@
 package connection
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/turbot/pipe-fittings/app_specific"
-	"github.com/turbot/pipe-fittings/perr"
-
 	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/contexthelpers"
+	"github.com/turbot/pipe-fittings/perr"
 )
 
 const (
@@ -32,6 +37,83 @@ var allowedCloudHosts = []string{
 	mockCloudHost,
 }
 
+var (
+	// custom cloud hosts, e.g. self-hosted pipes compatible endpoints, added using AllowPipesCloudHost
+	customCloudHosts     []string
+	customCloudHostsLock sync.RWMutex
+)
+
+// AllowPipesCloudHost allows the given host (e.g. a self-hosted pipes compatible endpoint) to be used as the
+// cloud_host of a pipes block. Unlike the built-in pipes hosts, the cloud_host must match the host exactly
+func AllowPipesCloudHost(host string) {
+	customCloudHostsLock.Lock()
+	defer customCloudHostsLock.Unlock()
+	if !slices.Contains(customCloudHosts, host) {
+		customCloudHosts = append(customCloudHosts, host)
+	}
+}
+
+func isCustomCloudHost(host string) bool {
+	customCloudHostsLock.RLock()
+	defer customCloudHostsLock.RUnlock()
+	return slices.Contains(customCloudHosts, host)
+}
+
+var contextKeyPipesClientConfig = contexthelpers.ContextKey("pipes_client_config")
+
+// PipesClientConfig configures the client used to call the pipes credential api.
+// Fields which are not set are defaulted from DefaultPipesClientConfig
+type PipesClientConfig struct {
+	// the timeout of each request
+	Timeout time.Duration
+	// the maximum number of retries of a request which fails with a retryable error,
+	// i.e. a network error or a 408, 429 or 5xx status code - a negative value disables retries
+	MaxRetries int
+	// the initial backoff between retries, which doubles after each retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	HttpClient HttpClient
+}
+
+func DefaultPipesClientConfig() PipesClientConfig {
+	return PipesClientConfig{
+		Timeout:    10 * time.Second,
+		MaxRetries: 3,
+		Backoff:    500 * time.Millisecond,
+		MaxBackoff: 5 * time.Second,
+		HttpClient: &http.Client{},
+	}
+}
+
+// AddPipesClientConfigToContext sets the config of the client used to resolve connections using pipes
+func AddPipesClientConfigToContext(ctx context.Context, config PipesClientConfig) context.Context {
+	return context.WithValue(ctx, contextKeyPipesClientConfig, config)
+}
+
+func pipesClientConfigFromContext(ctx context.Context) PipesClientConfig {
+	defaultConfig := DefaultPipesClientConfig()
+	config, ok := ctx.Value(contextKeyPipesClientConfig).(PipesClientConfig)
+	if !ok {
+		return defaultConfig
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultConfig.Timeout
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultConfig.MaxRetries
+	}
+	if config.Backoff == 0 {
+		config.Backoff = defaultConfig.Backoff
+	}
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = defaultConfig.MaxBackoff
+	}
+	if config.HttpClient == nil {
+		config.HttpClient = defaultConfig.HttpClient
+	}
+	return config
+}
+
 type PipesConnectionMetadata struct {
 	CloudHost  *string `json:"cloud_host,omitempty" cty:"cloud_host" hcl:"cloud_host,optional"`
 	User       *string `json:"user,omitempty" cty:"user" hcl:"user,optional"`
@@ -51,14 +133,14 @@ func (m PipesConnectionMetadata) Resolve(ctx context.Context, target PipelingCon
 	if err := m.validate(); err != nil {
 		return nil, err
 	}
-	err := m.callPipesCredApi(target)
+	err := m.callPipesCredApi(ctx, target)
 	if err != nil {
 		return nil, err
 	}
 	return target, nil
 }
 
-func (m PipesConnectionMetadata) callPipesCredApi(target PipelingConnection) error {
+func (m PipesConnectionMetadata) callPipesCredApi(ctx context.Context, target PipelingConnection) error {
 	// get token from env
 	// NOTE: use app specific pipes token env, e.g. FLOWPIPE_PIPES_TOKEN
 	token, ok := os.LookupEnv(app_specific.EnvPipesToken)
@@ -66,18 +148,41 @@ func (m PipesConnectionMetadata) callPipesCredApi(target PipelingConnection) err
 		return fmt.Errorf("missing environment variable %s", app_specific.EnvPipesToken)
 	}
 
-	// API endpoint
-	url := m.endpoint()
+	config := pipesClientConfigFromContext(ctx)
+	backoff := config.Backoff
+	for attempt := 0; ; attempt++ {
+		body, retryAfter, err := m.doPipesCredApiRequest(ctx, config, token)
+		if err == nil {
+			// Parse the JSON response
+			return m.handlePipesCredApiResponse(io.NopCloser(bytes.NewReader(body)), target)
+		}
+		if attempt >= config.MaxRetries || !isRetryablePipesError(ctx, err) {
+			return err
+		}
 
-	// Create a new HTTP client with a timeout
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+		// if the server specified how long to wait, wait at least that long, even if this exceeds MaxBackoff
+		wait := max(min(backoff, config.MaxBackoff), retryAfter)
+		slog.Debug("pipes credential api request failed - retrying", "attempt", attempt+1, "backoff", wait, "error", err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return err
+		}
+		backoff = min(backoff*2, config.MaxBackoff)
 	}
+}
+
+// doPipesCredApiRequest calls the pipes credential api, returning the response body.
+// Errors are classified by status code using perr.FromHttpError, and network errors are returned as service
+// unavailable errors. If the response specifies a Retry-After delay, it is also returned
+func (m PipesConnectionMetadata) doPipesCredApiRequest(ctx context.Context, config PipesClientConfig, token string) ([]byte, time.Duration, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
 
 	// Create a new HTTP request
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, m.endpoint(), nil)
 	if err != nil {
-		return perr.InternalWithMessage("failed to create request")
+		return nil, 0, perr.InternalWithMessage("failed to create request")
 	}
 
 	// Set the Authorization header with the Bearer token
@@ -85,19 +190,42 @@ func (m PipesConnectionMetadata) callPipesCredApi(target PipelingConnection) err
 	req.Header.Set("Content-Type", "application/json")
 
 	// Send the request
-	resp, err := client.Do(req)
+	resp, err := config.HttpClient.Do(req)
 	if err != nil {
-		return perr.InternalWithMessage("failed to execute request")
+		if ctx.Err() != nil {
+			return nil, 0, perr.TimeoutWithMessage(fmt.Sprintf("pipes credential api request cancelled: %s", ctx.Err().Error()))
+		}
+		return nil, 0, perr.ServiceUnavailableWithMessage(fmt.Sprintf("failed to execute request: %s", err.Error()))
 	}
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, perr.ServiceUnavailableWithMessage(fmt.Sprintf("failed to read response body: %s", err.Error()))
+	}
+
 	// Check if the status code is OK (200)
 	if resp.StatusCode != http.StatusOK {
-		return perr.InternalWithMessage(fmt.Sprintf("unexpected status code: %d", resp.StatusCode))
+		return nil, retryAfterDuration(resp), perr.FromHttpError(fmt.Errorf("unexpected status code: %d", resp.StatusCode), resp.StatusCode)
+	}
+	return body, 0, nil
+}
+
+// isRetryablePipesError returns whether the error is transient, so the request should be retried
+func isRetryablePipesError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
 	}
+	return perr.IsTooManyRequests(err) || perr.IsServiceUnavailable(err) || perr.IsTimeout(err) || perr.IsInternal(err)
+}
 
-	// Parse the JSON response
-	return m.handlePipesCredApiResponse(resp.Body, target)
+// retryAfterDuration returns the delay specified in seconds by the Retry-After header, if any
+func retryAfterDuration(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
 }
 
 func (m PipesConnectionMetadata) handlePipesCredApiResponse(resp io.ReadCloser, target PipelingConnection) error {
@@ -113,8 +241,9 @@ func (m PipesConnectionMetadata) handlePipesCredApiResponse(resp io.ReadCloser,
 	// always set a ttl, even if pipes does not provide one
 	ttl := constants.DefaultConnectionTtl
 	// if api response contains a use before, set the ttl from it
+	// (if use_before has already passed, the ttl is zero so the connection is not cached)
 	if !apiResponse.UseBefore.IsZero() {
-		ttl = int(time.Until(apiResponse.UseBefore).Seconds())
+		ttl = max(int(time.Until(apiResponse.UseBefore).Seconds()), 0)
 	}
 
 	target.SetTtl(ttl)
@@ -160,8 +289,8 @@ func (m PipesConnectionMetadata) validate() error {
 		return perr.BadRequestWithMessage("only one of user or org is allowed")
 	}
 
-	// cloudhost, if provided, must END in pipes.turbot.com
-	if m.CloudHost != nil {
+	// cloudhost, if provided, must END in one of the allowed hosts, or be an allowed custom host
+	if m.CloudHost != nil && !isCustomCloudHost(*m.CloudHost) {
 		valid := false
 		for _, host := range allowedCloudHosts {
 			if strings.HasSuffix(*m.CloudHost, host) {
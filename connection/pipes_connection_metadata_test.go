@@ -1,14 +1,22 @@
 package connection
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/app_specific"
 	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/perr"
 	"github.com/turbot/pipe-fittings/utils"
 	"io"
 	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -188,7 +196,28 @@ func TestPipesConnectionMetadata_validate(t *testing.T) {
 			},
 			wantErr: assert.Error,
 		},
+		{
+			name: "Valid - custom cloud host",
+			fields: fields{
+				User:       utils.ToStringPointer("user"),
+				Workspace:  utils.ToStringPointer("workspace"),
+				Connection: utils.ToStringPointer("connection"),
+				CloudHost:  utils.ToStringPointer("pipes.example.com"),
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "Invalid - subdomain of custom cloud host",
+			fields: fields{
+				User:       utils.ToStringPointer("user"),
+				Workspace:  utils.ToStringPointer("workspace"),
+				Connection: utils.ToStringPointer("connection"),
+				CloudHost:  utils.ToStringPointer("foo.pipes.example.com"),
+			},
+			wantErr: assert.Error,
+		},
 	}
+	AllowPipesCloudHost("pipes.example.com")
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := PipesConnectionMetadata{
@@ -269,3 +298,124 @@ func TestPipesConnectionMetadata_endpoint(t *testing.T) {
 		})
 	}
 }
+
+// newPipesStub starts a stub pipes credential api which returns the given status codes in turn, followed by a
+// successful response. It returns a context configured to send pipes requests to the stub, and the request count
+func newPipesStub(t *testing.T, statusCodes ...int) (context.Context, *atomic.Int32) {
+	t.Helper()
+	// the app specific env var keys are not set when testing
+	envPipesToken := app_specific.EnvPipesToken
+	app_specific.EnvPipesToken = "PIPE_FITTINGS_TEST_PIPES_TOKEN"
+	t.Cleanup(func() { app_specific.EnvPipesToken = envPipesToken })
+	t.Setenv(app_specific.EnvPipesToken, "pipes_token")
+	AllowPipesCloudHost("pipes.example.com")
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count := int(requests.Add(1))
+		if r.Header.Get("Authorization") != "Bearer pipes_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if count <= len(statusCodes) {
+			w.WriteHeader(statusCodes[count-1])
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"config": map[string]any{"token": "github_token"},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	serverUrl, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	config := DefaultPipesClientConfig()
+	config.Backoff = time.Millisecond
+	config.HttpClient = &http.Client{Transport: stubTransport{serverUrl: serverUrl}}
+	return AddPipesClientConfigToContext(context.Background(), config), &requests
+}
+
+func testPipesConnectionMetadata() *PipesConnectionMetadata {
+	return &PipesConnectionMetadata{
+		CloudHost:  utils.ToStringPointer("pipes.example.com"),
+		User:       utils.ToStringPointer("user"),
+		Workspace:  utils.ToStringPointer("workspace"),
+		Connection: utils.ToStringPointer("github"),
+	}
+}
+
+func TestPipesConnectionMetadata_ResolveRetries(t *testing.T) {
+	// transient errors are retried
+	ctx, requests := newPipesStub(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	resolved, err := testPipesConnectionMetadata().Resolve(ctx, &GithubConnection{})
+	require.NoError(t, err)
+	assert.Equal(t, "github_token", *resolved.(*GithubConnection).Token)
+	assert.Equal(t, int32(3), requests.Load())
+
+	// client errors are not retried, and are classified by status code
+	ctx, requests = newPipesStub(t, http.StatusForbidden)
+	_, err = testPipesConnectionMetadata().Resolve(ctx, &GithubConnection{})
+	require.Error(t, err)
+	assert.True(t, perr.IsForbidden(err))
+	assert.Equal(t, int32(1), requests.Load())
+
+	// retries stop after MaxRetries
+	ctx, requests = newPipesStub(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	_, err = testPipesConnectionMetadata().Resolve(ctx, &GithubConnection{})
+	require.Error(t, err)
+	assert.Equal(t, int32(4), requests.Load())
+}
+
+func TestPipesConnectionMetadata_ResolveCancelled(t *testing.T) {
+	ctx, requests := newPipesStub(t, http.StatusServiceUnavailable)
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err := testPipesConnectionMetadata().Resolve(ctx, &GithubConnection{})
+	require.Error(t, err)
+	assert.Equal(t, int32(0), requests.Load())
+}
+
+func TestPipesClientConfigFromContext(t *testing.T) {
+	// fields which are not set are defaulted
+	config := pipesClientConfigFromContext(AddPipesClientConfigToContext(context.Background(), PipesClientConfig{MaxBackoff: time.Second}))
+	defaultConfig := DefaultPipesClientConfig()
+	assert.Equal(t, defaultConfig.Timeout, config.Timeout)
+	assert.Equal(t, defaultConfig.MaxRetries, config.MaxRetries)
+	assert.Equal(t, defaultConfig.Backoff, config.Backoff)
+	assert.Equal(t, time.Second, config.MaxBackoff)
+	assert.NotNil(t, config.HttpClient)
+
+	// a negative MaxRetries disables retries
+	config = pipesClientConfigFromContext(AddPipesClientConfigToContext(context.Background(), PipesClientConfig{MaxRetries: -1}))
+	assert.Equal(t, -1, config.MaxRetries)
+}
+
+func TestPipesConnectionMetadata_ResolveRetryAfter(t *testing.T) {
+	ctx, requests := newPipesStub(t, http.StatusTooManyRequests)
+	config := pipesClientConfigFromContext(ctx)
+	// the Retry-After delay is honoured even if it exceeds MaxBackoff
+	config.MaxBackoff = time.Millisecond
+	config.HttpClient = retryAfterClient{client: config.HttpClient, retryAfter: "1"}
+	ctx = AddPipesClientConfigToContext(ctx, config)
+
+	start := time.Now()
+	_, err := testPipesConnectionMetadata().Resolve(ctx, &GithubConnection{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+// retryAfterClient sets the Retry-After header of responses which are not successful
+type retryAfterClient struct {
+	client     HttpClient
+	retryAfter string
+}
+
+func (c retryAfterClient) Do(req *http.Request) (*http.Response, error) {
+	resp, err := c.client.Do(req)
+	if err == nil && resp.StatusCode != http.StatusOK {
+		resp.Header.Set("Retry-After", c.retryAfter)
+	}
+	return resp, err
+}
@@ -3,12 +3,17 @@ package connection
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
 	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/sslio"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -28,11 +33,20 @@ type PostgresConnection struct {
 	UserName         *string   `json:"username,omitempty" cty:"username" hcl:"username,optional"`
 	Host             *string   `json:"host,omitempty" cty:"host" hcl:"host,optional"`
 	Port             *int      `json:"port,omitempty" cty:"port" hcl:"port,optional"`
-	Password         *string   `json:"password,omitempty" cty:"password" hcl:"password,optional"`
+	Password         *string   `json:"password,omitempty" cty:"password" hcl:"password,optional" secret:"true"`
 	SearchPath       *[]string `json:"search_path,omitempty" cty:"search_path" hcl:"search_path,optional"`
 	SearchPathPrefix *[]string `json:"search_path_prefix,omitempty" cty:"search_path_prefix" hcl:"search_path_prefix,optional"`
 	SslMode          *string   `json:"sslmode,omitempty" cty:"sslmode" hcl:"sslmode,optional"`
-	ConnectionString *string   `json:"connection_string,omitempty" cty:"connection_string"`
+	SslRootCert      *string   `json:"sslrootcert,omitempty" cty:"sslrootcert" hcl:"sslrootcert,optional"`
+	SslCert          *string   `json:"sslcert,omitempty" cty:"sslcert" hcl:"sslcert,optional"`
+	SslKey           *string   `json:"sslkey,omitempty" cty:"sslkey" hcl:"sslkey,optional"`
+	ApplicationName  *string   `json:"application_name,omitempty" cty:"application_name" hcl:"application_name,optional"`
+	ConnectTimeout   *int      `json:"connect_timeout,omitempty" cty:"connect_timeout" hcl:"connect_timeout,optional"`
+	Options          *string   `json:"options,omitempty" cty:"options" hcl:"options,optional"`
+	// hosts to try in turn, in the form host or host:port - if no port is specified, port is used
+	Hosts              *[]string `json:"hosts,omitempty" cty:"hosts" hcl:"hosts,optional"`
+	TargetSessionAttrs *string   `json:"target_session_attrs,omitempty" cty:"target_session_attrs" hcl:"target_session_attrs,optional"`
+	ConnectionString   *string   `json:"connection_string,omitempty" cty:"connection_string" secret:"true"`
 }
 
 func NewPostgresConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -45,11 +59,21 @@ func (c *PostgresConnection) GetConnectionType() string {
 }
 
 func (c *PostgresConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &PostgresConnection{ConnectionImpl: c.ConnectionImpl})
 	}
 
+	// the certificate files are checked when the connection is resolved, rather than when it is parsed
+	if err := checkSslFiles(c.SslRootCert, c.SslCert, c.SslKey); err != nil {
+		return nil, err
+	}
+
 	// if pipes is nil, we must have a connection string, so there is nothing to so
 	return c, nil
 }
@@ -57,7 +81,8 @@ func (c *PostgresConnection) Resolve(ctx context.Context) (PipelingConnection, e
 func (c *PostgresConnection) Validate() hcl.Diagnostics {
 	// if pipes metadata is set, no other properties should be sets
 	if c.Pipes != nil {
-		if c.UserName != nil || c.Host != nil || c.Port != nil || c.Password != nil || c.SearchPath != nil {
+		if c.UserName != nil || c.Host != nil || c.Hosts != nil || c.Port != nil || c.Password != nil || c.SearchPath != nil ||
+			c.SslRootCert != nil || c.SslCert != nil || c.SslKey != nil {
 			return hcl.Diagnostics{
 				{
 					Severity: hcl.DiagError,
@@ -79,17 +104,73 @@ func (c *PostgresConnection) Validate() hcl.Diagnostics {
 			c.UserName = &defaultPostgresUser
 		}
 
-		if c.Host == nil {
+		if c.Host == nil && c.Hosts == nil {
 			c.Host = &defaultPostgresHost
 		}
 
 		if c.Port == nil {
 			c.Port = &defaultPostgresPort
 		}
+	}
+
+	var diags hcl.Diagnostics
+	// validate sslmode
+	if c.SslMode != nil {
+		diags = append(diags, validateSSlMode(*c.SslMode, c.DeclRange.HclRangePointer())...)
+	}
+	diags = append(diags, validateSslClientCertificate(c.SslCert, c.SslKey, c.DeclRange.HclRangePointer())...)
+	diags = append(diags, c.validateHosts()...)
+
+	if c.ConnectTimeout != nil && *c.ConnectTimeout < 0 {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "connect_timeout must not be negative",
+			Subject:  c.DeclRange.HclRangePointer(),
+		})
+	}
+	if c.TargetSessionAttrs != nil && !slices.Contains(postgresTargetSessionAttrs, *c.TargetSessionAttrs) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("target_session_attrs must be one of %s", strings.Join(postgresTargetSessionAttrs, ", ")),
+			Subject:  c.DeclRange.HclRangePointer(),
+		})
+	}
+	return diags
+}
 
-		// validate sslmode
-		if c.SslMode != nil {
-			return validateSSlMode(*c.SslMode, c.DeclRange.HclRangePointer())
+var postgresTargetSessionAttrs = []string{"any", "read-write", "read-only", "primary", "standby", "prefer-standby"}
+
+func (c *PostgresConnection) validateHosts() hcl.Diagnostics {
+	if c.Hosts == nil {
+		return nil
+	}
+	if c.Host != nil {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "only one of host, hosts may be set",
+				Subject:  c.DeclRange.HclRangePointer(),
+			},
+		}
+	}
+	if len(*c.Hosts) == 0 {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "hosts must contain at least one host",
+				Subject:  c.DeclRange.HclRangePointer(),
+			},
+		}
+	}
+	for _, h := range *c.Hosts {
+		if _, _, err := splitPostgresHostPort(h, defaultPostgresPort); err != nil {
+			return hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  fmt.Sprintf("invalid host %s: %s", h, err.Error()),
+					Subject:  c.DeclRange.HclRangePointer(),
+				},
+			}
 		}
 	}
 	return nil
@@ -119,30 +200,79 @@ func validateSSlMode(s string, declRange *hcl.Range) hcl.Diagnostics {
 	}
 }
 
+// validateSslClientCertificate validates that a client certificate and key are set together. The files are not
+// read until the connection is resolved (see checkSslFiles)
+func validateSslClientCertificate(cert, key *string, declRange *hcl.Range) hcl.Diagnostics {
+	if (cert == nil) != (key == nil) {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "a client certificate and key must be set together",
+				Subject:  declRange,
+			},
+		}
+	}
+	return nil
+}
+
+// checkSslFiles checks that the certificate and key files exist and can be parsed
+func checkSslFiles(rootCert, cert, key *string) error {
+	for _, certFile := range []*string{rootCert, cert} {
+		if certFile == nil {
+			continue
+		}
+		if _, err := sslio.ParseCertificateInLocation(*certFile); err != nil {
+			return perr.BadRequestWithMessage(fmt.Sprintf("invalid certificate %s: %s", *certFile, err.Error()))
+		}
+	}
+	if key != nil {
+		if _, err := sslio.ParsePrivateKeyInLocation(*key); err != nil {
+			return perr.BadRequestWithMessage(fmt.Sprintf("invalid private key %s: %s", *key, err.Error()))
+		}
+	}
+	return nil
+}
+
 func (c *PostgresConnection) GetConnectionString() string {
 	if c.ConnectionString != nil {
 		return *c.ConnectionString
 	}
 
 	// db, username, host and port all have default values if not set
-	connString := buildPostgresConnectionString(
-		c.getDbName(),
-		c.getUserName(),
-		c.getHost(),
-		c.getPort(),
-		c.Password, c.SslMode)
-	return connString
+	return buildPostgresConnectionString(c.connectionParams())
 }
 
 func (c *PostgresConnection) GetEnv() map[string]cty.Value {
 	// db, username, host and port all have default values if not set
-	return postgresConnectionParamsToEnvValueMap(
-		c.getDbName(),
-		c.getUserName(),
-		c.getHost(),
-		c.getPort(),
-		c.Password,
-		c.SslMode)
+	return postgresConnectionParamsToEnvValueMap(c.connectionParams())
+}
+
+func (c *PostgresConnection) connectionParams() postgresConnectionParams {
+	params := postgresConnectionParams{
+		db:                 c.getDbName(),
+		user:               c.getUserName(),
+		password:           c.Password,
+		sslMode:            c.SslMode,
+		sslRootCert:        c.SslRootCert,
+		sslCert:            c.SslCert,
+		sslKey:             c.SslKey,
+		applicationName:    c.ApplicationName,
+		connectTimeout:     c.ConnectTimeout,
+		options:            c.Options,
+		targetSessionAttrs: c.TargetSessionAttrs,
+	}
+	if c.Hosts == nil {
+		params.hosts = []string{c.getHost()}
+		params.ports = []int{c.getPort()}
+		return params
+	}
+	for _, h := range *c.Hosts {
+		// hosts are validated by Validate
+		host, port, _ := splitPostgresHostPort(h, c.getPort())
+		params.hosts = append(params.hosts, host)
+		params.ports = append(params.ports, port)
+	}
+	return params
 }
 
 func (c *PostgresConnection) GetSearchPath() []string {
@@ -181,6 +311,14 @@ func (c *PostgresConnection) Equals(otherConnection PipelingConnection) bool {
 		utils.SlicePtrEqual(c.SearchPath, other.SearchPath) &&
 		utils.SlicePtrEqual(c.SearchPathPrefix, other.SearchPathPrefix) &&
 		utils.PtrEqual(c.SslMode, other.SslMode) &&
+		utils.PtrEqual(c.SslRootCert, other.SslRootCert) &&
+		utils.PtrEqual(c.SslCert, other.SslCert) &&
+		utils.PtrEqual(c.SslKey, other.SslKey) &&
+		utils.PtrEqual(c.ApplicationName, other.ApplicationName) &&
+		utils.PtrEqual(c.ConnectTimeout, other.ConnectTimeout) &&
+		utils.PtrEqual(c.Options, other.Options) &&
+		utils.SlicePtrEqual(c.Hosts, other.Hosts) &&
+		utils.PtrEqual(c.TargetSessionAttrs, other.TargetSessionAttrs) &&
 		c.GetConnectionImpl().Equals(other.GetConnectionImpl())
 
 }
@@ -189,54 +327,136 @@ func (c *PostgresConnection) CtyValue() (cty.Value, error) {
 	return ctyValueForConnection(c)
 }
 
-func buildPostgresConnectionString(db string, user string, host string, port int, pPassword *string, pSslMode *string) string {
-	password := typehelpers.SafeString(pPassword)
-	sslmode := typehelpers.SafeString(pSslMode)
+// postgresConnectionParams are the libpq connection parameters used to build a connection string or env vars
+type postgresConnectionParams struct {
+	db   string
+	user string
+	// the hosts and corresponding ports - if there are multiple hosts, they are tried in turn
+	hosts              []string
+	ports              []int
+	password           *string
+	sslMode            *string
+	sslRootCert        *string
+	sslCert            *string
+	sslKey             *string
+	applicationName    *string
+	connectTimeout     *int
+	options            *string
+	targetSessionAttrs *string
+}
+
+// optionalParams returns the libpq names and values of the optional parameters which are set
+func (p postgresConnectionParams) optionalParams() [][2]string {
+	var res [][2]string
+	for _, param := range []struct {
+		name  string
+		value *string
+	}{
+		{"sslmode", p.sslMode},
+		{"sslrootcert", p.sslRootCert},
+		{"sslcert", p.sslCert},
+		{"sslkey", p.sslKey},
+		{"application_name", p.applicationName},
+		{"options", p.options},
+		{"target_session_attrs", p.targetSessionAttrs},
+	} {
+		if param.value != nil && *param.value != "" {
+			res = append(res, [2]string{param.name, *param.value})
+		}
+	}
+	if p.connectTimeout != nil {
+		res = append(res, [2]string{"connect_timeout", strconv.Itoa(*p.connectTimeout)})
+	}
+	return res
+}
+
+func buildPostgresConnectionString(params postgresConnectionParams) string {
+	password := typehelpers.SafeString(params.password)
+
+	hosts := make([]string, len(params.hosts))
+	for i, host := range params.hosts {
+		hosts[i] = net.JoinHostPort(host, strconv.Itoa(params.ports[i]))
+	}
 
 	// Use url.URL to encode the connection string parameters safely
 	connStr := url.URL{
 		Scheme: "postgresql",
-		Host:   fmt.Sprintf("%s:%d", host, port),
-		Path:   db, // This adds the /dbname part in the connection string
+		Host:   strings.Join(hosts, ","),
+		Path:   params.db, // This adds the /dbname part in the connection string
 	}
 
 	// Set the user with or without the password
 	if password == "" {
-		connStr.User = url.User(user) // No password
+		connStr.User = url.User(params.user) // No password
 	} else {
-		connStr.User = url.UserPassword(user, password)
+		connStr.User = url.UserPassword(params.user, password)
 	}
 
 	// Add SSL mode or other query parameters if needed
 	q := connStr.Query()
-	if sslmode != "" {
-		q.Add("sslmode", sslmode)
+	for _, param := range params.optionalParams() {
+		q.Add(param[0], param[1])
 	}
 	connStr.RawQuery = q.Encode()
 
 	return connStr.String()
 }
 
-func postgresConnectionParamsToEnvValueMap(db, username, host string, port int, password, sslmode *string) map[string]cty.Value {
+// postgresEnvVars maps libpq parameter names to the corresponding libpq env vars
+var postgresEnvVars = map[string]string{
+	"sslmode":              "PGSSLMODE",
+	"sslrootcert":          "PGSSLROOTCERT",
+	"sslcert":              "PGSSLCERT",
+	"sslkey":               "PGSSLKEY",
+	"application_name":     "PGAPPNAME",
+	"connect_timeout":      "PGCONNECT_TIMEOUT",
+	"options":              "PGOPTIONS",
+	"target_session_attrs": "PGTARGETSESSIONATTRS",
+}
+
+func postgresConnectionParamsToEnvValueMap(params postgresConnectionParams) map[string]cty.Value {
+	ports := make([]string, len(params.ports))
+	for i, port := range params.ports {
+		ports[i] = strconv.Itoa(port)
+	}
+
+	// libpq accepts comma separated lists of hosts and ports
 	envVars := map[string]cty.Value{
-		"PGDATABASE": cty.StringVal(db),
-		"PGUSER":     cty.StringVal(username),
-		"PGHOST":     cty.StringVal(host),
-		"PGPORT":     cty.StringVal(strconv.Itoa(port)),
+		"PGDATABASE": cty.StringVal(params.db),
+		"PGUSER":     cty.StringVal(params.user),
+		"PGHOST":     cty.StringVal(strings.Join(params.hosts, ",")),
+		"PGPORT":     cty.StringVal(strings.Join(ports, ",")),
 	}
 
 	// Add optional fields if not nil
-	if password != nil {
-		envVars["PGPASSWORD"] = cty.StringVal(*password)
+	if params.password != nil {
+		envVars["PGPASSWORD"] = cty.StringVal(*params.password)
 	}
-	if sslmode != nil {
-		envVars["PGSSLMODE"] = cty.StringVal(*sslmode)
+	for _, param := range params.optionalParams() {
+		envVars[postgresEnvVars[param[0]]] = cty.StringVal(param[1])
 	}
 
 	// Convert the map to a cty.Value map
 	return envVars
 }
 
+// splitPostgresHostPort splits a host of the form host or host:port, using the default port if no port is specified
+func splitPostgresHostPort(hostPort string, defaultPort int) (string, int, error) {
+	host, portString, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		// there is no port
+		if strings.TrimSpace(hostPort) == "" {
+			return "", 0, fmt.Errorf("host must not be empty")
+		}
+		return hostPort, defaultPort, nil
+	}
+	port, err := strconv.Atoi(portString)
+	if err != nil || port <= 0 || port > 65535 {
+		return "", 0, fmt.Errorf("invalid port %s", portString)
+	}
+	return host, port, nil
+}
+
 // TODO avoid the need for this
 func (c *PostgresConnection) getPort() int {
 	if c.Port != nil {
@@ -265,3 +485,8 @@ func (c *PostgresConnection) getUserName() string {
 	}
 	return defaultPostgresUser
 }
+
+// Verify connects to the database and runs a test query
+func (c *PostgresConnection) Verify(ctx context.Context) error {
+	return verifyDatabaseConnection(ctx, c.GetConnectionString())
+}
@@ -0,0 +1,180 @@
+package connection
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/turbot/pipe-fittings/constants"
+	"golang.org/x/sync/singleflight"
+)
+
+// ResolvedCache caches the result of PipelingConnection.Resolve.
+//
+// Entries are keyed by the connection full name and a hash of the connection config, so a change to the
+// connection config results in a cache miss. Each entry expires after the ttl of the resolved connection
+// (as returned by GetTtl); a ttl of zero disables caching and a negative ttl (i.e. no ttl set) uses the
+// default ttl of the cache. Concurrent resolves of the same connection are de-duplicated, and each resolve
+// is bounded by the resolve timeout of the cache.
+type ResolvedCache struct {
+	mu sync.Mutex
+	// map of cache entries, keyed by connection full name and config hash
+	entries map[string]resolvedCacheEntry
+	group   singleflight.Group
+
+	// now returns the current time - this may be overridden for testing
+	now        func() time.Time
+	defaultTtl time.Duration
+	// the maximum duration of a resolve - as a resolve is shared by concurrent callers, it is not cancelled
+	// by the callers' contexts, so must be bounded to prevent a hung resolve blocking every later caller
+	resolveTimeout time.Duration
+}
+
+type resolvedCacheEntry struct {
+	fullName   string
+	connection PipelingConnection
+	expiresAt  time.Time
+}
+
+type ResolvedCacheOption func(*ResolvedCache)
+
+// WithClock sets the function used to get the current time
+func WithClock(now func() time.Time) ResolvedCacheOption {
+	return func(c *ResolvedCache) {
+		c.now = now
+	}
+}
+
+// WithDefaultTtl sets the ttl used for resolved connections which do not have a ttl set
+func WithDefaultTtl(ttl time.Duration) ResolvedCacheOption {
+	return func(c *ResolvedCache) {
+		c.defaultTtl = ttl
+	}
+}
+
+// WithResolveTimeout sets the maximum duration of a resolve
+func WithResolveTimeout(timeout time.Duration) ResolvedCacheOption {
+	return func(c *ResolvedCache) {
+		c.resolveTimeout = timeout
+	}
+}
+
+func NewResolvedCache(opts ...ResolvedCacheOption) *ResolvedCache {
+	c := &ResolvedCache{
+		entries:        make(map[string]resolvedCacheEntry),
+		now:            time.Now,
+		defaultTtl:     constants.DefaultConnectionTtl * time.Second,
+		resolveTimeout: constants.DefaultConnectionResolveTimeout * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Resolve returns the cached resolved connection if there is an unexpired entry,
+// otherwise it resolves the connection and caches the result
+func (c *ResolvedCache) Resolve(ctx context.Context, conn PipelingConnection) (PipelingConnection, error) {
+	key, err := resolvedCacheKey(conn)
+	if err != nil {
+		// the connection cannot be hashed, so cannot be cached
+		slog.Warn("failed to build resolved connection cache key - the resolved connection will not be cached", "connection", conn.Name(), "error", err)
+		return conn.Resolve(ctx)
+	}
+
+	if resolved, ok := c.get(key); ok {
+		return resolved, nil
+	}
+
+	// NOTE: the resolve is shared by all concurrent callers, so should not be cancelled if the context of
+	// the first caller is cancelled - each caller instead stops waiting when its own context is done
+	resultChan := c.group.DoChan(key, func() (any, error) {
+		// another caller may have populated the cache since we checked
+		if resolved, ok := c.get(key); ok {
+			return resolved, nil
+		}
+		resolveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.resolveTimeout)
+		defer cancel()
+		resolved, err := conn.Resolve(resolveCtx)
+		if err != nil {
+			return nil, err
+		}
+		c.set(key, conn.Name(), resolved)
+		return resolved, nil
+	})
+
+	select {
+	case res := <-resultChan:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(PipelingConnection), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Invalidate removes all cached entries for the connection with the given full name
+func (c *ResolvedCache) Invalidate(fullName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range c.entries {
+		if entry.fullName == fullName {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// InvalidateAll removes all cached entries
+func (c *ResolvedCache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+func (c *ResolvedCache) get(key string) (PipelingConnection, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.connection, true
+}
+
+func (c *ResolvedCache) set(key, fullName string, resolved PipelingConnection) {
+	ttl := c.defaultTtl
+	if t := resolved.GetTtl(); t >= 0 {
+		ttl = time.Duration(t) * time.Second
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = resolvedCacheEntry{
+		fullName:   fullName,
+		connection: resolved,
+		expiresAt:  c.now().Add(ttl),
+	}
+}
+
+// resolvedCacheKey builds the cache key from the connection full name and a hash of the connection config
+func resolvedCacheKey(conn PipelingConnection) (string, error) {
+	configJson, err := json.Marshal(conn)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(configJson)
+	return conn.Name() + ":" + hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,141 @@
+package connection
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingConnection is a connection which counts the number of times it is resolved
+type countingConnection struct {
+	SlackConnection
+	resolveCount *atomic.Int32
+	// if set, Resolve blocks until this is closed
+	block chan struct{}
+}
+
+func newCountingConnection(token string, ttl int) *countingConnection {
+	c := &countingConnection{
+		SlackConnection: *NewSlackConnection("default", hcl.Range{}).(*SlackConnection),
+		resolveCount:    &atomic.Int32{},
+	}
+	c.Token = &token
+	c.SetTtl(ttl)
+	return c
+}
+
+func (c *countingConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	if c.block != nil {
+		select {
+		case <-c.block:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	c.resolveCount.Add(1)
+	return c, nil
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestResolvedCacheTtl(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Now()}
+	cache := NewResolvedCache(WithClock(clock.Now))
+
+	conn := newCountingConnection("token", 60)
+	for range 3 {
+		_, err := cache.Resolve(ctx, conn)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), conn.resolveCount.Load())
+
+	// expires after the ttl
+	clock.now = clock.now.Add(59 * time.Second)
+	_, _ = cache.Resolve(ctx, conn)
+	assert.Equal(t, int32(1), conn.resolveCount.Load())
+	clock.now = clock.now.Add(time.Second)
+	_, _ = cache.Resolve(ctx, conn)
+	assert.Equal(t, int32(2), conn.resolveCount.Load())
+
+	// a config change is a cache miss
+	changed := newCountingConnection("new token", 60)
+	_, _ = cache.Resolve(ctx, changed)
+	assert.Equal(t, int32(1), changed.resolveCount.Load())
+
+	// explicit invalidation
+	cache.Invalidate(conn.Name())
+	_, _ = cache.Resolve(ctx, conn)
+	_, _ = cache.Resolve(ctx, changed)
+	assert.Equal(t, int32(3), conn.resolveCount.Load())
+	assert.Equal(t, int32(2), changed.resolveCount.Load())
+
+	// a zero ttl disables caching
+	uncached := newCountingConnection("uncached", 0)
+	_, _ = cache.Resolve(ctx, uncached)
+	_, _ = cache.Resolve(ctx, uncached)
+	assert.Equal(t, int32(2), uncached.resolveCount.Load())
+
+	// no ttl uses the cache default
+	noTtl := newCountingConnection("no ttl", -1)
+	_, _ = cache.Resolve(ctx, noTtl)
+	clock.now = clock.now.Add(59 * time.Minute)
+	_, _ = cache.Resolve(ctx, noTtl)
+	assert.Equal(t, int32(1), noTtl.resolveCount.Load())
+	clock.now = clock.now.Add(time.Minute)
+	_, _ = cache.Resolve(ctx, noTtl)
+	assert.Equal(t, int32(2), noTtl.resolveCount.Load())
+}
+
+func TestResolvedCacheConcurrentResolve(t *testing.T) {
+	ctx := context.Background()
+	cache := NewResolvedCache()
+	conn := newCountingConnection("token", 60)
+	conn.block = make(chan struct{})
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resolved, err := cache.Resolve(ctx, conn)
+			assert.NoError(t, err)
+			assert.Equal(t, conn.Name(), resolved.Name())
+		}()
+	}
+	// give the goroutines a chance to start waiting on the resolve
+	time.Sleep(10 * time.Millisecond)
+	close(conn.block)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), conn.resolveCount.Load())
+}
+
+func TestResolvedCacheResolveTimeout(t *testing.T) {
+	ctx := context.Background()
+	cache := NewResolvedCache(WithResolveTimeout(10 * time.Millisecond))
+	conn := newCountingConnection("token", 60)
+	// the resolve hangs until the resolve timeout
+	conn.block = make(chan struct{})
+
+	_, err := cache.Resolve(ctx, conn)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the hung resolve does not block later callers
+	close(conn.block)
+	resolved, err := cache.Resolve(ctx, conn)
+	require.NoError(t, err)
+	assert.Equal(t, conn.Name(), resolved.Name())
+}
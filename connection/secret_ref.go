@@ -0,0 +1,347 @@
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/contexthelpers"
+	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/sanitize"
+)
+
+// secret references may be used for any secret attribute of a connection (i.e. a field tagged `secret:"true"`),
+// and are dereferenced when the connection is resolved:
+//
+//	env://NAME              - the value of the environment variable NAME
+//	file:///path/to/file    - the contents of the file (with any trailing newline removed)
+//	vault://mount/path#key  - the key of the vault KV secret at mount/path
+const (
+	envSecretRefPrefix   = "env://"
+	fileSecretRefPrefix  = "file://"
+	vaultSecretRefPrefix = "vault://"
+)
+
+var contextKeySecretRefVaultConnection = contexthelpers.ContextKey("secret_ref_vault_connection")
+
+// the http client used to read vault secrets
+var secretRefHttpClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+// AddSecretRefVaultConnectionToContext sets the vault connection used to dereference vault:// secret references.
+// If no vault connection is set, VAULT_ADDR and VAULT_TOKEN are used
+func AddSecretRefVaultConnectionToContext(ctx context.Context, vaultConnection *VaultConnection) context.Context {
+	return context.WithValue(ctx, contextKeySecretRefVaultConnection, vaultConnection)
+}
+
+func secretRefVaultConnectionFromContext(ctx context.Context) (*VaultConnection, error) {
+	val, ok := ctx.Value(contextKeySecretRefVaultConnection).(*VaultConnection)
+	if !ok {
+		return &VaultConnection{}, nil
+	}
+	// a nil connection is set while resolving the vault connection itself
+	if val == nil {
+		return nil, perr.BadRequestWithMessage("vault secret references cannot be used by the vault connection used to resolve them")
+	}
+	return val, nil
+}
+
+// IsSecretRef returns whether the value is a secret reference
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, envSecretRefPrefix) ||
+		strings.HasPrefix(value, fileSecretRefPrefix) ||
+		strings.HasPrefix(value, vaultSecretRefPrefix)
+}
+
+// ResolveSecretRef returns the secret referenced by the value, or the value unchanged if it is not a secret reference
+func ResolveSecretRef(ctx context.Context, value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, envSecretRefPrefix):
+		name := strings.TrimPrefix(value, envSecretRefPrefix)
+		if name == "" {
+			return "", perr.BadRequestWithMessage(fmt.Sprintf("invalid secret reference '%s' - an environment variable name is required", value))
+		}
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", perr.NotFoundWithMessage(fmt.Sprintf("environment variable %s referenced by '%s' is not set", name, value))
+		}
+		return secret, nil
+
+	case strings.HasPrefix(value, fileSecretRefPrefix):
+		path := strings.TrimPrefix(value, fileSecretRefPrefix)
+		if path == "" {
+			return "", perr.BadRequestWithMessage(fmt.Sprintf("invalid secret reference '%s' - a file path is required", value))
+		}
+		secret, err := os.ReadFile(path)
+		if err != nil {
+			return "", perr.BadRequestWithMessage(fmt.Sprintf("failed to read file referenced by '%s': %s", value, err.Error()))
+		}
+		return strings.TrimRight(string(secret), "\r\n"), nil
+
+	case strings.HasPrefix(value, vaultSecretRefPrefix):
+		return resolveVaultSecretRef(ctx, value)
+	}
+
+	return value, nil
+}
+
+// dereferenceSecretRefs returns the connection with any secret references in its secret attributes dereferenced.
+// It is called by the Resolve function of each connection, before any other resolution
+func dereferenceSecretRefs[T PipelingConnection](ctx context.Context, c T) (T, error) {
+	resolved, err := resolveSecretRefs(ctx, c)
+	if err != nil {
+		return c, err
+	}
+	return resolved.(T), nil
+}
+
+// secretRefResolver is implemented by connections which do not store their attributes in tagged struct fields,
+// and so must dereference their own secret references
+type secretRefResolver interface {
+	resolveSecretRefs(ctx context.Context) (PipelingConnection, error)
+}
+
+// resolveSecretRefs returns the connection with any secret references in its secret attributes dereferenced.
+// Attributes which are not tagged as secrets (e.g. file paths) are never dereferenced.
+// If the connection contains no secret references it is returned unchanged, otherwise a copy is returned
+func resolveSecretRefs(ctx context.Context, c PipelingConnection) (PipelingConnection, error) {
+	if r, ok := c.(secretRefResolver); ok {
+		return r.resolveSecretRefs(ctx)
+	}
+
+	ptr := reflect.ValueOf(c)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() || ptr.Elem().Kind() != reflect.Struct {
+		return c, nil
+	}
+	val := ptr.Elem()
+	var resolved reflect.Value
+
+	// only the attributes of the concrete connection are checked - the embedded ConnectionImpl is skipped
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Type().Field(i)
+		if field.Anonymous || !field.IsExported() || !sanitize.IsSecretField(field) {
+			continue
+		}
+		newValue, changed, err := resolveSecretRefsForValue(ctx, val.Field(i))
+		if err != nil {
+			return c, secretRefError(err, fmt.Sprintf("failed to resolve %s for connection %s", attributeName(field), c.Name()))
+		}
+		if !changed {
+			continue
+		}
+		// copy the connection on the first change so the original connection is not modified
+		if !resolved.IsValid() {
+			resolved = reflect.New(val.Type())
+			resolved.Elem().Set(val)
+		}
+		resolved.Elem().Field(i).Set(newValue)
+	}
+
+	if !resolved.IsValid() {
+		return c, nil
+	}
+	return resolved.Interface().(PipelingConnection), nil
+}
+
+// resolveSecretRefsForValue dereferences secret references in string, *string, *[]string and *map[string]string values
+func resolveSecretRefsForValue(ctx context.Context, v reflect.Value) (reflect.Value, bool, error) {
+	switch value := v.Interface().(type) {
+	case string:
+		if !IsSecretRef(value) {
+			return v, false, nil
+		}
+		secret, err := ResolveSecretRef(ctx, value)
+		if err != nil {
+			return v, false, err
+		}
+		return reflect.ValueOf(secret), true, nil
+
+	case *string:
+		if value == nil || !IsSecretRef(*value) {
+			return v, false, nil
+		}
+		secret, err := ResolveSecretRef(ctx, *value)
+		if err != nil {
+			return v, false, err
+		}
+		return reflect.ValueOf(&secret), true, nil
+
+	case *[]string:
+		if value == nil {
+			return v, false, nil
+		}
+		var secrets []string
+		for i, element := range *value {
+			if !IsSecretRef(element) {
+				continue
+			}
+			secret, err := ResolveSecretRef(ctx, element)
+			if err != nil {
+				return v, false, err
+			}
+			if secrets == nil {
+				secrets = append([]string{}, *value...)
+			}
+			secrets[i] = secret
+		}
+		if secrets == nil {
+			return v, false, nil
+		}
+		return reflect.ValueOf(&secrets), true, nil
+
+	case *map[string]string:
+		if value == nil {
+			return v, false, nil
+		}
+		var secrets map[string]string
+		for key, element := range *value {
+			if !IsSecretRef(element) {
+				continue
+			}
+			secret, err := ResolveSecretRef(ctx, element)
+			if err != nil {
+				return v, false, err
+			}
+			if secrets == nil {
+				secrets = maps.Clone(*value)
+			}
+			secrets[key] = secret
+		}
+		if secrets == nil {
+			return v, false, nil
+		}
+		return reflect.ValueOf(&secrets), true, nil
+	}
+	return v, false, nil
+}
+
+func resolveVaultSecretRef(ctx context.Context, ref string) (string, error) {
+	pathAndKey := strings.TrimPrefix(ref, vaultSecretRefPrefix)
+	secretPath, key, _ := strings.Cut(pathAndKey, "#")
+	mount, path, _ := strings.Cut(secretPath, "/")
+	if mount == "" || path == "" || key == "" {
+		return "", perr.BadRequestWithMessage(fmt.Sprintf("invalid secret reference '%s' - expected vault://mount/path#key", ref))
+	}
+
+	vaultConnection, err := secretRefVaultConnectionFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	// resolve the vault connection (it may itself use env:// or file:// secret references)
+	resolved, err := vaultConnection.Resolve(AddSecretRefVaultConnectionToContext(ctx, nil))
+	if err != nil {
+		return "", err
+	}
+	vaultConnection = resolved.(*VaultConnection)
+	if vaultConnection.Address == nil || *vaultConnection.Address == "" {
+		return "", perr.BadRequestWithMessage(fmt.Sprintf("cannot resolve '%s' - no vault address is set", ref))
+	}
+
+	data, err := readVaultSecret(ctx, vaultConnection, mount, path)
+	if err != nil {
+		return "", err
+	}
+	secret, ok := data[key]
+	if !ok {
+		return "", perr.NotFoundWithMessage(fmt.Sprintf("vault secret %s has no key %s", secretPath, key))
+	}
+	if str, ok := secret.(string); ok {
+		return str, nil
+	}
+	// non string values are returned as JSON
+	jsonBytes, err := json.Marshal(secret)
+	if err != nil {
+		return "", perr.InternalWithMessage(fmt.Sprintf("failed to serialise vault secret %s key %s", secretPath, key))
+	}
+	return string(jsonBytes), nil
+}
+
+// readVaultSecret reads the data of a KV secret, trying the KV version 2 API first and falling back to version 1
+func readVaultSecret(ctx context.Context, vaultConnection *VaultConnection, mount, path string) (map[string]any, error) {
+	address := strings.TrimSuffix(*vaultConnection.Address, "/")
+
+	// KV version 2 nests the secret data under data.data
+	var v2Response struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	found, err := callVaultApi(ctx, vaultConnection, fmt.Sprintf("%s/v1/%s/data/%s", address, url.PathEscape(mount), path), &v2Response)
+	if err != nil {
+		return nil, err
+	}
+	if found && v2Response.Data.Data != nil {
+		return v2Response.Data.Data, nil
+	}
+
+	var v1Response struct {
+		Data map[string]any `json:"data"`
+	}
+	found, err = callVaultApi(ctx, vaultConnection, fmt.Sprintf("%s/v1/%s/%s", address, url.PathEscape(mount), path), &v1Response)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, perr.NotFoundWithMessage(fmt.Sprintf("vault secret %s/%s not found", mount, path))
+	}
+	return v1Response.Data, nil
+}
+
+// callVaultApi calls the vault api and decodes the response into target. If the secret is not found, false is returned
+func callVaultApi(ctx context.Context, vaultConnection *VaultConnection, endpoint string, target any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return false, perr.InternalWithMessage("failed to create vault request")
+	}
+	if vaultConnection.Token != nil {
+		req.Header.Set("X-Vault-Token", *vaultConnection.Token)
+	}
+
+	resp, err := secretRefHttpClient.Do(req)
+	if err != nil {
+		return false, perr.InternalWithMessage(fmt.Sprintf("failed to call vault: %s", err.Error()))
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+			return false, perr.InternalWithMessage("failed to decode vault response body")
+		}
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, perr.UnauthorizedWithMessage(fmt.Sprintf("vault returned status code: %d", resp.StatusCode))
+	default:
+		return false, perr.InternalWithMessage(fmt.Sprintf("unexpected vault status code: %d", resp.StatusCode))
+	}
+}
+
+// attributeName returns the hcl attribute name of the field
+func attributeName(field reflect.StructField) string {
+	for _, tag := range []string{"hcl", "cty"} {
+		if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// secretRefError prefixes the detail of the error with the message, preserving the error type
+func secretRefError(err error, message string) error {
+	errorModel, ok := err.(perr.ErrorModel)
+	if !ok {
+		return perr.InternalWithMessage(fmt.Sprintf("%s: %s", message, err.Error()))
+	}
+	errorModel.Detail = fmt.Sprintf("%s: %s", message, errorModel.Detail)
+	return errorModel
+}
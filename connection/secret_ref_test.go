@@ -0,0 +1,160 @@
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/utils"
+)
+
+// newVaultStub starts a stub vault server with a KV version 2 secret at secret/app and
+// a KV version 1 secret at kv/app, which requires the given token
+func newVaultStub(t *testing.T, token string) *VaultConnection {
+	t.Helper()
+	secrets := map[string]any{
+		"/v1/secret/data/app": map[string]any{"data": map[string]any{"data": map[string]any{"password": "v2_password", "port": 5432}}},
+		"/v1/kv/app":          map[string]any{"data": map[string]any{"password": "v1_password"}},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		secret, ok := secrets[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(secret)
+	}))
+	t.Cleanup(server.Close)
+
+	return &VaultConnection{
+		ConnectionImpl: NewConnectionImpl(VaultConnectionType, "default", hcl.Range{}),
+		Address:        &server.URL,
+		Token:          utils.ToStringPointer(token),
+	}
+}
+
+func TestResolveSecretRef(t *testing.T) {
+	t.Setenv("SECRET_REF_TEST", "env_secret")
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("file_secret\n"), 0600))
+
+	vaultConnection := newVaultStub(t, "vault_token")
+	ctx := AddSecretRefVaultConnectionToContext(context.Background(), vaultConnection)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "literal", value: "literal_value", want: "literal_value"},
+		{name: "env", value: "env://SECRET_REF_TEST", want: "env_secret"},
+		{name: "env unset", value: "env://SECRET_REF_TEST_UNSET", wantErr: true},
+		{name: "file", value: "file://" + secretFile, want: "file_secret"},
+		{name: "file missing", value: "file://" + secretFile + "_missing", wantErr: true},
+		{name: "vault kv v2", value: "vault://secret/app#password", want: "v2_password"},
+		{name: "vault kv v2 non string", value: "vault://secret/app#port", want: "5432"},
+		{name: "vault kv v1", value: "vault://kv/app#password", want: "v1_password"},
+		{name: "vault missing key", value: "vault://secret/app#username", wantErr: true},
+		{name: "vault missing secret", value: "vault://secret/other#password", wantErr: true},
+		{name: "vault no key", value: "vault://secret/app", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveSecretRef(ctx, tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResolveSecretRefVaultForbidden(t *testing.T) {
+	vaultConnection := newVaultStub(t, "vault_token")
+	vaultConnection.Token = utils.ToStringPointer("wrong_token")
+	ctx := AddSecretRefVaultConnectionToContext(context.Background(), vaultConnection)
+
+	_, err := ResolveSecretRef(ctx, "vault://secret/app#password")
+	require.Error(t, err)
+	assert.Equal(t, perr.ErrorCodeUnauthorized, err.(perr.ErrorModel).Type)
+}
+
+func TestConnectionResolveSecretRefs(t *testing.T) {
+	t.Setenv("SLACK_TOKEN_SECRET", "slack_token")
+	t.Setenv("VAULT_TOKEN_SECRET", "vault_token")
+
+	// the vault connection token is itself a secret reference
+	vaultConnection := newVaultStub(t, "vault_token")
+	vaultConnection.Token = utils.ToStringPointer("env://VAULT_TOKEN_SECRET")
+	ctx := AddSecretRefVaultConnectionToContext(context.Background(), vaultConnection)
+
+	slackConnection := &SlackConnection{
+		ConnectionImpl: NewConnectionImpl(SlackConnectionType, "default", hcl.Range{}),
+		Token:          utils.ToStringPointer("env://SLACK_TOKEN_SECRET"),
+	}
+	resolved, err := slackConnection.Resolve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "slack_token", *resolved.(*SlackConnection).Token)
+	// the original connection is not modified
+	assert.Equal(t, "env://SLACK_TOKEN_SECRET", *slackConnection.Token)
+
+	postgresConnection := &PostgresConnection{
+		ConnectionImpl: NewConnectionImpl(PostgresConnectionType, "default", hcl.Range{}),
+		Password:       utils.ToStringPointer("vault://secret/app#password"),
+		SearchPath:     &[]string{"public", "env://SLACK_TOKEN_SECRET"},
+	}
+	resolved, err = postgresConnection.Resolve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "v2_password", *resolved.(*PostgresConnection).Password)
+	// only secret attributes are dereferenced
+	assert.Equal(t, []string{"public", "env://SLACK_TOKEN_SECRET"}, *resolved.(*PostgresConnection).SearchPath)
+
+	// file paths are not secrets, so a file:// URI is not replaced with the contents of the file
+	dbFile := filepath.Join(t.TempDir(), "test.db")
+	require.NoError(t, os.WriteFile(dbFile, []byte("db contents"), 0600))
+	sqliteConnection := &SqliteConnection{
+		ConnectionImpl: NewConnectionImpl(SqliteConnectionType, "default", hcl.Range{}),
+		FileName:       utils.ToStringPointer("file://" + dbFile),
+	}
+	resolved, err = sqliteConnection.Resolve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "file://"+dbFile, *resolved.(*SqliteConnection).FileName)
+
+	keyValueConnection := &KeyValueConnection{
+		ConnectionImpl: NewConnectionImpl(KeyValueConnectionType, "default", hcl.Range{}),
+		Values:         &map[string]string{"TOKEN": "env://SLACK_TOKEN_SECRET", "REGION": "us-east-1"},
+	}
+	resolved, err = keyValueConnection.Resolve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"TOKEN": "slack_token", "REGION": "us-east-1"}, *resolved.(*KeyValueConnection).Values)
+
+	// a connection without secret references is returned unchanged
+	githubConnection := &GithubConnection{
+		ConnectionImpl: NewConnectionImpl(GithubConnectionType, "default", hcl.Range{}),
+		Token:          utils.ToStringPointer("literal_token"),
+	}
+	resolved, err = githubConnection.Resolve(ctx)
+	require.NoError(t, err)
+	assert.Same(t, githubConnection, resolved)
+
+	// the error identifies the attribute and connection
+	slackConnection.Token = utils.ToStringPointer("env://SLACK_TOKEN_SECRET_UNSET")
+	_, err = slackConnection.Resolve(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to resolve token for connection slack.default")
+}
@@ -3,9 +3,12 @@ package connection
 import (
 	"context"
 	"os"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/pipe-fittings/perr"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -17,7 +20,12 @@ type ServiceNowConnection struct {
 
 	InstanceURL *string `json:"instance_url,omitempty" cty:"instance_url" hcl:"instance_url,optional"`
 	Username    *string `json:"username,omitempty" cty:"username" hcl:"username,optional"`
-	Password    *string `json:"password,omitempty" cty:"password" hcl:"password,optional"`
+	Password    *string `json:"password,omitempty" cty:"password" hcl:"password,optional" secret:"true"`
+
+	// if set, the oauth2 credentials are exchanged for an access token when the connection is resolved
+	OAuth2 *OAuth2Config `json:"oauth2,omitempty" cty:"oauth2" hcl:"oauth2,block"`
+	// the oauth2 access token is populated by Resolve
+	AccessToken *string `json:"access_token,omitempty" cty:"access_token" secret:"true"`
 }
 
 func NewServiceNowConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -30,11 +38,28 @@ func (c *ServiceNowConnection) GetConnectionType() string {
 }
 
 func (c *ServiceNowConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &ServiceNowConnection{ConnectionImpl: c.ConnectionImpl})
 	}
 
+	// if an oauth2 block is defined, exchange the oauth2 credentials for an access token
+	if c.OAuth2 != nil {
+		newConnection := *c
+		oauth2, err := c.OAuth2.Resolve(ctx, &newConnection.ConnectionImpl)
+		if err != nil {
+			return nil, err
+		}
+		newConnection.OAuth2 = oauth2
+		newConnection.AccessToken = oauth2.AccessToken
+		return &newConnection, nil
+	}
+
 	servicenowInstanceURLEnvVar := os.Getenv("SERVICENOW_INSTANCE_URL")
 	servicenowUsernameEnvVar := os.Getenv("SERVICENOW_USERNAME")
 	servicenowPasswordEnvVar := os.Getenv("SERVICENOW_PASSWORD")
@@ -92,11 +117,15 @@ func (c *ServiceNowConnection) Equals(otherConnection PipelingConnection) bool {
 		return false
 	}
 
+	if !c.OAuth2.Equals(other.OAuth2) {
+		return false
+	}
+
 	return c.GetConnectionImpl().Equals(otherConnection.GetConnectionImpl())
 }
 
 func (c *ServiceNowConnection) Validate() hcl.Diagnostics {
-	if c.Pipes != nil && (c.InstanceURL != nil || c.Username != nil || c.Password != nil) {
+	if c.Pipes != nil && (c.InstanceURL != nil || c.Username != nil || c.Password != nil || c.OAuth2 != nil) {
 		return hcl.Diagnostics{
 			{
 				Severity: hcl.DiagError,
@@ -105,6 +134,9 @@ func (c *ServiceNowConnection) Validate() hcl.Diagnostics {
 			},
 		}
 	}
+	if c.OAuth2 != nil {
+		return c.OAuth2.Validate(c.DeclRange.HclRangePointer())
+	}
 	return hcl.Diagnostics{}
 }
 
@@ -115,5 +147,28 @@ func (c *ServiceNowConnection) CtyValue() (cty.Value, error) {
 }
 
 func (c *ServiceNowConnection) GetEnv() map[string]cty.Value {
-	return nil
+	env := map[string]cty.Value{}
+	if c.AccessToken != nil {
+		env["SERVICENOW_ACCESS_TOKEN"] = cty.StringVal(*c.AccessToken)
+	}
+	return env
+}
+
+// Verify calls the ServiceNow table API to read a single user
+func (c *ServiceNowConnection) Verify(ctx context.Context) error {
+	if typehelpers.SafeString(c.InstanceURL) == "" {
+		return perr.BadRequestWithMessage("instance_url is required to verify a servicenow connection")
+	}
+	req, err := newVerifyRequest(ctx, strings.TrimSuffix(*c.InstanceURL, "/")+"/api/now/table/sys_user?sysparm_limit=1")
+	if err != nil {
+		return err
+	}
+	if c.OAuth2 != nil {
+		if err := c.OAuth2.setAuthorization(req); err != nil {
+			return err
+		}
+	} else {
+		req.SetBasicAuth(typehelpers.SafeString(c.Username), typehelpers.SafeString(c.Password))
+	}
+	return verifyHttpRequest(ctx, req, nil)
 }
@@ -6,16 +6,21 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/pipe-fittings/perr"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
 )
 
-const SlackConnectionType = "slack"
+const (
+	SlackConnectionType = "slack"
+	slackVerifyUrl      = "https://slack.com/api/auth.test"
+)
 
 type SlackConnection struct {
 	ConnectionImpl
 
-	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional"`
+	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional" secret:"true"`
 }
 
 func NewSlackConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -28,6 +33,11 @@ func (c *SlackConnection) GetConnectionType() string {
 }
 
 func (c *SlackConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &SlackConnection{ConnectionImpl: c.ConnectionImpl})
@@ -95,3 +105,25 @@ func (c *SlackConnection) GetEnv() map[string]cty.Value {
 	}
 	return env
 }
+
+// Verify calls the Slack auth.test API to check the token
+func (c *SlackConnection) Verify(ctx context.Context) error {
+	req, err := newVerifyRequest(ctx, slackVerifyUrl)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+typehelpers.SafeString(c.Token))
+
+	// slack returns a 200 status code for invalid tokens, with ok set to false
+	var resp struct {
+		Ok    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := verifyHttpRequest(ctx, req, &resp); err != nil {
+		return err
+	}
+	if !resp.Ok {
+		return perr.UnauthorizedWithMessage("slack auth.test failed: " + resp.Error)
+	}
+	return nil
+}
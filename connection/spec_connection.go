@@ -0,0 +1,387 @@
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/cty_helpers"
+	"github.com/turbot/pipe-fittings/sanitize"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// ConnectionSpec declares a connection type and its attributes.
+//
+// A SpecConnection implements all connection behaviour (decoding, resolving, validation, equality, env and cty
+// conversion) from the spec, so a simple connection type can be added by declaring a spec rather than writing
+// a connection struct. Register the type using the NewConnection method of the spec as the connection func.
+type ConnectionSpec struct {
+	Type       string
+	Attributes []ConnectionAttributeSpec
+	// groups of attributes of which at most one may be set
+	MutuallyExclusive [][]string
+}
+
+type ConnectionAttributeSpec struct {
+	Name string
+	// the type of the attribute value - if not set, the attribute is a string
+	Type cty.Type
+	// whether the attribute value is a secret, e.g. a token or api key - secret attributes must be strings
+	Secret bool
+	// if the attribute is not set, Resolve sets it from the first of these env vars which is set (converted to
+	// the attribute type), or to an empty string if none are set
+	EnvVars []string
+	// GetEnv exports the attribute value as these env vars - values which cannot be converted to a string,
+	// e.g. lists, are not exported
+	ExportEnvVars []string
+}
+
+// ctyType returns the type of the attribute value
+func (a ConnectionAttributeSpec) ctyType() cty.Type {
+	if a.Type == cty.NilType {
+		return cty.String
+	}
+	return a.Type
+}
+
+// NewConnection creates an empty connection of the spec type - this is a ConnectionFunc for the spec type
+func (s *ConnectionSpec) NewConnection(shortName string, declRange hcl.Range) PipelingConnection {
+	return &SpecConnection{
+		ConnectionImpl: NewConnectionImpl(s.Type, shortName, declRange),
+		spec:           s,
+		values:         make(map[string]cty.Value),
+	}
+}
+
+func (s *ConnectionSpec) attribute(name string) (ConnectionAttributeSpec, bool) {
+	for _, a := range s.Attributes {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return ConnectionAttributeSpec{}, false
+}
+
+// SpecConnection is a connection whose behaviour is defined by a ConnectionSpec
+type SpecConnection struct {
+	ConnectionImpl
+
+	spec *ConnectionSpec
+	// map of attribute name to value - unset attributes have no entry
+	values map[string]cty.Value
+}
+
+func (c *SpecConnection) GetConnectionType() string {
+	return c.spec.Type
+}
+
+// GetSpec returns the spec of the connection type
+func (c *SpecConnection) GetSpec() *ConnectionSpec {
+	return c.spec
+}
+
+// GetAttribute returns the value of the attribute, and whether it is set
+func (c *SpecConnection) GetAttribute(name string) (cty.Value, bool) {
+	value, ok := c.values[name]
+	return value, ok
+}
+
+// SetAttribute sets the value of the attribute, converted to the attribute type - this fails if the attribute
+// is not defined by the spec or the value cannot be converted. Setting a null value unsets the attribute
+func (c *SpecConnection) SetAttribute(name string, value cty.Value) error {
+	a, ok := c.spec.attribute(name)
+	if !ok {
+		return fmt.Errorf("connection type %s has no attribute %s", c.spec.Type, name)
+	}
+	if value.IsNull() {
+		delete(c.values, name)
+		return nil
+	}
+	converted, err := convert.Convert(value, a.ctyType())
+	if err != nil || !converted.IsWhollyKnown() {
+		return fmt.Errorf("invalid value for %s - a %s is required", name, a.ctyType().FriendlyName())
+	}
+	c.values[name] = converted
+	return nil
+}
+
+// IsSecret returns whether the attribute is declared as a secret by the spec
+func (c *SpecConnection) IsSecret(name string) bool {
+	a, ok := c.spec.attribute(name)
+	return ok && a.Secret
+}
+
+func (c *SpecConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	// if pipes metadata is set, call pipes to retrieve the creds
+	if c.Pipes != nil {
+		target := c.spec.NewConnection(c.ShortName, c.DeclRange.HclRange()).(*SpecConnection)
+		target.ConnectionImpl = c.ConnectionImpl
+		return c.Pipes.Resolve(ctx, target)
+	}
+
+	// Don't modify existing connection, resolve to a new one
+	newConnection := c.clone()
+	for _, a := range c.spec.Attributes {
+		if _, ok := newConnection.values[a.Name]; ok || len(a.EnvVars) == 0 {
+			continue
+		}
+		value := envVarValue(a.EnvVars)
+		// an unset env var leaves an attribute of another type unset, rather than failing to convert
+		if value == "" && a.ctyType() != cty.String {
+			continue
+		}
+		if err := newConnection.SetAttribute(a.Name, cty.StringVal(value)); err != nil {
+			return nil, fmt.Errorf("failed to resolve %s for connection %s from the environment: %w", a.Name, c.Name(), err)
+		}
+	}
+
+	return newConnection, nil
+}
+
+// resolveSecretRefs implements secretRefResolver - the secret references in the values of the secret attributes
+// are dereferenced
+func (c *SpecConnection) resolveSecretRefs(ctx context.Context) (PipelingConnection, error) {
+	var newConnection *SpecConnection
+	for _, a := range c.spec.Attributes {
+		value, ok := c.values[a.Name]
+		if !ok || !a.Secret || value.Type() != cty.String || !IsSecretRef(value.AsString()) {
+			continue
+		}
+		secret, err := ResolveSecretRef(ctx, value.AsString())
+		if err != nil {
+			return nil, secretRefError(err, fmt.Sprintf("failed to resolve %s for connection %s", a.Name, c.Name()))
+		}
+		if newConnection == nil {
+			newConnection = c.clone()
+		}
+		newConnection.values[a.Name] = cty.StringVal(secret)
+	}
+	if newConnection == nil {
+		return c, nil
+	}
+	return newConnection, nil
+}
+
+func (c *SpecConnection) Equals(otherConnection PipelingConnection) bool {
+	// If both pointers are nil, they are considered equal
+	if c == nil && helpers.IsNil(otherConnection) {
+		return true
+	}
+
+	if (c == nil && !helpers.IsNil(otherConnection)) || (c != nil && helpers.IsNil(otherConnection)) {
+		return false
+	}
+
+	other, ok := otherConnection.(*SpecConnection)
+	if !ok {
+		return false
+	}
+
+	if c.GetConnectionType() != other.GetConnectionType() || !maps.EqualFunc(c.values, other.values, cty.Value.RawEquals) {
+		return false
+	}
+
+	return c.GetConnectionImpl().Equals(otherConnection.GetConnectionImpl())
+}
+
+func (c *SpecConnection) Validate() hcl.Diagnostics {
+	if c.Pipes != nil && len(c.values) > 0 {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "if pipes block is defined, no other auth properties should be set",
+				Subject:  c.DeclRange.HclRangePointer(),
+			},
+		}
+	}
+
+	var diags hcl.Diagnostics
+	for _, group := range c.spec.MutuallyExclusive {
+		var set []string
+		for _, name := range group {
+			if _, ok := c.values[name]; ok {
+				set = append(set, name)
+			}
+		}
+		if len(set) > 1 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("only one of %s may be set", strings.Join(group, ", ")),
+				Subject:  c.DeclRange.HclRangePointer(),
+			})
+		}
+	}
+	return diags
+}
+
+func (c *SpecConnection) CtyValue() (cty.Value, error) {
+	baseCtyValue, err := cty_helpers.GetCtyValue(c.GetConnectionImpl())
+	if err != nil {
+		return cty.NilVal, err
+	}
+
+	valueMap := baseCtyValue.AsValueMap()
+	for _, a := range c.spec.Attributes {
+		if value, ok := c.values[a.Name]; ok {
+			valueMap[a.Name] = value
+		} else {
+			valueMap[a.Name] = cty.NullVal(a.ctyType())
+		}
+	}
+
+	valueMap["env"] = cty.ObjectVal(c.GetEnv())
+	valueMap["type"] = cty.StringVal(c.GetConnectionType())
+	valueMap["resource_type"] = cty.StringVal("connection")
+	return cty.ObjectVal(valueMap), nil
+}
+
+func (c *SpecConnection) GetEnv() map[string]cty.Value {
+	env := map[string]cty.Value{}
+	for _, a := range c.spec.Attributes {
+		value, ok := c.values[a.Name]
+		if !ok || len(a.ExportEnvVars) == 0 {
+			continue
+		}
+		strVal, err := convert.Convert(value, cty.String)
+		if err != nil {
+			continue
+		}
+		for _, envVar := range a.ExportEnvVars {
+			env[envVar] = strVal
+		}
+	}
+	return env
+}
+
+// DecodeBody implements BodyDecoder, decoding the attributes defined by the spec
+func (c *SpecConnection) DecodeBody(body hcl.Body, evalCtx *hcl.EvalContext) hcl.Diagnostics {
+	schema := &hcl.BodySchema{}
+	for _, a := range c.spec.Attributes {
+		schema.Attributes = append(schema.Attributes, hcl.AttributeSchema{Name: a.Name})
+	}
+
+	content, diags := body.Content(schema)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	for name, attr := range content.Attributes {
+		val, moreDiags := attr.Expr.Value(evalCtx)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() || val.IsNull() {
+			continue
+		}
+		if err := c.SetAttribute(name, val); err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  err.Error(),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+	return diags
+}
+
+// FromCtyValue implements CtyValueDecoder, decoding the attributes defined by the spec
+func (c *SpecConnection) FromCtyValue(value cty.Value) error {
+	for name, val := range value.AsValueMap() {
+		if err := c.SetAttribute(name, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalJSON serialises the connection as a single object containing the ConnectionImpl fields
+// and the attribute values
+func (c *SpecConnection) MarshalJSON() ([]byte, error) {
+	implJson, err := json.Marshal(c.ConnectionImpl)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]any)
+	if err := json.Unmarshal(implJson, &res); err != nil {
+		return nil, err
+	}
+	for name, value := range c.values {
+		valueJson, err := ctyjson.Marshal(value, value.Type())
+		if err != nil {
+			return nil, err
+		}
+		res[name] = json.RawMessage(valueJson)
+	}
+	return json.Marshal(res)
+}
+
+// UnmarshalJSON populates the ConnectionImpl fields and the attribute values defined by the spec
+func (c *SpecConnection) UnmarshalJSON(data []byte) error {
+	if c.spec == nil {
+		return fmt.Errorf("cannot unmarshal a connection with no spec - create the connection using ConnectionSpec.NewConnection")
+	}
+	if err := json.Unmarshal(data, &c.ConnectionImpl); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if c.values == nil {
+		c.values = make(map[string]cty.Value)
+	}
+	for _, a := range c.spec.Attributes {
+		raw, ok := fields[a.Name]
+		if !ok || string(raw) == "null" {
+			continue
+		}
+		value, err := ctyjson.Unmarshal(raw, a.ctyType())
+		if err != nil {
+			return fmt.Errorf("invalid value for %s - a %s is required", a.Name, a.ctyType().FriendlyName())
+		}
+		c.values[a.Name] = value
+	}
+	return nil
+}
+
+// Redacted implements sanitize.Redactable, returning a copy of the connection with the values of the
+// secret attributes redacted
+func (c *SpecConnection) Redacted() any {
+	res := c.clone()
+	for name, value := range res.values {
+		if c.IsSecret(name) && value.Type() == cty.String && value.AsString() != "" {
+			res.values[name] = cty.StringVal(sanitize.RedactedStr)
+		}
+	}
+	return res
+}
+
+func (c *SpecConnection) clone() *SpecConnection {
+	return &SpecConnection{
+		ConnectionImpl: c.ConnectionImpl,
+		spec:           c.spec,
+		values:         maps.Clone(c.values),
+	}
+}
+
+// envVarValue returns the value of the first of the env vars which is set, or an empty string if none are set
+func envVarValue(envVars []string) string {
+	idx := slices.IndexFunc(envVars, func(envVar string) bool {
+		return os.Getenv(envVar) != ""
+	})
+	if idx == -1 {
+		return ""
+	}
+	return os.Getenv(envVars[idx])
+}
@@ -0,0 +1,254 @@
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var testConnectionSpec = &ConnectionSpec{
+	Type: "test",
+	Attributes: []ConnectionAttributeSpec{
+		{Name: "token", Secret: true, EnvVars: []string{"TEST_CONN_TOKEN"}, ExportEnvVars: []string{"TEST_CONN_TOKEN"}},
+		{Name: "api_key", Secret: true},
+		{Name: "region", ExportEnvVars: []string{"TEST_CONN_REGION", "TEST_CONN_DEFAULT_REGION"}},
+		{Name: "port", Type: cty.Number, EnvVars: []string{"TEST_CONN_PORT"}, ExportEnvVars: []string{"TEST_CONN_PORT"}},
+		{Name: "scopes", Type: cty.List(cty.String), ExportEnvVars: []string{"TEST_CONN_SCOPES"}},
+	},
+	MutuallyExclusive: [][]string{{"token", "api_key"}},
+}
+
+func decodeTestSpecConnection(t *testing.T, config string) (*SpecConnection, hcl.Diagnostics) {
+	t.Helper()
+	file, diags := hclsyntax.ParseConfig([]byte(config), "test.fpc", hcl.InitialPos)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	conn := testConnectionSpec.NewConnection("default", hcl.Range{}).(*SpecConnection)
+	diags = conn.DecodeBody(file.Body, &hcl.EvalContext{})
+	return conn, append(diags, conn.Validate()...)
+}
+
+func TestSpecConnectionDecodeBody(t *testing.T) {
+	conn, diags := decodeTestSpecConnection(t, `
+token  = "abc"
+region = "us-east-1"
+port   = "8080"
+scopes = ["read", "write"]
+`)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	token, ok := conn.GetAttribute("token")
+	assert.True(t, ok)
+	assert.Equal(t, cty.StringVal("abc"), token)
+	// values are converted to the attribute type
+	port, _ := conn.GetAttribute("port")
+	assert.True(t, cty.NumberIntVal(8080).RawEquals(port))
+	scopes, _ := conn.GetAttribute("scopes")
+	assert.Equal(t, cty.ListVal([]cty.Value{cty.StringVal("read"), cty.StringVal("write")}), scopes)
+	_, ok = conn.GetAttribute("api_key")
+	assert.False(t, ok)
+	assert.True(t, conn.IsSecret("token"))
+	assert.False(t, conn.IsSecret("region"))
+
+	assert.Equal(t, map[string]cty.Value{
+		"TEST_CONN_TOKEN":          cty.StringVal("abc"),
+		"TEST_CONN_REGION":         cty.StringVal("us-east-1"),
+		"TEST_CONN_DEFAULT_REGION": cty.StringVal("us-east-1"),
+		"TEST_CONN_PORT":           cty.StringVal("8080"),
+	}, conn.GetEnv())
+
+	// values which cannot be converted to the attribute type are rejected
+	_, diags = decodeTestSpecConnection(t, `port = "abc"`)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, "invalid value for port - a number is required", diags[0].Summary)
+
+	// unknown attributes are rejected
+	_, diags = decodeTestSpecConnection(t, `username = "abc"`)
+	assert.True(t, diags.HasErrors())
+
+	// mutually exclusive attributes
+	_, diags = decodeTestSpecConnection(t, `
+token   = "abc"
+api_key = "def"
+`)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, "only one of token, api_key may be set", diags[0].Summary)
+}
+
+func TestSpecConnectionResolve(t *testing.T) {
+	t.Setenv("TEST_CONN_TOKEN", "env_token")
+	t.Setenv("TEST_CONN_API_KEY", "env_api_key")
+	t.Setenv("TEST_CONN_PORT", "5432")
+
+	conn, diags := decodeTestSpecConnection(t, `api_key = "env://TEST_CONN_API_KEY"`)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	resolved, err := conn.Resolve(context.Background())
+	require.NoError(t, err)
+	resolvedSpecConnection := resolved.(*SpecConnection)
+
+	// the env var fallback is used for the unset token, and the secret reference is dereferenced
+	token, _ := resolvedSpecConnection.GetAttribute("token")
+	assert.Equal(t, cty.StringVal("env_token"), token)
+	apiKey, _ := resolvedSpecConnection.GetAttribute("api_key")
+	assert.Equal(t, cty.StringVal("env_api_key"), apiKey)
+	// env var fallbacks are converted to the attribute type
+	port, _ := resolvedSpecConnection.GetAttribute("port")
+	assert.True(t, cty.NumberIntVal(5432).RawEquals(port))
+	// region has no env var fallback
+	_, ok := resolvedSpecConnection.GetAttribute("region")
+	assert.False(t, ok)
+
+	// the original connection is not modified
+	_, ok = conn.GetAttribute("token")
+	assert.False(t, ok)
+
+	// an env var which cannot be converted to the attribute type is an error
+	t.Setenv("TEST_CONN_PORT", "abc")
+	_, err = conn.Resolve(context.Background())
+	assert.Error(t, err)
+}
+
+func TestSpecConnectionSerialisation(t *testing.T) {
+	conn, diags := decodeTestSpecConnection(t, `
+token  = "abc"
+region = "us-east-1"
+port   = 8080
+scopes = ["read"]
+`)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	// cty
+	ctyValue, err := conn.CtyValue()
+	require.NoError(t, err)
+	valueMap := ctyValue.AsValueMap()
+	assert.Equal(t, cty.StringVal("abc"), valueMap["token"])
+	assert.True(t, valueMap["api_key"].IsNull())
+	assert.True(t, cty.NumberIntVal(8080).RawEquals(valueMap["port"]))
+	assert.Equal(t, cty.StringVal("test"), valueMap["type"])
+
+	fromCty := testConnectionSpec.NewConnection("default", hcl.Range{}).(*SpecConnection)
+	require.NoError(t, fromCty.FromCtyValue(cty.ObjectVal(map[string]cty.Value{
+		"token":   valueMap["token"],
+		"api_key": valueMap["api_key"],
+		"region":  valueMap["region"],
+		"port":    valueMap["port"],
+		"scopes":  valueMap["scopes"],
+	})))
+	assert.True(t, conn.Equals(fromCty))
+
+	// json
+	jsonBytes, err := json.Marshal(conn)
+	require.NoError(t, err)
+	fromJson := testConnectionSpec.NewConnection("", hcl.Range{}).(*SpecConnection)
+	require.NoError(t, json.Unmarshal(jsonBytes, fromJson))
+	assert.True(t, conn.Equals(fromJson))
+}
+
+// legacyAbuseIPDBConnection is the struct implementation of the abuseipdb connection type, before it was
+// migrated to AbuseIPDBConnectionSpec - it is used to check the spec connection behaves the same
+type legacyAbuseIPDBConnection struct {
+	ConnectionImpl
+
+	APIKey *string `json:"api_key,omitempty" cty:"api_key" hcl:"api_key,optional" secret:"true"`
+}
+
+func (c *legacyAbuseIPDBConnection) GetConnectionType() string {
+	return AbuseIPDBConnectionType
+}
+
+func (c *legacyAbuseIPDBConnection) Resolve(context.Context) (PipelingConnection, error) {
+	if c.APIKey == nil {
+		apiKey := os.Getenv("ABUSEIPDB_API_KEY")
+		return &legacyAbuseIPDBConnection{ConnectionImpl: c.ConnectionImpl, APIKey: &apiKey}, nil
+	}
+	return c, nil
+}
+
+func (c *legacyAbuseIPDBConnection) Equals(otherConnection PipelingConnection) bool {
+	if c == nil && helpers.IsNil(otherConnection) {
+		return true
+	}
+	if (c == nil && !helpers.IsNil(otherConnection)) || (c != nil && helpers.IsNil(otherConnection)) {
+		return false
+	}
+	other, ok := otherConnection.(*legacyAbuseIPDBConnection)
+	if !ok || !utils.PtrEqual(c.APIKey, other.APIKey) {
+		return false
+	}
+	return c.GetConnectionImpl().Equals(otherConnection.GetConnectionImpl())
+}
+
+func (c *legacyAbuseIPDBConnection) Validate() hcl.Diagnostics {
+	if c.Pipes != nil && c.APIKey != nil {
+		return hcl.Diagnostics{{Severity: hcl.DiagError, Summary: "if pipes block is defined, no other auth properties should be set"}}
+	}
+	return hcl.Diagnostics{}
+}
+
+func (c *legacyAbuseIPDBConnection) CtyValue() (cty.Value, error) {
+	return ctyValueForConnection(c)
+}
+
+func (c *legacyAbuseIPDBConnection) GetEnv() map[string]cty.Value {
+	return nil
+}
+
+func TestAbuseIPDBConnectionSpecEquivalence(t *testing.T) {
+	parse := func(config string) (*legacyAbuseIPDBConnection, *SpecConnection) {
+		file, diags := hclsyntax.ParseConfig([]byte(config), "test.fpc", hcl.InitialPos)
+		require.False(t, diags.HasErrors(), diags.Error())
+
+		legacy := &legacyAbuseIPDBConnection{ConnectionImpl: NewConnectionImpl(AbuseIPDBConnectionType, "default", hcl.Range{})}
+		diags = gohcl.DecodeBody(file.Body, &hcl.EvalContext{}, legacy)
+		require.False(t, diags.HasErrors(), diags.Error())
+
+		spec := NewAbuseIPDBConnection("default", hcl.Range{}).(*SpecConnection)
+		diags = spec.DecodeBody(file.Body, &hcl.EvalContext{})
+		require.False(t, diags.HasErrors(), diags.Error())
+		return legacy, spec
+	}
+	assertEquivalent := func(legacy PipelingConnection, spec PipelingConnection) {
+		t.Helper()
+		legacyCty, err := legacy.CtyValue()
+		require.NoError(t, err)
+		specCty, err := spec.CtyValue()
+		require.NoError(t, err)
+		assert.True(t, legacyCty.RawEquals(specCty), "cty values differ: %#v, %#v", legacyCty, specCty)
+		assert.Equal(t, len(legacy.GetEnv()), len(spec.GetEnv()))
+		assert.Equal(t, len(legacy.Validate()), len(spec.Validate()))
+	}
+
+	t.Setenv("ABUSEIPDB_API_KEY", "env_api_key")
+	for _, config := range []string{`api_key = "abc123"`, ``} {
+		legacy, spec := parse(config)
+		assertEquivalent(legacy, spec)
+
+		legacyResolved, err := legacy.Resolve(context.Background())
+		require.NoError(t, err)
+		specResolved, err := spec.Resolve(context.Background())
+		require.NoError(t, err)
+		assertEquivalent(legacyResolved, specResolved)
+	}
+
+	// connections are equal only if their api keys are equal
+	legacy1, spec1 := parse(`api_key = "abc123"`)
+	legacy2, spec2 := parse(`api_key = "abc123"`)
+	legacy3, spec3 := parse(`api_key = "def456"`)
+	legacy4, spec4 := parse(``)
+	assert.Equal(t, legacy1.Equals(legacy2), spec1.Equals(spec2))
+	assert.Equal(t, legacy1.Equals(legacy3), spec1.Equals(spec3))
+	assert.Equal(t, legacy1.Equals(legacy4), spec1.Equals(spec4))
+	assert.True(t, spec1.Equals(spec2))
+	assert.False(t, spec1.Equals(spec3))
+}
@@ -3,6 +3,7 @@ package connection
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
 
 	"github.com/hashicorp/hcl/v2"
@@ -16,6 +17,11 @@ const SqliteConnectionType = "sqlite"
 type SqliteConnection struct {
 	ConnectionImpl
 	FileName *string `json:"file_name,omitempty" cty:"file_name" hcl:"file_name,optional"`
+	// databases to attach, in the form [alias=]path
+	Attach *[]string `json:"attach,omitempty" cty:"attach" hcl:"attach,optional"`
+	// databases to search for unqualified table names, i.e. main or an attached database alias
+	SearchPath       *[]string `json:"search_path,omitempty" cty:"search_path" hcl:"search_path,optional"`
+	SearchPathPrefix *[]string `json:"search_path_prefix,omitempty" cty:"search_path_prefix" hcl:"search_path_prefix,optional"`
 	// used only to set the connection string from command line variable value with a connection string
 	ConnectionString *string `json:"connection_string,omitempty" cty:"connection_string"`
 }
@@ -30,6 +36,11 @@ func (c *SqliteConnection) GetConnectionType() string {
 }
 
 func (c *SqliteConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &SqliteConnection{ConnectionImpl: c.ConnectionImpl})
@@ -57,6 +68,20 @@ func (c *SqliteConnection) GetEnv() map[string]cty.Value {
 	return map[string]cty.Value{}
 }
 
+func (c *SqliteConnection) GetSearchPath() []string {
+	if c.SearchPath != nil {
+		return *c.SearchPath
+	}
+	return []string{}
+}
+
+func (c *SqliteConnection) GetSearchPathPrefix() []string {
+	if c.SearchPathPrefix != nil {
+		return *c.SearchPathPrefix
+	}
+	return []string{}
+}
+
 func (c *SqliteConnection) Equals(otherConnection PipelingConnection) bool {
 	// If both pointers are nil, they are considered equal
 	if c == nil && helpers.IsNil(otherConnection) {
@@ -72,7 +97,10 @@ func (c *SqliteConnection) Equals(otherConnection PipelingConnection) bool {
 		return false
 	}
 
-	return utils.PtrEqual(c.FileName, other.FileName)
+	return utils.PtrEqual(c.FileName, other.FileName) &&
+		utils.SlicePtrEqual(c.Attach, other.Attach) &&
+		utils.SlicePtrEqual(c.SearchPath, other.SearchPath) &&
+		utils.SlicePtrEqual(c.SearchPathPrefix, other.SearchPathPrefix)
 
 }
 
@@ -85,7 +113,11 @@ func (c *SqliteConnection) GetConnectionString() string {
 		return *c.ConnectionString
 	}
 
-	return fmt.Sprintf("sqlite://%s", c.getFileName())
+	connString := fmt.Sprintf("sqlite://%s", c.getFileName())
+	if c.Attach != nil && len(*c.Attach) > 0 {
+		connString += "?" + url.Values{"attach": *c.Attach}.Encode()
+	}
+	return connString
 }
 
 func (c *SqliteConnection) getFileName() any {
@@ -94,3 +126,8 @@ func (c *SqliteConnection) getFileName() any {
 	}
 	return os.Getenv("DUCKDB_FILENAME")
 }
+
+// Verify connects to the database and runs a test query
+func (c *SqliteConnection) Verify(ctx context.Context) error {
+	return verifyDatabaseConnection(ctx, c.GetConnectionString())
+}
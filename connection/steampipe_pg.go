@@ -23,11 +23,11 @@ type SteampipePgConnection struct {
 	UserName         *string   `json:"username,omitempty" cty:"username" hcl:"username,optional"`
 	Host             *string   `json:"host,omitempty" cty:"host" hcl:"host,optional"`
 	Port             *int      `json:"port,omitempty" cty:"port" hcl:"port,optional"`
-	Password         *string   `json:"password,omitempty" cty:"password" hcl:"password,optional"`
+	Password         *string   `json:"password,omitempty" cty:"password" hcl:"password,optional" secret:"true"`
 	SearchPath       *[]string `json:"search_path,omitempty" cty:"search_path" hcl:"search_path,optional"`
 	SearchPathPrefix *[]string `json:"search_path_prefix,omitempty" cty:"search_path_prefix" hcl:"search_path_prefix,optional"`
 	SslMode          *string   `json:"sslmode,omitempty" cty:"sslmode" hcl:"sslmode,optional"`
-	ConnectionString *string   `json:"connection_string,omitempty" cty:"connection_string"`
+	ConnectionString *string   `json:"connection_string,omitempty" cty:"connection_string" secret:"true"`
 }
 
 func NewSteampipePgConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -40,6 +40,11 @@ func (c *SteampipePgConnection) GetConnectionType() string {
 }
 
 func (c *SteampipePgConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &SteampipePgConnection{ConnectionImpl: c.ConnectionImpl})
@@ -94,23 +99,23 @@ func (c *SteampipePgConnection) GetConnectionString() string {
 		return *c.ConnectionString
 	}
 	// db, username, host and port all have default values if not set
-	return buildPostgresConnectionString(
-		c.getDbName(),
-		c.getUserName(),
-		c.getHost(),
-		c.getPort(),
-
-		c.Password, c.SslMode)
+	return buildPostgresConnectionString(c.connectionParams())
 }
 
 func (c *SteampipePgConnection) GetEnv() map[string]cty.Value {
 	// db, username, host and port all have default values if not set
-	return postgresConnectionParamsToEnvValueMap(c.getDbName(),
-		c.getUserName(),
-		c.getHost(),
-		c.getPort(),
-		c.Password,
-		c.SslMode)
+	return postgresConnectionParamsToEnvValueMap(c.connectionParams())
+}
+
+func (c *SteampipePgConnection) connectionParams() postgresConnectionParams {
+	return postgresConnectionParams{
+		db:       c.getDbName(),
+		user:     c.getUserName(),
+		hosts:    []string{c.getHost()},
+		ports:    []int{c.getPort()},
+		password: c.Password,
+		sslMode:  c.SslMode,
+	}
 }
 
 func (c *SteampipePgConnection) GetSearchPath() []string {
@@ -189,3 +194,8 @@ func (c *SteampipePgConnection) getPort() int {
 	}
 	return defaultSteampipePort
 }
+
+// Verify connects to the database and runs a test query
+func (c *SteampipePgConnection) Verify(ctx context.Context) error {
+	return verifyDatabaseConnection(ctx, c.GetConnectionString())
+}
@@ -15,8 +15,8 @@ const TrelloConnectionType = "trello"
 type TrelloConnection struct {
 	ConnectionImpl
 
-	APIKey *string `json:"api_key,omitempty" cty:"api_key" hcl:"api_key,optional"`
-	Token  *string `json:"token,omitempty" cty:"token" hcl:"token,optional"`
+	APIKey *string `json:"api_key,omitempty" cty:"api_key" hcl:"api_key,optional" secret:"true"`
+	Token  *string `json:"token,omitempty" cty:"token" hcl:"token,optional" secret:"true"`
 }
 
 func NewTrelloConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -29,6 +29,11 @@ func (c *TrelloConnection) GetConnectionType() string {
 }
 
 func (c *TrelloConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &TrelloConnection{ConnectionImpl: c.ConnectionImpl})
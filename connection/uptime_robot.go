@@ -15,7 +15,7 @@ const UptimeRobotConnectionType = "uptimerobot"
 type UptimeRobotConnection struct {
 	ConnectionImpl
 
-	APIKey *string `json:"api_key,omitempty" cty:"api_key" hcl:"api_key,optional"`
+	APIKey *string `json:"api_key,omitempty" cty:"api_key" hcl:"api_key,optional" secret:"true"`
 }
 
 func NewUptimeRobotConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -28,6 +28,11 @@ func (c *UptimeRobotConnection) GetConnectionType() string {
 }
 
 func (c *UptimeRobotConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &UptimeRobotConnection{ConnectionImpl: c.ConnectionImpl})
@@ -16,7 +16,7 @@ type VaultConnection struct {
 	ConnectionImpl
 
 	Address *string `json:"address,omitempty" cty:"address" hcl:"address,optional"`
-	Token   *string `json:"token,omitempty" cty:"token" hcl:"token,optional"`
+	Token   *string `json:"token,omitempty" cty:"token" hcl:"token,optional" secret:"true"`
 }
 
 func NewVaultConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -29,6 +29,11 @@ func (c *VaultConnection) GetConnectionType() string {
 }
 
 func (c *VaultConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &VaultConnection{ConnectionImpl: c.ConnectionImpl})
@@ -0,0 +1,95 @@
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/turbot/pipe-fittings/backend"
+	"github.com/turbot/pipe-fittings/contexthelpers"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+// Verifiable is implemented by connections which can verify that they work, e.g. that the database can be queried
+// or that the credentials are accepted by the service. Verify should be called on a resolved connection
+type Verifiable interface {
+	Verify(ctx context.Context) error
+}
+
+// HttpClient is the interface of the http client used to verify SaaS connections
+type HttpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+var contextKeyVerifyHttpClient = contexthelpers.ContextKey("verify_http_client")
+
+var defaultVerifyHttpClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+// AddVerifyHttpClientToContext sets the http client used by Verify to call SaaS APIs
+func AddVerifyHttpClientToContext(ctx context.Context, client HttpClient) context.Context {
+	return context.WithValue(ctx, contextKeyVerifyHttpClient, client)
+}
+
+func verifyHttpClientFromContext(ctx context.Context) HttpClient {
+	if client, ok := ctx.Value(contextKeyVerifyHttpClient).(HttpClient); ok {
+		return client
+	}
+	return defaultVerifyHttpClient
+}
+
+// verifyDatabaseConnection connects to the database using the backend for the connection string and runs SELECT 1
+func verifyDatabaseConnection(ctx context.Context, connectionString string, opts ...backend.ConnectOption) error {
+	b, err := backend.FromConnectionString(ctx, connectionString, opts...)
+	if err != nil {
+		return err
+	}
+	db, err := b.Connect(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var res int
+	if err := db.QueryRowContext(ctx, "SELECT 1").Scan(&res); err != nil {
+		return perr.InternalWithMessage(fmt.Sprintf("failed to query %s database: %s", b.Name(), err.Error()))
+	}
+	return nil
+}
+
+// newVerifyRequest creates a GET request for the whoami endpoint of a SaaS API
+func newVerifyRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, perr.BadRequestWithMessage(fmt.Sprintf("failed to create verify request: %s", err.Error()))
+	}
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// verifyHttpRequest sends the request using the verify http client, returning an error if the response status
+// is not successful. If target is not nil, the response body is decoded into it
+func verifyHttpRequest(ctx context.Context, req *http.Request, target any) error {
+	resp, err := verifyHttpClientFromContext(ctx).Do(req)
+	if err != nil {
+		return perr.InternalWithMessage(fmt.Sprintf("failed to call %s: %s", req.URL.Host, err.Error()))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// drain the body so the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return perr.FromHttpError(fmt.Errorf("%s returned status code: %d", req.URL.Host, resp.StatusCode), resp.StatusCode)
+	}
+
+	if target != nil {
+		if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+			return perr.InternalWithMessage(fmt.Sprintf("failed to decode %s response body", req.URL.Host))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/utils"
+)
+
+// stubTransport sends all requests to the stub server, regardless of the request host
+type stubTransport struct {
+	serverUrl *url.URL
+}
+
+func (t stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.serverUrl.Scheme
+	req.URL.Host = t.serverUrl.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// stubAuthorised returns whether the request is authorised by the bearer token "valid_token", or if basicUsername
+// is set, by basic auth using the username and the password "valid_token"
+func stubAuthorised(r *http.Request, basicUsername string) bool {
+	if r.Header.Get("Authorization") == "Bearer valid_token" {
+		return true
+	}
+	username, password, ok := r.BasicAuth()
+	return basicUsername != "" && ok && username == basicUsername && password == "valid_token"
+}
+
+// newVerifyStub starts a stub server implementing the whoami endpoints used by Verify, which accept the token
+// "valid_token", and returns a context using an http client which sends all requests to the stub server
+func newVerifyStub(t *testing.T) context.Context {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer valid_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"login": "octocat"})
+	})
+	mux.HandleFunc("/api/auth.test", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer valid_token" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": "invalid_auth"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+	})
+	mux.HandleFunc("/rest/api/2/myself", func(w http.ResponseWriter, r *http.Request) {
+		if !stubAuthorised(r, "user@example.com") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"emailAddress": "user@example.com"})
+	})
+	mux.HandleFunc("/api/v1/users/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "SSWS valid_token" && !stubAuthorised(r, "") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "user"})
+	})
+	mux.HandleFunc("/api/v2/users/me.json", func(w http.ResponseWriter, r *http.Request) {
+		if !stubAuthorised(r, "user@example.com/token") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"user": map[string]any{"id": 1}})
+	})
+	mux.HandleFunc("/api/now/table/sys_user", func(w http.ResponseWriter, r *http.Request) {
+		if !stubAuthorised(r, "admin") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": []any{}})
+	})
+	mux.HandleFunc("/v1.0/organization", func(w http.ResponseWriter, r *http.Request) {
+		if !stubAuthorised(r, "") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"value": []any{}})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	serverUrl, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	client := &http.Client{Transport: stubTransport{serverUrl: serverUrl}}
+	return AddVerifyHttpClientToContext(context.Background(), client)
+}
+
+func TestVerifySaasConnections(t *testing.T) {
+	ctx := newVerifyStub(t)
+
+	tests := []struct {
+		name     string
+		conn     func(token string) Verifiable
+		wantType string
+	}{
+		{
+			name: "github",
+			conn: func(token string) Verifiable {
+				return &GithubConnection{Token: &token}
+			},
+			wantType: perr.ErrorCodeUnauthorized,
+		},
+		{
+			name: "slack",
+			conn: func(token string) Verifiable {
+				return &SlackConnection{Token: &token}
+			},
+			wantType: perr.ErrorCodeUnauthorized,
+		},
+		{
+			name: "jira",
+			conn: func(token string) Verifiable {
+				// the base url host is replaced with the stub server by the stub transport
+				return &JiraConnection{BaseURL: utils.ToStringPointer("https://example.atlassian.net/"), Username: utils.ToStringPointer("user@example.com"), APIToken: &token}
+			},
+			wantType: perr.ErrorCodeUnauthorized,
+		},
+		{
+			name: "okta",
+			conn: func(token string) Verifiable {
+				return &OktaConnection{Domain: utils.ToStringPointer("https://example.okta.com"), Token: &token}
+			},
+			wantType: perr.ErrorCodeUnauthorized,
+		},
+		{
+			name: "zendesk",
+			conn: func(token string) Verifiable {
+				return &ZendeskConnection{Subdomain: utils.ToStringPointer("example"), Email: utils.ToStringPointer("user@example.com"), Token: &token}
+			},
+			wantType: perr.ErrorCodeUnauthorized,
+		},
+		{
+			name: "servicenow",
+			conn: func(token string) Verifiable {
+				return &ServiceNowConnection{InstanceURL: utils.ToStringPointer("https://example.service-now.com"), Username: utils.ToStringPointer("admin"), Password: &token}
+			},
+			wantType: perr.ErrorCodeUnauthorized,
+		},
+		{
+			name: "teams",
+			conn: func(token string) Verifiable {
+				return &MicrosoftTeamsConnection{AccessToken: &token}
+			},
+			wantType: perr.ErrorCodeUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, tt.conn("valid_token").Verify(ctx))
+
+			err := tt.conn("invalid_token").Verify(ctx)
+			require.Error(t, err)
+			assert.Equal(t, tt.wantType, err.(perr.ErrorModel).Type)
+		})
+	}
+}
+
+func TestVerifyOAuth2Connections(t *testing.T) {
+	ctx := newVerifyStub(t)
+
+	// the connections have static credentials which the stub accepts, so the oauth2 access token must be used
+	// for an invalid access token to fail
+	validToken := "valid_token"
+	tests := map[string]func(oauth2 *OAuth2Config) Verifiable{
+		"jira": func(oauth2 *OAuth2Config) Verifiable {
+			return &JiraConnection{BaseURL: utils.ToStringPointer("https://example.atlassian.net"), Username: utils.ToStringPointer("user@example.com"), APIToken: &validToken, OAuth2: oauth2}
+		},
+		"okta": func(oauth2 *OAuth2Config) Verifiable {
+			return &OktaConnection{Domain: utils.ToStringPointer("https://example.okta.com"), Token: &validToken, OAuth2: oauth2}
+		},
+		"zendesk": func(oauth2 *OAuth2Config) Verifiable {
+			return &ZendeskConnection{Subdomain: utils.ToStringPointer("example"), Email: utils.ToStringPointer("user@example.com"), Token: &validToken, OAuth2: oauth2}
+		},
+		"servicenow": func(oauth2 *OAuth2Config) Verifiable {
+			return &ServiceNowConnection{InstanceURL: utils.ToStringPointer("https://example.service-now.com"), Username: utils.ToStringPointer("admin"), Password: &validToken, OAuth2: oauth2}
+		},
+		"teams": func(oauth2 *OAuth2Config) Verifiable {
+			return &MicrosoftTeamsConnection{AccessToken: &validToken, OAuth2: oauth2}
+		},
+	}
+	for name, conn := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, conn(&OAuth2Config{AccessToken: utils.ToStringPointer("valid_token")}).Verify(ctx))
+
+			err := conn(&OAuth2Config{AccessToken: utils.ToStringPointer("invalid_token")}).Verify(ctx)
+			require.Error(t, err)
+			assert.Equal(t, perr.ErrorCodeUnauthorized, err.(perr.ErrorModel).Type)
+
+			// the oauth2 block must be resolved
+			err = conn(&OAuth2Config{}).Verify(ctx)
+			require.Error(t, err)
+			assert.Equal(t, perr.ErrorCodeBadRequest, err.(perr.ErrorModel).Type)
+		})
+	}
+}
+
+func TestVerifyDatabaseConnection(t *testing.T) {
+	ctx := context.Background()
+
+	conn := NewSqliteConnection("default", hcl.Range{}).(*SqliteConnection)
+	conn.FileName = utils.ToStringPointer(filepath.Join(t.TempDir(), "test.db"))
+	assert.NoError(t, conn.Verify(ctx))
+
+	// a missing directory cannot be opened
+	conn.FileName = utils.ToStringPointer(filepath.Join(t.TempDir(), "missing", "test.db"))
+	assert.Error(t, conn.Verify(ctx))
+}
@@ -2,10 +2,13 @@ package connection
 
 import (
 	"context"
+	"fmt"
 	"os"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/pipe-fittings/perr"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -17,7 +20,12 @@ type ZendeskConnection struct {
 
 	Email     *string `json:"email,omitempty" cty:"email" hcl:"email,optional"`
 	Subdomain *string `json:"subdomain,omitempty" cty:"subdomain" hcl:"subdomain,optional"`
-	Token     *string `json:"token,omitempty" cty:"token" hcl:"token,optional"`
+	Token     *string `json:"token,omitempty" cty:"token" hcl:"token,optional" secret:"true"`
+
+	// if set, the oauth2 credentials are exchanged for an access token when the connection is resolved
+	OAuth2 *OAuth2Config `json:"oauth2,omitempty" cty:"oauth2" hcl:"oauth2,block"`
+	// the oauth2 access token is populated by Resolve
+	AccessToken *string `json:"access_token,omitempty" cty:"access_token" secret:"true"`
 }
 
 func NewZendeskConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -30,11 +38,28 @@ func (c *ZendeskConnection) GetConnectionType() string {
 }
 
 func (c *ZendeskConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
+	c, err := dereferenceSecretRefs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	// if pipes metadata is set, call pipes to retrieve the creds
 	if c.Pipes != nil {
 		return c.Pipes.Resolve(ctx, &ZendeskConnection{ConnectionImpl: c.ConnectionImpl})
 	}
 
+	// if an oauth2 block is defined, exchange the oauth2 credentials for an access token
+	if c.OAuth2 != nil {
+		newConnection := *c
+		oauth2, err := c.OAuth2.Resolve(ctx, &newConnection.ConnectionImpl)
+		if err != nil {
+			return nil, err
+		}
+		newConnection.OAuth2 = oauth2
+		newConnection.AccessToken = oauth2.AccessToken
+		return &newConnection, nil
+	}
+
 	if c.Subdomain == nil && c.Email == nil && c.Token == nil {
 		subdomainEnvVar := os.Getenv("ZENDESK_SUBDOMAIN")
 		emailEnvVar := os.Getenv("ZENDESK_EMAIL")
@@ -81,11 +106,15 @@ func (c *ZendeskConnection) Equals(otherConnection PipelingConnection) bool {
 		return false
 	}
 
+	if !c.OAuth2.Equals(other.OAuth2) {
+		return false
+	}
+
 	return c.GetConnectionImpl().Equals(otherConnection.GetConnectionImpl())
 }
 
 func (c *ZendeskConnection) Validate() hcl.Diagnostics {
-	if c.Pipes != nil && (c.Email != nil || c.Subdomain != nil || c.Token != nil) {
+	if c.Pipes != nil && (c.Email != nil || c.Subdomain != nil || c.Token != nil || c.OAuth2 != nil) {
 		return hcl.Diagnostics{
 			{
 				Severity: hcl.DiagError,
@@ -94,6 +123,9 @@ func (c *ZendeskConnection) Validate() hcl.Diagnostics {
 			},
 		}
 	}
+	if c.OAuth2 != nil {
+		return c.OAuth2.Validate(c.DeclRange.HclRangePointer())
+	}
 	return hcl.Diagnostics{}
 }
 
@@ -104,5 +136,28 @@ func (c *ZendeskConnection) CtyValue() (cty.Value, error) {
 }
 
 func (c *ZendeskConnection) GetEnv() map[string]cty.Value {
-	return nil
+	env := map[string]cty.Value{}
+	if c.AccessToken != nil {
+		env["ZENDESK_ACCESS_TOKEN"] = cty.StringVal(*c.AccessToken)
+	}
+	return env
+}
+
+// Verify calls the Zendesk API to get the authenticated user
+func (c *ZendeskConnection) Verify(ctx context.Context) error {
+	if typehelpers.SafeString(c.Subdomain) == "" {
+		return perr.BadRequestWithMessage("subdomain is required to verify a zendesk connection")
+	}
+	req, err := newVerifyRequest(ctx, fmt.Sprintf("https://%s.zendesk.com/api/v2/users/me.json", *c.Subdomain))
+	if err != nil {
+		return err
+	}
+	if c.OAuth2 != nil {
+		if err := c.OAuth2.setAuthorization(req); err != nil {
+			return err
+		}
+	} else {
+		req.SetBasicAuth(typehelpers.SafeString(c.Email)+"/token", typehelpers.SafeString(c.Token))
+	}
+	return verifyHttpRequest(ctx, req, nil)
 }
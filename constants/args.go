@@ -8,6 +8,7 @@ const (
 	ArgClear               = "clear"
 	ArgServicePassword     = "database-password"
 	ArgServiceShowPassword = "show-password"
+	ArgShowSecrets         = "show-secrets"
 	ArgSkipConfig          = "skip-config"
 	ArgForeground          = "foreground"
 	ArgInvoker             = "invoker"
@@ -4,4 +4,6 @@ const (
 	// TODO finalize this
 	// seconds
 	DefaultConnectionTtl = 3600
+	// the maximum duration of a connection resolve, in seconds
+	DefaultConnectionResolveTimeout = 60
 )
@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/sanitize"
 	"github.com/turbot/terraform-components/configs/configschema"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/gocty"
@@ -44,8 +45,26 @@ func GetCtyTypes(item interface{}) map[string]cty.Type {
 	return res
 }
 
+type ctyValueConfig struct {
+	redactSecrets bool
+}
+
+type CtyValueOption func(*ctyValueConfig)
+
+// WithRedactedSecrets redacts the values of fields tagged as secrets (see sanitize.SecretTag)
+func WithRedactedSecrets() CtyValueOption {
+	return func(c *ctyValueConfig) {
+		c.redactSecrets = true
+	}
+}
+
 // GetCtyValue converts the item into a cty value
-func GetCtyValue(item interface{}) (cty.Value, error) {
+func GetCtyValue(item interface{}, opts ...CtyValueOption) (cty.Value, error) {
+	var cfg ctyValueConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	// build the block schema
 	var block = configschema.Block{Attributes: make(map[string]*configschema.Attribute)}
 
@@ -59,5 +78,82 @@ func GetCtyValue(item interface{}) (cty.Value, error) {
 	spec := block.DecoderSpec()
 	ty := hcldec.ImpliedType(spec)
 
-	return gocty.ToCtyValue(item, ty)
+	val, err := gocty.ToCtyValue(item, ty)
+	if err != nil || !cfg.redactSecrets {
+		return val, err
+	}
+	return redactCtyValue(reflect.ValueOf(item), val), nil
+}
+
+// redactCtyValue replaces the values of the attributes of the cty object which correspond to secret fields
+// of the struct with sanitize.RedactedStr, recursing into nested structs
+func redactCtyValue(item reflect.Value, val cty.Value) cty.Value {
+	for item.Kind() == reflect.Pointer || item.Kind() == reflect.Interface {
+		if item.IsNil() {
+			return val
+		}
+		item = item.Elem()
+	}
+	if item.Kind() != reflect.Struct || val.IsNull() || !val.IsKnown() || !val.Type().IsObjectType() {
+		return val
+	}
+
+	valueMap := val.AsValueMap()
+	changed := false
+	for i := 0; i < item.NumField(); i++ {
+		structField := item.Type().Field(i)
+		attribute, ok := structField.Tag.Lookup("cty")
+		if !ok || attribute == "-" {
+			continue
+		}
+		attrVal, ok := valueMap[attribute]
+		if !ok {
+			continue
+		}
+		var redacted cty.Value
+		if sanitize.IsSecretField(structField) {
+			redacted = redactSecretCtyValue(attrVal)
+		} else {
+			redacted = redactCtyValue(item.Field(i), attrVal)
+		}
+		if redacted.RawEquals(attrVal) {
+			continue
+		}
+		valueMap[attribute] = redacted
+		changed = true
+	}
+	if !changed {
+		return val
+	}
+	return cty.ObjectVal(valueMap)
+}
+
+// redactSecretCtyValue redacts a secret value, which may be a string, or a collection of strings
+func redactSecretCtyValue(val cty.Value) cty.Value {
+	if val.IsNull() || !val.IsKnown() {
+		return val
+	}
+	ty := val.Type()
+	switch {
+	case ty == cty.String:
+		if val.AsString() == "" {
+			return val
+		}
+		return cty.StringVal(sanitize.RedactedStr)
+	case ty.IsListType(), ty.IsSetType(), ty.IsMapType():
+		if val.LengthInt() == 0 {
+			return val
+		}
+		redacted, err := cty.Transform(val, func(path cty.Path, v cty.Value) (cty.Value, error) {
+			if len(path) == 0 {
+				return v, nil
+			}
+			return redactSecretCtyValue(v), nil
+		})
+		if err != nil {
+			return val
+		}
+		return redacted
+	}
+	return val
 }
@@ -0,0 +1,380 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/exp/maps"
+)
+
+// ColumnType is the type of a column which a filter may refer to
+type ColumnType string
+
+const (
+	ColumnTypeString    ColumnType = "string"
+	ColumnTypeNumber    ColumnType = "number"
+	ColumnTypeBool      ColumnType = "bool"
+	ColumnTypeTimestamp ColumnType = "timestamp"
+	// json columns may be nested maps and slices, or strings containing JSON - jsonb selectors may only be
+	// applied to json columns
+	ColumnTypeJson ColumnType = "json"
+	// columns whose type is not known, e.g. as their values are always null, are not type checked
+	ColumnTypeAny ColumnType = "any"
+)
+
+// Schema is a map of the name to the type of the columns which a filter may refer to
+type Schema map[string]ColumnType
+
+// CompileError is an error in a filter, at a position in the filter source
+type CompileError struct {
+	Pos     Position
+	Message string
+	// if set, a suggested fix for the error, e.g. the name of a column similar to an unknown column
+	Suggestion string
+}
+
+func (e *CompileError) Error() string {
+	msg := fmt.Sprintf("line %d, column %d: %s", e.Pos.Line, e.Pos.Column, e.Message)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(" - did you mean %s?", e.Suggestion)
+	}
+	return msg
+}
+
+// CompileErrors is the list of errors in a filter
+type CompileErrors []*CompileError
+
+func (e CompileErrors) Error() string {
+	var messages []string
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Predicate is a compiled filter, which may be evaluated against any number of rows, or converted to SQL
+type Predicate struct {
+	source string
+	node   ComparisonNode
+	schema Schema
+	// the compiled like patterns, shared by every evaluation of the predicate
+	likeCache *likeCache
+}
+
+// Compile parses the filter and, if the schema is set, checks that the columns it refers to are in the schema and
+// the values compared are of compatible types. If the filter is invalid, the error is a CompileErrors
+// containing an error for each problem found
+func Compile(filter string, schema Schema) (*Predicate, error) {
+	parsed, err := Parse("", []byte(filter))
+	if err != nil {
+		return nil, parseErrorToCompileErrors(filter, err)
+	}
+	node, ok := parsed.(ComparisonNode)
+	if !ok {
+		return nil, CompileErrors{{Pos: Position{Line: 1, Column: 1}, Message: "invalid filter"}}
+	}
+
+	c := &compiler{schema: schema}
+	c.checkComparison(node)
+	if len(c.errors) > 0 {
+		return nil, c.errors
+	}
+
+	// evaluate the filter against an empty row, which reports any expressions which cannot be evaluated
+	if _, err := Evaluate(node, MapRow{}); err != nil {
+		return nil, CompileErrors{{Pos: Position{Line: 1, Column: 1}, Message: err.Error()}}
+	}
+
+	return &Predicate{source: filter, node: node, schema: schema, likeCache: newLikeCache()}, nil
+}
+
+// String returns the filter source
+func (p *Predicate) String() string {
+	return p.source
+}
+
+// Node returns the parsed filter
+func (p *Predicate) Node() ComparisonNode {
+	return p.node
+}
+
+// Evaluate evaluates the filter against the row
+func (p *Predicate) Evaluate(row Row, opts ...EvaluateOption) (bool, error) {
+	return Evaluate(p.node, row, append([]EvaluateOption{withLikeCache(p.likeCache)}, opts...)...)
+}
+
+// Match returns whether the row matches the filter - as the filter has been compiled, the evaluation
+// cannot fail, so any error is treated as not matching
+func (p *Predicate) Match(row Row, opts ...EvaluateOption) bool {
+	res, err := p.Evaluate(row, opts...)
+	return err == nil && res
+}
+
+// ToSQL converts the filter to parameterised SQL - if the predicate has a schema, the SQL may only refer to the
+// columns in the schema
+func (p *Predicate) ToSQL(opts ...SQLOption) (string, []any, error) {
+	if p.schema != nil {
+		opts = append([]SQLOption{WithAllowedIdentifiers(maps.Keys(p.schema))}, opts...)
+	}
+	return ComparisonToParameterisedSQL(p.node, opts...)
+}
+
+// suggestions for common mistakes, keyed by the unexpected text at the position of a parse error
+var parseErrorSuggestions = map[string]string{
+	"==": "'='",
+	"&&": "'and'",
+	"||": "'or'",
+	"!":  "'not'",
+	`"`:  "a single quoted string - double quotes are used for column names",
+}
+
+// parseErrorToCompileErrors converts the errors returned by Parse into a CompileErrors
+func parseErrorToCompileErrors(filter string, err error) CompileErrors {
+	var res CompileErrors
+	var errs errList
+	if !errors.As(err, &errs) {
+		return CompileErrors{{Pos: Position{Line: 1, Column: 1}, Message: err.Error()}}
+	}
+	for _, e := range errs {
+		var pe *parserError
+		if !errors.As(e, &pe) {
+			res = append(res, &CompileError{Pos: Position{Line: 1, Column: 1}, Message: e.Error()})
+			continue
+		}
+		compileErr := &CompileError{
+			Pos: Position{Line: pe.pos.line, Column: pe.pos.col, Offset: pe.pos.offset},
+		}
+		unexpected := unexpectedToken(filter, pe.pos.offset)
+		if unexpected == "" {
+			compileErr.Message = "unexpected end of filter"
+		} else {
+			compileErr.Message = fmt.Sprintf("syntax error at '%s'", unexpected)
+			for prefix, suggestion := range parseErrorSuggestions {
+				if strings.HasPrefix(unexpected, prefix) {
+					compileErr.Suggestion = suggestion
+				}
+			}
+		}
+		res = append(res, compileErr)
+	}
+	return res
+}
+
+// unexpectedToken returns the whitespace delimited text of the filter containing the offset
+func unexpectedToken(filter string, offset int) string {
+	if offset >= len(filter) {
+		return ""
+	}
+	start := strings.LastIndexAny(filter[:offset], " \t\r\n") + 1
+	token := filter[start:]
+	if idx := strings.IndexAny(token, " \t\r\n"); idx != -1 {
+		token = token[:idx]
+	}
+	return token
+}
+
+// valueType is the static type of a value in a filter - values of unknown type, e.g. the result of a '->'
+// jsonb selector, are compatible with any type
+type valueType struct {
+	columnType ColumnType
+	// the code node, if the value is a constant
+	constant *CodeNode
+	null     bool
+	unknown  bool
+}
+
+type compiler struct {
+	schema Schema
+	errors CompileErrors
+}
+
+func (c *compiler) addError(pos Position, suggestion string, format string, args ...any) {
+	c.errors = append(c.errors, &CompileError{Pos: pos, Message: fmt.Sprintf(format, args...), Suggestion: suggestion})
+}
+
+func (c *compiler) checkComparison(node ComparisonNode) {
+	switch node.Type {
+	case "and", "or":
+		for _, v := range toIfaceSlice(node.Values) {
+			if child, ok := v.(ComparisonNode); ok {
+				c.checkComparison(child)
+			}
+		}
+	case "not":
+		if values, ok := node.Values.([]ComparisonNode); ok {
+			for _, v := range values {
+				c.checkComparison(v)
+			}
+		}
+	case "compare", "in":
+		values, _ := node.Values.([]CodeNode)
+		if len(values) == 0 {
+			return
+		}
+		left := c.valueType(values[0])
+		for _, v := range values[1:] {
+			c.checkCompatible(left, c.valueType(v), values[0], v)
+		}
+	case "like":
+		values, _ := node.Values.([]CodeNode)
+		if len(values) != 2 {
+			return
+		}
+		left := c.valueType(values[0])
+		if !left.unknown && !left.null && left.columnType != ColumnTypeString {
+			c.addError(node.Operator.Pos, "", "'%s' cannot be applied to %s", node.Operator.Value, describeValue(values[0], left))
+		}
+	case "is":
+		values, _ := node.Values.([]CodeNode)
+		if len(values) != 2 {
+			return
+		}
+		left, right := c.valueType(values[0]), c.valueType(values[1])
+		if !right.null && !left.unknown && !left.null && left.columnType != ColumnTypeBool {
+			c.addError(node.Operator.Pos, "", "'%s %s' cannot be applied to %s", node.Operator.Value, values[1].Value, describeValue(values[0], left))
+		}
+	case "identifier":
+		values, _ := node.Values.([]CodeNode)
+		if len(values) != 1 {
+			return
+		}
+		t := c.valueType(values[0])
+		if !t.unknown && t.columnType != ColumnTypeBool {
+			c.addError(values[0].Pos, "", "%s cannot be used as a condition, as it is not a bool", describeValue(values[0], t))
+		}
+	}
+}
+
+// valueType returns the type of the code node, adding an error if it refers to an unknown column
+func (c *compiler) valueType(node CodeNode) valueType {
+	switch node.Type {
+	case "quoted_identifier", "unquoted_identifier":
+		if c.schema == nil {
+			return valueType{unknown: true}
+		}
+		columnType, ok := c.schema[node.Value]
+		if !ok {
+			suggestion := c.suggestColumn(node.Value)
+			if suggestion == "" && node.Type == "quoted_identifier" {
+				suggestion = fmt.Sprintf("the string '%s' - double quotes are used for column names", strings.ReplaceAll(node.Value, "'", "''"))
+			}
+			c.addError(node.Pos, suggestion, "unknown column '%s'", node.Value)
+			return valueType{unknown: true}
+		}
+		if columnType == ColumnTypeAny {
+			return valueType{unknown: true}
+		}
+		if len(node.JsonbSelector) == 0 {
+			return valueType{columnType: columnType}
+		}
+		if columnType != ColumnTypeJson {
+			c.addError(node.Pos, "", "jsonb selectors can only be applied to json columns, but column '%s' is a %s", node.Value, columnType)
+			return valueType{unknown: true}
+		}
+		// '->>' returns text, '->' returns json, which may be of any type
+		if node.JsonbSelector[len(node.JsonbSelector)-2].Value == "->>" {
+			return valueType{columnType: ColumnTypeString}
+		}
+		return valueType{unknown: true}
+	case "string":
+		return valueType{columnType: ColumnTypeString, constant: &node}
+	case "number":
+		return valueType{columnType: ColumnTypeNumber, constant: &node}
+	case "bool":
+		return valueType{columnType: ColumnTypeBool, constant: &node}
+	case "null":
+		return valueType{null: true, constant: &node}
+	case "time_calculation":
+		if calculation, err := parseTimeCalculation(node); err != nil {
+			c.addError(node.Pos, "", "%s", err.Error())
+		} else if calculation.intervalString != "" {
+			if _, err := calculation.interval(); err != nil {
+				c.addError(node.Pos, "", "%s", err.Error())
+			}
+		}
+		return valueType{columnType: ColumnTypeTimestamp, constant: &node}
+	}
+	return valueType{unknown: true}
+}
+
+// checkCompatible adds an error if the values cannot be compared - string constants are compatible with
+// other types if they can be converted to the type, as they are when the filter is evaluated
+func (c *compiler) checkCompatible(left, right valueType, leftNode, rightNode CodeNode) {
+	if left.unknown || right.unknown || left.null || right.null || left.columnType == right.columnType {
+		return
+	}
+	if left.columnType == ColumnTypeString && left.constant != nil && canConvert(left.constant.Value, right.columnType) {
+		return
+	}
+	if right.columnType == ColumnTypeString && right.constant != nil && canConvert(right.constant.Value, left.columnType) {
+		return
+	}
+	c.addError(rightNode.Pos, "", "cannot compare %s with %s", describeValue(leftNode, left), describeValue(rightNode, right))
+}
+
+func canConvert(value string, columnType ColumnType) bool {
+	var ok bool
+	switch columnType {
+	case ColumnTypeNumber:
+		_, ok = asFloat(value)
+	case ColumnTypeBool:
+		_, ok = asBool(value)
+	case ColumnTypeTimestamp:
+		_, ok = asTime(value)
+	case ColumnTypeJson:
+		ok = true
+	}
+	return ok
+}
+
+// describeValue describes the value for an error message
+func describeValue(node CodeNode, t valueType) string {
+	if t.constant != nil {
+		return fmt.Sprintf("%s %s", t.columnType, node.Source)
+	}
+	return fmt.Sprintf("column '%s' of type %s", node.Value, t.columnType)
+}
+
+// suggestColumn returns the schema column most similar to the unknown column, if any are similar enough
+func (c *compiler) suggestColumn(column string) string {
+	columns := maps.Keys(c.schema)
+	// sort so the suggestion is deterministic if several columns are equally similar
+	slices.Sort(columns)
+
+	best, bestDistance := "", 0
+	for _, candidate := range columns {
+		distance := levenshteinDistance(strings.ToLower(column), strings.ToLower(candidate))
+		if best == "" || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	// only suggest columns which differ by less than half the length of the column
+	if best == "" || bestDistance > max(1, len(column)/2) {
+		return ""
+	}
+	return fmt.Sprintf("'%s'", best)
+}
+
+// levenshteinDistance returns the number of single character edits required to change a into b
+func levenshteinDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
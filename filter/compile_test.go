@@ -0,0 +1,142 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+)
+
+var compileSchema = Schema{
+	"name":       ColumnTypeString,
+	"count":      ColumnTypeNumber,
+	"enabled":    ColumnTypeBool,
+	"created_at": ColumnTypeTimestamp,
+	"tags":       ColumnTypeJson,
+	"extra":      ColumnTypeAny,
+}
+
+func TestCompile(t *testing.T) {
+	valid := []string{
+		`name = 'foo' and count > 1`,
+		`count = '12'`,
+		`enabled and not enabled = 'false'`,
+		`created_at > '2024-01-01' or created_at < now() - interval '1 day'`,
+		`tags ->> 'service' like 'ec%'`,
+		`tags -> 'count' > 1`,
+		`name is null and enabled is not true`,
+		`count in (1, 2)`,
+		`extra = 1 or extra like 'a%' or extra ->> 'a' = 'b'`,
+	}
+	for _, tc := range valid {
+		if _, err := Compile(tc, compileSchema); err != nil {
+			t.Errorf("%q: want no error, got %v", tc, err)
+		}
+	}
+
+	// without a schema, columns and types are not checked
+	if _, err := Compile(`unknown like 'a%' and other`, nil); err != nil {
+		t.Errorf("want no error without a schema, got %v", err)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   []CompileError
+	}{
+		{
+			filter: `nmae = 'foo'`,
+			want:   []CompileError{{Pos: Position{Line: 1, Column: 1}, Message: "unknown column 'nmae'", Suggestion: "'name'"}},
+		},
+		{
+			filter: `name = "foo"`,
+			want:   []CompileError{{Pos: Position{Line: 1, Column: 8}, Message: "unknown column 'foo'", Suggestion: "the string 'foo' - double quotes are used for column names"}},
+		},
+		{
+			filter: "name = 'foo'\nand count like '1%'",
+			want:   []CompileError{{Pos: Position{Line: 2, Column: 11}, Message: "'like' cannot be applied to column 'count' of type number"}},
+		},
+		{
+			filter: `count = 'abc' or created_at > 'yesterday' or enabled = 1`,
+			want: []CompileError{
+				{Pos: Position{Line: 1, Column: 9}, Message: "cannot compare column 'count' of type number with string 'abc'"},
+				{Pos: Position{Line: 1, Column: 31}, Message: "cannot compare column 'created_at' of type timestamp with string 'yesterday'"},
+				{Pos: Position{Line: 1, Column: 56}, Message: "cannot compare column 'enabled' of type bool with number 1"},
+			},
+		},
+		{
+			filter: `name ->> 'a' = 'b'`,
+			want:   []CompileError{{Pos: Position{Line: 1, Column: 1}, Message: "jsonb selectors can only be applied to json columns, but column 'name' is a string"}},
+		},
+		{
+			filter: `name and count is true`,
+			want: []CompileError{
+				{Pos: Position{Line: 1, Column: 1}, Message: "column 'name' of type string cannot be used as a condition, as it is not a bool"},
+				{Pos: Position{Line: 1, Column: 16}, Message: "'is true' cannot be applied to column 'count' of type number"},
+			},
+		},
+		{
+			filter: `created_at > now() - interval '1 fortnight'`,
+			want:   []CompileError{{Pos: Position{Line: 1, Column: 14}, Message: "invalid interval '1 fortnight': unknown unit 'fortnight'"}},
+		},
+		{
+			filter: `count == 1`,
+			want:   []CompileError{{Pos: Position{Line: 1, Column: 8}, Message: "syntax error at '=='", Suggestion: "'='"}},
+		},
+		{
+			filter: `name = 'foo' and`,
+			want:   []CompileError{{Pos: Position{Line: 1, Column: 17}, Message: "unexpected end of filter"}},
+		},
+	}
+	for _, tc := range tests {
+		_, err := Compile(tc.filter, compileSchema)
+		var errs CompileErrors
+		if !errors.As(err, &errs) {
+			t.Errorf("%q: want CompileErrors, got %v", tc.filter, err)
+			continue
+		}
+		if len(errs) != len(tc.want) {
+			t.Errorf("%q: want %d errors, got %d: %v", tc.filter, len(tc.want), len(errs), errs)
+			continue
+		}
+		for i, want := range tc.want {
+			got := *errs[i]
+			got.Pos.Offset = 0
+			if got != want {
+				t.Errorf("%q: want %+v, got %+v", tc.filter, want, got)
+			}
+		}
+	}
+}
+
+func TestPredicate(t *testing.T) {
+	p, err := Compile(`name like 'a%' and count > 1`, compileSchema)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	rows := map[string]bool{
+		"a1": true,
+		"a2": false,
+		"b1": false,
+	}
+	for name, exp := range rows {
+		count := 2
+		if name == "a2" {
+			count = 0
+		}
+		if got := p.Match(MapRow{"name": name, "count": count}); got != exp {
+			t.Errorf("%s: want %v, got %v", name, exp, got)
+		}
+	}
+	// the like pattern is compiled once, rather than for each row
+	if got := len(p.likeCache.patterns); got != 1 {
+		t.Errorf("want 1 compiled like pattern, got %d", got)
+	}
+
+	sql, args, err := p.ToSQL(WithDialect(DialectSqlite))
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if sql != `( ( "name" glob ? ) and ( "count" > ? ) )` || len(args) != 2 {
+		t.Errorf("unexpected sql %s %v", sql, args)
+	}
+}
@@ -0,0 +1,682 @@
+package filter
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	typehelpers "github.com/turbot/go-kit/types"
+)
+
+// Row is implemented by the rows which a filter may be evaluated against
+type Row interface {
+	// GetColumnValue returns the value of the column, and whether the row has the column
+	GetColumnValue(column string) (any, bool)
+}
+
+// MapRow is a Row backed by a map of column name to value
+type MapRow map[string]any
+
+func (r MapRow) GetColumnValue(column string) (any, bool) {
+	value, ok := r[column]
+	return value, ok
+}
+
+// truth is the result of evaluating a comparison using SQL three-valued logic - comparisons involving null
+// values are unknown
+type truth int
+
+const (
+	truthUnknown truth = iota
+	truthFalse
+	truthTrue
+)
+
+func truthOf(b bool) truth {
+	if b {
+		return truthTrue
+	}
+	return truthFalse
+}
+
+func (t truth) not() truth {
+	switch t {
+	case truthTrue:
+		return truthFalse
+	case truthFalse:
+		return truthTrue
+	}
+	return truthUnknown
+}
+
+type EvaluateOption func(*evaluator)
+
+// WithClock sets the clock used to evaluate now() in time calculations - by default this is time.Now
+func WithClock(clock func() time.Time) EvaluateOption {
+	return func(e *evaluator) {
+		e.clock = clock
+	}
+}
+
+// withLikeCache sets the cache of compiled like patterns - this is used by Predicate so the patterns are compiled
+// once, rather than for every row
+func withLikeCache(cache *likeCache) EvaluateOption {
+	return func(e *evaluator) {
+		e.likeCache = cache
+	}
+}
+
+type evaluator struct {
+	row   Row
+	clock func() time.Time
+	// the value of now() - as in postgres this is fixed for the evaluation, so it is only read from the clock once
+	now       *time.Time
+	likeCache *likeCache
+}
+
+// Evaluate evaluates the filter (as returned by Parse) against the row, following the semantics of the SQL
+// returned by ComparisonToSQL:
+//   - values are compared as numbers, booleans or timestamps if either side is of that type, and as strings otherwise
+//   - missing columns are null, and comparisons with null values are unknown, i.e. do not match
+//   - 'and', 'or' and 'not' use three-valued logic
+//   - jsonb selectors are applied to nested maps, slices and structs, or to strings containing JSON
+//   - time calculations, e.g. now() - interval '1 day', are evaluated using the clock set by WithClock
+//
+// Values which cannot be converted to a common type do not match. All nodes of the filter are evaluated, so an error
+// is returned for an unsupported expression regardless of the row values
+func Evaluate(node ComparisonNode, row Row, opts ...EvaluateOption) (bool, error) {
+	e := &evaluator{
+		row:   row,
+		clock: time.Now,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.likeCache == nil {
+		e.likeCache = newLikeCache()
+	}
+
+	res, err := e.evaluateComparison(node)
+	if err != nil {
+		return false, err
+	}
+	return res == truthTrue, nil
+}
+
+func (e *evaluator) evaluateComparison(node ComparisonNode) (truth, error) {
+	switch node.Type {
+	case "and", "or":
+		return e.evaluateLogic(node)
+	case "not":
+		values, ok := node.Values.([]ComparisonNode)
+		if !ok || len(values) != 1 {
+			return truthUnknown, fmt.Errorf("invalid 'not' expression")
+		}
+		res, err := e.evaluateComparison(values[0])
+		return res.not(), err
+	case "compare":
+		return e.evaluateCompare(node)
+	case "like":
+		return e.evaluateLike(node)
+	case "is":
+		return e.evaluateIs(node)
+	case "in":
+		return e.evaluateIn(node)
+	case "identifier":
+		values, ok := node.Values.([]CodeNode)
+		if !ok || len(values) != 1 {
+			return truthUnknown, fmt.Errorf("invalid identifier expression")
+		}
+		value, err := e.evaluateCode(values[0])
+		if err != nil {
+			return truthUnknown, err
+		}
+		b, ok := asBool(value)
+		if !ok {
+			return truthUnknown, nil
+		}
+		return truthOf(b), nil
+	}
+	return truthUnknown, fmt.Errorf("unsupported expression type '%s'", node.Type)
+}
+
+func (e *evaluator) evaluateLogic(node ComparisonNode) (truth, error) {
+	// 'and' is false if any value is false, 'or' is true if any value is true
+	decisive := truthFalse
+	if node.Type == "or" {
+		decisive = truthTrue
+	}
+	res := decisive.not()
+	for _, v := range toIfaceSlice(node.Values) {
+		child, ok := v.(ComparisonNode)
+		if !ok {
+			return truthUnknown, fmt.Errorf("invalid '%s' expression", node.Type)
+		}
+		childRes, err := e.evaluateComparison(child)
+		if err != nil {
+			return truthUnknown, err
+		}
+		if childRes == decisive || (childRes == truthUnknown && res != decisive) {
+			res = childRes
+		}
+	}
+	return res, nil
+}
+
+func (e *evaluator) evaluateCompare(node ComparisonNode) (truth, error) {
+	left, right, err := e.evaluateOperands(node)
+	if err != nil {
+		return truthUnknown, err
+	}
+	switch node.Operator.Value {
+	case "=", "!=", "<>", "<", "<=", ">", ">=":
+	default:
+		return truthUnknown, fmt.Errorf("unsupported comparison operator '%s'", node.Operator.Value)
+	}
+	c, ok := compareValues(left, right)
+	if !ok {
+		return truthUnknown, nil
+	}
+	switch node.Operator.Value {
+	case "=":
+		return truthOf(c == 0), nil
+	case "!=", "<>":
+		return truthOf(c != 0), nil
+	case "<":
+		return truthOf(c < 0), nil
+	case "<=":
+		return truthOf(c <= 0), nil
+	case ">":
+		return truthOf(c > 0), nil
+	default:
+		return truthOf(c >= 0), nil
+	}
+}
+
+func (e *evaluator) evaluateLike(node ComparisonNode) (truth, error) {
+	left, right, err := e.evaluateOperands(node)
+	if err != nil {
+		return truthUnknown, err
+	}
+	var res bool
+	op := node.Operator.Value
+	if left != nil && right != nil {
+		caseSensitive := !strings.HasSuffix(op, "ilike")
+		res = e.likeCache.regexp(typehelpers.ToString(right), caseSensitive).MatchString(typehelpers.ToString(left))
+	}
+	switch op {
+	case "like", "ilike":
+	case "not like", "not ilike":
+		res = !res
+	default:
+		return truthUnknown, fmt.Errorf("unsupported like operator '%s'", op)
+	}
+	if left == nil || right == nil {
+		return truthUnknown, nil
+	}
+	return truthOf(res), nil
+}
+
+func (e *evaluator) evaluateIs(node ComparisonNode) (truth, error) {
+	left, right, err := e.evaluateOperands(node)
+	if err != nil {
+		return truthUnknown, err
+	}
+	var res bool
+	if right == nil {
+		res = left == nil
+	} else {
+		leftBool, leftOk := asBool(left)
+		rightBool, rightOk := asBool(right)
+		res = leftOk && rightOk && leftBool == rightBool
+	}
+	switch node.Operator.Value {
+	case "is":
+		return truthOf(res), nil
+	case "is not":
+		return truthOf(!res), nil
+	}
+	return truthUnknown, fmt.Errorf("unsupported is operator '%s'", node.Operator.Value)
+}
+
+func (e *evaluator) evaluateIn(node ComparisonNode) (truth, error) {
+	values, ok := node.Values.([]CodeNode)
+	if !ok || len(values) == 0 {
+		return truthUnknown, fmt.Errorf("invalid 'in' expression")
+	}
+	left, err := e.evaluateCode(values[0])
+	if err != nil {
+		return truthUnknown, err
+	}
+	// as in SQL, if there is no match and any comparison is unknown, the result is unknown
+	res := truthFalse
+	for _, v := range values[1:] {
+		right, err := e.evaluateCode(v)
+		if err != nil {
+			return truthUnknown, err
+		}
+		c, ok := compareValues(left, right)
+		switch {
+		case !ok:
+			if res != truthTrue {
+				res = truthUnknown
+			}
+		case c == 0:
+			res = truthTrue
+		}
+	}
+	switch node.Operator.Value {
+	case "in":
+		return res, nil
+	case "not in":
+		return res.not(), nil
+	}
+	return truthUnknown, fmt.Errorf("unsupported in operator '%s'", node.Operator.Value)
+}
+
+func (e *evaluator) evaluateOperands(node ComparisonNode) (any, any, error) {
+	values, ok := node.Values.([]CodeNode)
+	if !ok || len(values) != 2 {
+		return nil, nil, fmt.Errorf("invalid '%s' expression", node.Type)
+	}
+	left, err := e.evaluateCode(values[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	right, err := e.evaluateCode(values[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	return left, right, nil
+}
+
+// evaluateCode returns the value of the code node - this is nil, a string, int64, float64, bool, time.Time,
+// or for column values of other types, the column value
+func (e *evaluator) evaluateCode(node CodeNode) (any, error) {
+	switch node.Type {
+	case "quoted_identifier", "unquoted_identifier":
+		value, _ := e.row.GetColumnValue(node.Value)
+		if len(node.JsonbSelector) > 0 {
+			return evaluateJsonbSelector(value, node.JsonbSelector)
+		}
+		return normaliseValue(value), nil
+	case "string":
+		return node.Value, nil
+	case "number":
+		if i, err := strconv.ParseInt(node.Value, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(node.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s'", node.Source)
+		}
+		return f, nil
+	case "bool":
+		return node.Value == "true", nil
+	case "null":
+		return nil, nil
+	case "time_calculation":
+		return e.evaluateTimeCalculation(node)
+	}
+	return nil, fmt.Errorf("unsupported value type '%s'", node.Type)
+}
+
+// evaluateTimeCalculation evaluates a time calculation using the evaluator clock
+func (e *evaluator) evaluateTimeCalculation(node CodeNode) (time.Time, error) {
+	if e.now == nil {
+		now := e.clock()
+		e.now = &now
+	}
+
+	calculation, err := parseTimeCalculation(node)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if calculation.intervalString == "" {
+		return *e.now, nil
+	}
+	i, err := calculation.interval()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time calculation '%s': %s", node.Source, err.Error())
+	}
+	return i.AddTo(*e.now), nil
+}
+
+// evaluateJsonbSelector applies the jsonb selector (a sequence of operator and field pairs) to the value.
+// As in postgres, '->' returns the selected element and '->>' returns it as text, and selecting a missing
+// key or index returns null
+func evaluateJsonbSelector(value any, selector []CodeNode) (any, error) {
+	if len(selector)%2 != 0 {
+		return nil, fmt.Errorf("invalid jsonb selector")
+	}
+	for i := 0; i < len(selector); i += 2 {
+		op, field := selector[i], selector[i+1]
+		value = jsonbElement(value, field)
+		switch op.Value {
+		case "->":
+		case "->>":
+			value = jsonbText(value)
+		default:
+			return nil, fmt.Errorf("unsupported jsonb operator '%s'", op.Value)
+		}
+	}
+	return normaliseValue(value), nil
+}
+
+// jsonbElement returns the element of the value selected by the field, which is either an object key or an
+// array index
+func jsonbElement(value any, field CodeNode) any {
+	v := jsonbValue(value)
+	if !v.IsValid() {
+		return nil
+	}
+	switch field.Type {
+	case "string":
+		if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		elem := v.MapIndex(reflect.ValueOf(field.Value).Convert(v.Type().Key()))
+		if !elem.IsValid() {
+			return nil
+		}
+		return elem.Interface()
+	case "number":
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil
+		}
+		idx, err := strconv.Atoi(field.Value)
+		if err != nil || idx >= v.Len() {
+			return nil
+		}
+		// as in postgres, negative indexes count from the end of the array
+		if idx < 0 {
+			idx += v.Len()
+		}
+		if idx < 0 {
+			return nil
+		}
+		return v.Index(idx).Interface()
+	}
+	return nil
+}
+
+// jsonbValue returns the value as a map or slice which may be selected from - strings are parsed as JSON,
+// and other types which are not maps or slices are converted via their JSON representation
+func jsonbValue(value any) reflect.Value {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+
+	var data []byte
+	switch {
+	case !v.IsValid():
+		return v
+	case v.Kind() == reflect.Map || v.Kind() == reflect.Array:
+		return v
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8:
+		return v
+	case v.Kind() == reflect.String:
+		data = []byte(v.String())
+	case v.Kind() == reflect.Slice:
+		data = v.Bytes()
+	default:
+		var err error
+		if data, err = json.Marshal(v.Interface()); err != nil {
+			return reflect.Value{}
+		}
+	}
+
+	var res any
+	if err := json.Unmarshal(data, &res); err != nil {
+		return reflect.Value{}
+	}
+	return reflect.ValueOf(res)
+}
+
+// jsonbText converts the value to text, as returned by the '->>' operator
+func jsonbText(value any) any {
+	switch v := normaliseValue(value).(type) {
+	case nil:
+		return nil
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []byte:
+		return string(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return typehelpers.ToString(v)
+		}
+		return string(data)
+	}
+}
+
+// normaliseValue dereferences pointers and converts integer values to int64 and other numeric values to
+// float64 - unsigned values too large for an int64 are converted to float64
+func normaliseValue(value any) any {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u := v.Uint(); u <= math.MaxInt64 {
+			return int64(u)
+		}
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String:
+		return v.String()
+	}
+	return v.Interface()
+}
+
+// compareValues converts the values to a common type and compares them, returning false if either value
+// is null or the values cannot be converted
+func compareValues(left, right any) (int, bool) {
+	if left == nil || right == nil {
+		return 0, false
+	}
+	switch l := left.(type) {
+	case int64, float64:
+		return compareNumbers(l, right)
+	case bool:
+		r, ok := asBool(right)
+		return compareBool(l, r), ok
+	case time.Time:
+		r, ok := asTime(right)
+		return l.Compare(r), ok
+	}
+	switch r := right.(type) {
+	case int64, float64:
+		return compareNumbers(left, r)
+	case bool:
+		l, ok := asBool(left)
+		return compareBool(l, r), ok
+	case time.Time:
+		l, ok := asTime(left)
+		return l.Compare(r), ok
+	}
+	return strings.Compare(typehelpers.ToString(left), typehelpers.ToString(right)), true
+}
+
+// compareNumbers compares the values as int64 if both are integral, so that integers beyond the precision
+// of a float64 compare exactly, and as float64 otherwise
+func compareNumbers(left, right any) (int, bool) {
+	l, lok := asInt(left)
+	r, rok := asInt(right)
+	if lok && rok {
+		return cmp.Compare(l, r), true
+	}
+	lf, lok := asFloat(left)
+	rf, rok := asFloat(right)
+	return cmp.Compare(lf, rf), lok && rok
+}
+
+func compareBool(l, r bool) int {
+	switch {
+	case l == r:
+		return 0
+	case r:
+		return -1
+	}
+	return 1
+}
+
+func asInt(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+func asFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func asBool(value any) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		return b, err == nil
+	}
+	return false, false
+}
+
+// the layouts which strings are parsed with when compared to timestamps
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	time.DateOnly,
+}
+
+func asTime(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// Like returns whether the input matches the SQL LIKE pattern, where '%' matches any sequence of characters,
+// '_' matches any single character and '\' escapes the following character
+func Like(input, pattern string, caseSensitive bool) bool {
+	return likeRegexp(pattern, caseSensitive).MatchString(input)
+}
+
+// the maximum number of patterns cached by a likeCache - patterns may be read from column values, so the
+// number of distinct patterns is unbounded
+const maxLikeCacheSize = 1000
+
+type likePattern struct {
+	pattern       string
+	caseSensitive bool
+}
+
+// likeCache caches the regular expressions compiled for like patterns. It is safe for concurrent use, as a
+// Predicate may be evaluated concurrently
+type likeCache struct {
+	mu       sync.Mutex
+	patterns map[likePattern]*regexp.Regexp
+}
+
+func newLikeCache() *likeCache {
+	return &likeCache{patterns: make(map[likePattern]*regexp.Regexp)}
+}
+
+func (c *likeCache) regexp(pattern string, caseSensitive bool) *regexp.Regexp {
+	key := likePattern{pattern: pattern, caseSensitive: caseSensitive}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if re, ok := c.patterns[key]; ok {
+		return re
+	}
+	re := likeRegexp(pattern, caseSensitive)
+	if len(c.patterns) < maxLikeCacheSize {
+		c.patterns[key] = re
+	}
+	return re
+}
+
+// likeRegexp converts the SQL LIKE pattern to a regular expression
+func likeRegexp(pattern string, caseSensitive bool) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("(?s)")
+	if !caseSensitive {
+		sb.WriteString("(?i)")
+	}
+	sb.WriteString("^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			sb.WriteString(".*")
+		case r == '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	// the pattern is built from quoted characters so is always valid
+	return regexp.MustCompile(sb.String())
+}
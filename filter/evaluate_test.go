@@ -0,0 +1,252 @@
+package filter
+
+import (
+	"testing"
+	"time"
+)
+
+var evaluateRow = MapRow{
+	"name":       "aws_s3_bucket",
+	"count":      12,
+	"size":       ptr(2.5),
+	"id":         int64(9007199254740993),
+	"uid":        uint64(18446744073709551615),
+	"enabled":    true,
+	"enabled_s":  "false",
+	"empty":      nil,
+	"created_at": time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	"FOO":        "upper",
+	"tags":       map[string]string{"service": "ec2"},
+	"config":     `{"a": {"b": [1, "two", true]}, "n": null}`,
+	"items":      []any{map[string]any{"id": 1}, map[string]any{"id": 2}},
+	"owner":      &evaluateOwner{Name: "alice", Groups: []string{"admin"}},
+}
+
+type evaluateOwner struct {
+	Name   string   `json:"name"`
+	Groups []string `json:"groups"`
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+var evaluateCases = map[string]bool{
+	// compare
+	`name = 'aws_s3_bucket'`:  true,
+	`name != 'aws_s3_bucket'`: false,
+	`'aws_s3_bucket' = name`:  true,
+	`name < 'b'`:              true,
+	`name = missing`:          false,
+	`name != missing`:         false,
+	`"FOO" = 'upper'`:         true,
+
+	// numbers
+	`count = 12`:     true,
+	`count > 9`:      true,
+	`count >= 12.0`:  true,
+	`count < 9`:      false,
+	`count <> 12`:    false,
+	`size > 2`:       true,
+	`size <= 2.5`:    true,
+	`count = '12'`:   true,
+	`name > 1`:       false,
+	`not (name > 1)`: false,
+
+	// integers beyond the precision of a float64 compare exactly
+	`id = 9007199254740993`:    true,
+	`id = 9007199254740992`:    false,
+	`id > 9007199254740992`:    true,
+	`id = '9007199254740993'`:  true,
+	`id in (9007199254740992)`: false,
+	`uid > 9007199254740993`:   true,
+
+	// booleans
+	`enabled`:                   true,
+	`not enabled`:               false,
+	`enabled = true`:            true,
+	`enabled is true`:           true,
+	`enabled is not false`:      true,
+	`enabled_s is false`:        true,
+	`enabled_s`:                 false,
+	`empty is true`:             false,
+	`empty is not true`:         true,
+	`missing is not false`:      true,
+	`enabled and enabled_s`:     false,
+	`enabled or enabled_s`:      true,
+	`not (enabled and empty)`:   false,
+	`not (enabled_s and empty)`: true,
+	`empty or enabled`:          true,
+
+	// null
+	`empty is null`:     true,
+	`missing is null`:   true,
+	`name is null`:      false,
+	`name is not null`:  true,
+	`empty = 'a'`:       false,
+	`not empty = 'a'`:   false,
+	`not empty is null`: false,
+
+	// timestamps
+	`created_at > '2024-02-28'`:           true,
+	`created_at < '2024-03-01T12:00:01Z'`: true,
+	`created_at = '2024-03-01 12:00:00'`:  true,
+
+	// like
+	`name like 'aws_%'`:        true,
+	`name like 'AWS%'`:         false,
+	`name ilike 'AWS%'`:        true,
+	`name not like 'aws\_s3%'`: false,
+	`name like 'aws\%'`:        false,
+	`name not ilike '%azure%'`: true,
+	`empty like '%'`:           false,
+	`empty not like '%'`:       false,
+
+	// jsonb
+	`tags ->> 'service' = 'ec2'`:                true,
+	`tags -> 'service' = 'ec2'`:                 true,
+	`tags ->> 'missing' is null`:                true,
+	`tags ->> 'service' like 'ec%'`:             true,
+	`config -> 'a' -> 'b' ->> 0 = '1'`:          true,
+	`config -> 'a' -> 'b' -> 0 > 0.5`:           true,
+	`config -> 'a' -> 'b' ->> 1 = 'two'`:        true,
+	`config -> 'a' -> 'b' -> 2 is true`:         true,
+	`config -> 'a' -> 'b' -> 3 is null`:         true,
+	`config -> 'a' ->> 'b' = '[1,"two",true]'`:  true,
+	`config -> 'n' is null`:                     true,
+	`config -> 0 is null`:                       true,
+	`items -> 1 ->> 'id' = '2'`:                 true,
+	`owner ->> 'name' = 'alice'`:                true,
+	`owner -> 'groups' ->> 0 in ('admin', 'x')`: true,
+	`name -> 'a' is null`:                       true,
+
+	// in
+	`name in ('a', 'aws_s3_bucket')`: true,
+	`name in ()`:                     false,
+	`name not in ()`:                 true,
+	`name not in ('a', 'b')`:         true,
+	`count in (1, 12)`:               true,
+	`count not in (1, null)`:         false,
+	`count in (12, null)`:            true,
+	`empty in ('a')`:                 false,
+	`empty not in ('a')`:             false,
+}
+
+func TestEvaluate(t *testing.T) {
+	for tc, exp := range evaluateCases {
+		parsed, err := Parse("", []byte(tc))
+		if err != nil {
+			t.Errorf("%q: want no parse error, got %v", tc, err)
+			continue
+		}
+		got, err := Evaluate(parsed.(ComparisonNode), evaluateRow)
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc, err)
+			continue
+		}
+		if got != exp {
+			t.Errorf("%q: want %v, got %v", tc, exp, got)
+		}
+	}
+}
+
+func TestLike(t *testing.T) {
+	tests := []struct {
+		input         string
+		pattern       string
+		caseSensitive bool
+		want          bool
+	}{
+		{"foo", "foo", true, true},
+		{"foo", "f%", true, true},
+		{"foo", "f_o", true, true},
+		{"foo", "F%", true, false},
+		{"foo", "F%", false, true},
+		{"f.o", "f.o", true, true},
+		{"fxo", "f.o", true, false},
+		{"50%", `50\%`, true, true},
+		{"500", `50\%`, true, false},
+		{"a\nb", "a%b", true, true},
+	}
+	for _, tc := range tests {
+		if got := Like(tc.input, tc.pattern, tc.caseSensitive); got != tc.want {
+			t.Errorf("Like(%q, %q, %v): want %v, got %v", tc.input, tc.pattern, tc.caseSensitive, tc.want, got)
+		}
+	}
+}
+
+func TestEvaluateTimeCalculations(t *testing.T) {
+	clock := func() time.Time {
+		return time.Date(2024, 3, 8, 12, 0, 0, 0, time.UTC)
+	}
+	cases := map[string]bool{
+		`created_at < now()`:                               true,
+		`created_at > now() - interval '7 days'`:           false,
+		`created_at >= now() - interval '1 week'`:          true,
+		`created_at > now() - interval '6 days 23 hrs'`:    false,
+		`created_at > now() - interval '7 days 1 min'`:     true,
+		`created_at < now() + interval '1 hour'`:           true,
+		`created_at > now() - interval '1 month'`:          true,
+		`now() - interval '1 year' < created_at`:           true,
+		`'2024-03-08T12:00:00Z' = now()`:                   true,
+		`missing > now() - interval '1 day'`:               false,
+		`created_at in (now() - interval '7 days', now())`: true,
+	}
+	for tc, exp := range cases {
+		parsed, err := Parse("", []byte(tc))
+		if err != nil {
+			t.Errorf("%q: want no parse error, got %v", tc, err)
+			continue
+		}
+		got, err := Evaluate(parsed.(ComparisonNode), evaluateRow, WithClock(clock))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc, err)
+			continue
+		}
+		if got != exp {
+			t.Errorf("%q: want %v, got %v", tc, exp, got)
+		}
+	}
+
+	// invalid intervals are reported regardless of the row values
+	parsed, err := Parse("", []byte(`missing > now() - interval '1 fortnight'`))
+	if err != nil {
+		t.Fatalf("want no parse error, got %v", err)
+	}
+	if _, err := Evaluate(parsed.(ComparisonNode), MapRow{}); err == nil {
+		t.Errorf("want error for invalid interval, got none")
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Interval
+	}{
+		{"1 day", Interval{Days: 1}},
+		{"2 weeks 3 days", Interval{Days: 17}},
+		{"1 hr", Interval{Duration: time.Hour}},
+		{"1h30m", Interval{Duration: 90 * time.Minute}},
+		{"-1 hour", Interval{Duration: -time.Hour}},
+		{"1.5 days", Interval{Days: 1, Duration: 12 * time.Hour}},
+		{"1 year 2 MONTHS", Interval{Months: 14}},
+		{"0.5 month", Interval{Days: 15}},
+		{"3 days ago", Interval{Days: -3}},
+	}
+	for _, tc := range tests {
+		got, err := ParseInterval(tc.input)
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc.input, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%q: want %+v, got %+v", tc.input, tc.want, got)
+		}
+	}
+
+	for _, input := range []string{"", "day", "1", "1 fortnight", "one day"} {
+		if _, err := ParseInterval(input); err == nil {
+			t.Errorf("%q: want error, got none", input)
+		}
+	}
+}
@@ -28,6 +28,19 @@ type CodeNode struct {
 	Source        string
 	Value         string
 	JsonbSelector []CodeNode
+	// the position of the node in the filter
+	Pos Position
+}
+
+// Position is a position in the filter - the line and column are 1 based, and the column is counted in runes
+type Position struct {
+	Line   int
+	Column int
+	Offset int
+}
+
+func (c *current) position() Position {
+	return Position{Line: c.pos.line, Column: c.pos.col, Offset: c.pos.offset}
 }
 
 type FunctionNode struct {
@@ -58,31 +71,31 @@ var g = &grammar{
 	rules: []*rule{
 		{
 			name: "Input",
-			pos:  position{line: 50, col: 1, offset: 785},
+			pos:  position{line: 63, col: 1, offset: 1159},
 			expr: &actionExpr{
-				pos: position{line: 50, col: 10, offset: 794},
+				pos: position{line: 63, col: 10, offset: 1168},
 				run: (*parser).callonInput1,
 				expr: &seqExpr{
-					pos: position{line: 50, col: 10, offset: 794},
-					exprs: []interface{}{
+					pos: position{line: 63, col: 10, offset: 1168},
+					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 50, col: 10, offset: 794},
+							pos:  position{line: 63, col: 10, offset: 1168},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 50, col: 12, offset: 796},
+							pos:   position{line: 63, col: 12, offset: 1170},
 							label: "i",
 							expr: &ruleRefExpr{
-								pos:  position{line: 50, col: 14, offset: 798},
+								pos:  position{line: 63, col: 14, offset: 1172},
 								name: "OrComparison",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 50, col: 27, offset: 811},
+							pos:  position{line: 63, col: 27, offset: 1185},
 							name: "_",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 50, col: 29, offset: 813},
+							pos:  position{line: 63, col: 29, offset: 1187},
 							name: "EOF",
 						},
 					},
@@ -91,43 +104,43 @@ var g = &grammar{
 		},
 		{
 			name: "OrComparison",
-			pos:  position{line: 54, col: 1, offset: 838},
+			pos:  position{line: 67, col: 1, offset: 1212},
 			expr: &actionExpr{
-				pos: position{line: 54, col: 17, offset: 854},
+				pos: position{line: 67, col: 17, offset: 1228},
 				run: (*parser).callonOrComparison1,
 				expr: &seqExpr{
-					pos: position{line: 54, col: 17, offset: 854},
-					exprs: []interface{}{
+					pos: position{line: 67, col: 17, offset: 1228},
+					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 54, col: 17, offset: 854},
+							pos:   position{line: 67, col: 17, offset: 1228},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 54, col: 23, offset: 860},
+								pos:  position{line: 67, col: 23, offset: 1234},
 								name: "AndComparison",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 54, col: 37, offset: 874},
+							pos:   position{line: 67, col: 37, offset: 1248},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 54, col: 42, offset: 879},
+								pos: position{line: 67, col: 42, offset: 1253},
 								expr: &seqExpr{
-									pos: position{line: 54, col: 44, offset: 881},
-									exprs: []interface{}{
+									pos: position{line: 67, col: 44, offset: 1255},
+									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 54, col: 44, offset: 881},
+											pos:  position{line: 67, col: 44, offset: 1255},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 54, col: 46, offset: 883},
+											pos:  position{line: 67, col: 46, offset: 1257},
 											name: "Or",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 54, col: 49, offset: 886},
+											pos:  position{line: 67, col: 49, offset: 1260},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 54, col: 51, offset: 888},
+											pos:  position{line: 67, col: 51, offset: 1262},
 											name: "AndComparison",
 										},
 									},
@@ -140,43 +153,43 @@ var g = &grammar{
 		},
 		{
 			name: "AndComparison",
-			pos:  position{line: 66, col: 1, offset: 1066},
+			pos:  position{line: 79, col: 1, offset: 1440},
 			expr: &actionExpr{
-				pos: position{line: 66, col: 18, offset: 1083},
+				pos: position{line: 79, col: 18, offset: 1457},
 				run: (*parser).callonAndComparison1,
 				expr: &seqExpr{
-					pos: position{line: 66, col: 18, offset: 1083},
-					exprs: []interface{}{
+					pos: position{line: 79, col: 18, offset: 1457},
+					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 66, col: 18, offset: 1083},
+							pos:   position{line: 79, col: 18, offset: 1457},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 66, col: 24, offset: 1089},
+								pos:  position{line: 79, col: 24, offset: 1463},
 								name: "Comparison",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 66, col: 35, offset: 1100},
+							pos:   position{line: 79, col: 35, offset: 1474},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 66, col: 40, offset: 1105},
+								pos: position{line: 79, col: 40, offset: 1479},
 								expr: &seqExpr{
-									pos: position{line: 66, col: 42, offset: 1107},
-									exprs: []interface{}{
+									pos: position{line: 79, col: 42, offset: 1481},
+									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 66, col: 42, offset: 1107},
+											pos:  position{line: 79, col: 42, offset: 1481},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 66, col: 44, offset: 1109},
+											pos:  position{line: 79, col: 44, offset: 1483},
 											name: "And",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 66, col: 48, offset: 1113},
+											pos:  position{line: 79, col: 48, offset: 1487},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 66, col: 50, offset: 1115},
+											pos:  position{line: 79, col: 50, offset: 1489},
 											name: "Comparison",
 										},
 									},
@@ -189,36 +202,36 @@ var g = &grammar{
 		},
 		{
 			name: "Comparison",
-			pos:  position{line: 83, col: 1, offset: 1314},
+			pos:  position{line: 96, col: 1, offset: 1688},
 			expr: &choiceExpr{
-				pos: position{line: 83, col: 16, offset: 1329},
-				alternatives: []interface{}{
+				pos: position{line: 96, col: 16, offset: 1703},
+				alternatives: []any{
 					&ruleRefExpr{
-						pos:  position{line: 83, col: 16, offset: 1329},
+						pos:  position{line: 96, col: 16, offset: 1703},
 						name: "MultiComparison",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 83, col: 34, offset: 1347},
+						pos:  position{line: 96, col: 34, offset: 1721},
 						name: "NotComparison",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 83, col: 50, offset: 1363},
+						pos:  position{line: 96, col: 50, offset: 1737},
 						name: "LeftRightComparison",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 83, col: 72, offset: 1385},
+						pos:  position{line: 96, col: 72, offset: 1759},
 						name: "LikeComparison",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 83, col: 89, offset: 1402},
+						pos:  position{line: 96, col: 89, offset: 1776},
 						name: "IsComparison",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 83, col: 104, offset: 1417},
+						pos:  position{line: 96, col: 104, offset: 1791},
 						name: "InComparison",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 83, col: 119, offset: 1432},
+						pos:  position{line: 96, col: 119, offset: 1806},
 						name: "IdentifierComparison",
 					},
 				},
@@ -226,37 +239,37 @@ var g = &grammar{
 		},
 		{
 			name: "MultiComparison",
-			pos:  position{line: 85, col: 1, offset: 1454},
+			pos:  position{line: 98, col: 1, offset: 1828},
 			expr: &actionExpr{
-				pos: position{line: 85, col: 20, offset: 1473},
+				pos: position{line: 98, col: 20, offset: 1847},
 				run: (*parser).callonMultiComparison1,
 				expr: &seqExpr{
-					pos: position{line: 85, col: 20, offset: 1473},
-					exprs: []interface{}{
+					pos: position{line: 98, col: 20, offset: 1847},
+					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 85, col: 20, offset: 1473},
+							pos:        position{line: 98, col: 20, offset: 1847},
 							val:        "(",
 							ignoreCase: false,
 							want:       "\"(\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 85, col: 24, offset: 1477},
+							pos:  position{line: 98, col: 24, offset: 1851},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 85, col: 26, offset: 1479},
+							pos:   position{line: 98, col: 26, offset: 1853},
 							label: "expr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 85, col: 31, offset: 1484},
+								pos:  position{line: 98, col: 31, offset: 1858},
 								name: "OrComparison",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 85, col: 44, offset: 1497},
+							pos:  position{line: 98, col: 44, offset: 1871},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 85, col: 46, offset: 1499},
+							pos:        position{line: 98, col: 46, offset: 1873},
 							val:        ")",
 							ignoreCase: false,
 							want:       "\")\"",
@@ -267,30 +280,30 @@ var g = &grammar{
 		},
 		{
 			name: "NotComparison",
-			pos:  position{line: 89, col: 1, offset: 1527},
+			pos:  position{line: 102, col: 1, offset: 1901},
 			expr: &actionExpr{
-				pos: position{line: 89, col: 18, offset: 1544},
+				pos: position{line: 102, col: 18, offset: 1918},
 				run: (*parser).callonNotComparison1,
 				expr: &seqExpr{
-					pos: position{line: 89, col: 18, offset: 1544},
-					exprs: []interface{}{
+					pos: position{line: 102, col: 18, offset: 1918},
+					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 89, col: 18, offset: 1544},
+							pos:   position{line: 102, col: 18, offset: 1918},
 							label: "op",
 							expr: &ruleRefExpr{
-								pos:  position{line: 89, col: 21, offset: 1547},
+								pos:  position{line: 102, col: 21, offset: 1921},
 								name: "Not",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 89, col: 25, offset: 1551},
+							pos:  position{line: 102, col: 25, offset: 1925},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 89, col: 27, offset: 1553},
+							pos:   position{line: 102, col: 27, offset: 1927},
 							label: "right",
 							expr: &ruleRefExpr{
-								pos:  position{line: 89, col: 33, offset: 1559},
+								pos:  position{line: 102, col: 33, offset: 1933},
 								name: "Comparison",
 							},
 						},
@@ -300,42 +313,42 @@ var g = &grammar{
 		},
 		{
 			name: "LeftRightComparison",
-			pos:  position{line: 98, col: 1, offset: 1718},
+			pos:  position{line: 111, col: 1, offset: 2092},
 			expr: &actionExpr{
-				pos: position{line: 98, col: 24, offset: 1741},
+				pos: position{line: 111, col: 24, offset: 2115},
 				run: (*parser).callonLeftRightComparison1,
 				expr: &seqExpr{
-					pos: position{line: 98, col: 24, offset: 1741},
-					exprs: []interface{}{
+					pos: position{line: 111, col: 24, offset: 2115},
+					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 98, col: 24, offset: 1741},
+							pos:   position{line: 111, col: 24, offset: 2115},
 							label: "left",
 							expr: &ruleRefExpr{
-								pos:  position{line: 98, col: 29, offset: 1746},
+								pos:  position{line: 111, col: 29, offset: 2120},
 								name: "Value",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 98, col: 35, offset: 1752},
+							pos:  position{line: 111, col: 35, offset: 2126},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 98, col: 37, offset: 1754},
+							pos:   position{line: 111, col: 37, offset: 2128},
 							label: "op",
 							expr: &ruleRefExpr{
-								pos:  position{line: 98, col: 40, offset: 1757},
+								pos:  position{line: 111, col: 40, offset: 2131},
 								name: "CompareOperator",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 98, col: 56, offset: 1773},
+							pos:  position{line: 111, col: 56, offset: 2147},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 98, col: 58, offset: 1775},
+							pos:   position{line: 111, col: 58, offset: 2149},
 							label: "right",
 							expr: &ruleRefExpr{
-								pos:  position{line: 98, col: 64, offset: 1781},
+								pos:  position{line: 111, col: 64, offset: 2155},
 								name: "Value",
 							},
 						},
@@ -345,42 +358,42 @@ var g = &grammar{
 		},
 		{
 			name: "LikeComparison",
-			pos:  position{line: 107, col: 1, offset: 1944},
+			pos:  position{line: 120, col: 1, offset: 2318},
 			expr: &actionExpr{
-				pos: position{line: 107, col: 19, offset: 1962},
+				pos: position{line: 120, col: 19, offset: 2336},
 				run: (*parser).callonLikeComparison1,
 				expr: &seqExpr{
-					pos: position{line: 107, col: 19, offset: 1962},
-					exprs: []interface{}{
+					pos: position{line: 120, col: 19, offset: 2336},
+					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 107, col: 19, offset: 1962},
+							pos:   position{line: 120, col: 19, offset: 2336},
 							label: "left",
 							expr: &ruleRefExpr{
-								pos:  position{line: 107, col: 25, offset: 1968},
+								pos:  position{line: 120, col: 25, offset: 2342},
 								name: "Identifier",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 107, col: 37, offset: 1980},
+							pos:  position{line: 120, col: 37, offset: 2354},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 107, col: 39, offset: 1982},
+							pos:   position{line: 120, col: 39, offset: 2356},
 							label: "op",
 							expr: &ruleRefExpr{
-								pos:  position{line: 107, col: 42, offset: 1985},
+								pos:  position{line: 120, col: 42, offset: 2359},
 								name: "Like",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 107, col: 47, offset: 1990},
+							pos:  position{line: 120, col: 47, offset: 2364},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 107, col: 49, offset: 1992},
+							pos:   position{line: 120, col: 49, offset: 2366},
 							label: "right",
 							expr: &ruleRefExpr{
-								pos:  position{line: 107, col: 56, offset: 1999},
+								pos:  position{line: 120, col: 56, offset: 2373},
 								name: "String",
 							},
 						},
@@ -390,62 +403,62 @@ var g = &grammar{
 		},
 		{
 			name: "IsComparison",
-			pos:  position{line: 116, col: 1, offset: 2161},
+			pos:  position{line: 129, col: 1, offset: 2535},
 			expr: &actionExpr{
-				pos: position{line: 116, col: 17, offset: 2177},
+				pos: position{line: 129, col: 17, offset: 2551},
 				run: (*parser).callonIsComparison1,
 				expr: &seqExpr{
-					pos: position{line: 116, col: 17, offset: 2177},
-					exprs: []interface{}{
+					pos: position{line: 129, col: 17, offset: 2551},
+					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 116, col: 17, offset: 2177},
+							pos:   position{line: 129, col: 17, offset: 2551},
 							label: "left",
 							expr: &choiceExpr{
-								pos: position{line: 116, col: 23, offset: 2183},
-								alternatives: []interface{}{
+								pos: position{line: 129, col: 23, offset: 2557},
+								alternatives: []any{
 									&ruleRefExpr{
-										pos:  position{line: 116, col: 23, offset: 2183},
+										pos:  position{line: 129, col: 23, offset: 2557},
 										name: "Identifier",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 116, col: 36, offset: 2196},
+										pos:  position{line: 129, col: 36, offset: 2570},
 										name: "Null",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 116, col: 43, offset: 2203},
+										pos:  position{line: 129, col: 43, offset: 2577},
 										name: "Bool",
 									},
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 116, col: 49, offset: 2209},
+							pos:  position{line: 129, col: 49, offset: 2583},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 116, col: 51, offset: 2211},
+							pos:   position{line: 129, col: 51, offset: 2585},
 							label: "op",
 							expr: &ruleRefExpr{
-								pos:  position{line: 116, col: 54, offset: 2214},
+								pos:  position{line: 129, col: 54, offset: 2588},
 								name: "Is",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 116, col: 57, offset: 2217},
+							pos:  position{line: 129, col: 57, offset: 2591},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 116, col: 59, offset: 2219},
+							pos:   position{line: 129, col: 59, offset: 2593},
 							label: "right",
 							expr: &choiceExpr{
-								pos: position{line: 116, col: 66, offset: 2226},
-								alternatives: []interface{}{
+								pos: position{line: 129, col: 66, offset: 2600},
+								alternatives: []any{
 									&ruleRefExpr{
-										pos:  position{line: 116, col: 66, offset: 2226},
+										pos:  position{line: 129, col: 66, offset: 2600},
 										name: "Null",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 116, col: 73, offset: 2233},
+										pos:  position{line: 129, col: 73, offset: 2607},
 										name: "Bool",
 									},
 								},
@@ -457,42 +470,42 @@ var g = &grammar{
 		},
 		{
 			name: "InComparison",
-			pos:  position{line: 125, col: 1, offset: 2391},
+			pos:  position{line: 138, col: 1, offset: 2765},
 			expr: &actionExpr{
-				pos: position{line: 125, col: 17, offset: 2407},
+				pos: position{line: 138, col: 17, offset: 2781},
 				run: (*parser).callonInComparison1,
 				expr: &seqExpr{
-					pos: position{line: 125, col: 17, offset: 2407},
-					exprs: []interface{}{
+					pos: position{line: 138, col: 17, offset: 2781},
+					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 125, col: 17, offset: 2407},
+							pos:   position{line: 138, col: 17, offset: 2781},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 125, col: 23, offset: 2413},
+								pos:  position{line: 138, col: 23, offset: 2787},
 								name: "Value",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 125, col: 29, offset: 2419},
+							pos:  position{line: 138, col: 29, offset: 2793},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 125, col: 31, offset: 2421},
+							pos:   position{line: 138, col: 31, offset: 2795},
 							label: "op",
 							expr: &ruleRefExpr{
-								pos:  position{line: 125, col: 34, offset: 2424},
+								pos:  position{line: 138, col: 34, offset: 2798},
 								name: "In",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 125, col: 37, offset: 2427},
+							pos:  position{line: 138, col: 37, offset: 2801},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 125, col: 39, offset: 2429},
+							pos:   position{line: 138, col: 39, offset: 2803},
 							label: "rest",
 							expr: &ruleRefExpr{
-								pos:  position{line: 125, col: 44, offset: 2434},
+								pos:  position{line: 138, col: 44, offset: 2808},
 								name: "InList",
 							},
 						},
@@ -502,15 +515,15 @@ var g = &grammar{
 		},
 		{
 			name: "IdentifierComparison",
-			pos:  position{line: 139, col: 1, offset: 2695},
+			pos:  position{line: 152, col: 1, offset: 3069},
 			expr: &actionExpr{
-				pos: position{line: 139, col: 25, offset: 2719},
+				pos: position{line: 152, col: 25, offset: 3093},
 				run: (*parser).callonIdentifierComparison1,
 				expr: &labeledExpr{
-					pos:   position{line: 139, col: 25, offset: 2719},
+					pos:   position{line: 152, col: 25, offset: 3093},
 					label: "i",
 					expr: &ruleRefExpr{
-						pos:  position{line: 139, col: 27, offset: 2721},
+						pos:  position{line: 152, col: 27, offset: 3095},
 						name: "Identifier",
 					},
 				},
@@ -518,16 +531,16 @@ var g = &grammar{
 		},
 		{
 			name: "InList",
-			pos:  position{line: 152, col: 1, offset: 2861},
+			pos:  position{line: 165, col: 1, offset: 3235},
 			expr: &choiceExpr{
-				pos: position{line: 152, col: 11, offset: 2871},
-				alternatives: []interface{}{
+				pos: position{line: 165, col: 11, offset: 3245},
+				alternatives: []any{
 					&ruleRefExpr{
-						pos:  position{line: 152, col: 11, offset: 2871},
+						pos:  position{line: 165, col: 11, offset: 3245},
 						name: "EmptyInList",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 152, col: 25, offset: 2885},
+						pos:  position{line: 165, col: 25, offset: 3259},
 						name: "NonEmptyInList",
 					},
 				},
@@ -535,25 +548,25 @@ var g = &grammar{
 		},
 		{
 			name: "EmptyInList",
-			pos:  position{line: 154, col: 1, offset: 2901},
+			pos:  position{line: 167, col: 1, offset: 3275},
 			expr: &actionExpr{
-				pos: position{line: 154, col: 16, offset: 2916},
+				pos: position{line: 167, col: 16, offset: 3290},
 				run: (*parser).callonEmptyInList1,
 				expr: &seqExpr{
-					pos: position{line: 154, col: 16, offset: 2916},
-					exprs: []interface{}{
+					pos: position{line: 167, col: 16, offset: 3290},
+					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 154, col: 16, offset: 2916},
+							pos:        position{line: 167, col: 16, offset: 3290},
 							val:        "(",
 							ignoreCase: false,
 							want:       "\"(\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 154, col: 20, offset: 2920},
+							pos:  position{line: 167, col: 20, offset: 3294},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 154, col: 22, offset: 2922},
+							pos:        position{line: 167, col: 22, offset: 3296},
 							val:        ")",
 							ignoreCase: false,
 							want:       "\")\"",
@@ -564,55 +577,55 @@ var g = &grammar{
 		},
 		{
 			name: "NonEmptyInList",
-			pos:  position{line: 158, col: 1, offset: 2961},
+			pos:  position{line: 171, col: 1, offset: 3335},
 			expr: &actionExpr{
-				pos: position{line: 158, col: 19, offset: 2979},
+				pos: position{line: 171, col: 19, offset: 3353},
 				run: (*parser).callonNonEmptyInList1,
 				expr: &seqExpr{
-					pos: position{line: 158, col: 19, offset: 2979},
-					exprs: []interface{}{
+					pos: position{line: 171, col: 19, offset: 3353},
+					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 158, col: 19, offset: 2979},
+							pos:        position{line: 171, col: 19, offset: 3353},
 							val:        "(",
 							ignoreCase: false,
 							want:       "\"(\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 158, col: 23, offset: 2983},
+							pos:  position{line: 171, col: 23, offset: 3357},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 158, col: 25, offset: 2985},
+							pos:   position{line: 171, col: 25, offset: 3359},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 158, col: 31, offset: 2991},
+								pos:  position{line: 171, col: 31, offset: 3365},
 								name: "UnbracketedValue",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 158, col: 48, offset: 3008},
+							pos:   position{line: 171, col: 48, offset: 3382},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 158, col: 53, offset: 3013},
+								pos: position{line: 171, col: 53, offset: 3387},
 								expr: &seqExpr{
-									pos: position{line: 158, col: 55, offset: 3015},
-									exprs: []interface{}{
+									pos: position{line: 171, col: 55, offset: 3389},
+									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 158, col: 55, offset: 3015},
+											pos:  position{line: 171, col: 55, offset: 3389},
 											name: "_",
 										},
 										&litMatcher{
-											pos:        position{line: 158, col: 57, offset: 3017},
+											pos:        position{line: 171, col: 57, offset: 3391},
 											val:        ",",
 											ignoreCase: false,
 											want:       "\",\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 158, col: 61, offset: 3021},
+											pos:  position{line: 171, col: 61, offset: 3395},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 158, col: 63, offset: 3023},
+											pos:  position{line: 171, col: 63, offset: 3397},
 											name: "UnbracketedValue",
 										},
 									},
@@ -620,11 +633,11 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 158, col: 82, offset: 3042},
+							pos:  position{line: 171, col: 82, offset: 3416},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 158, col: 84, offset: 3044},
+							pos:        position{line: 171, col: 84, offset: 3418},
 							val:        ")",
 							ignoreCase: false,
 							want:       "\")\"",
@@ -635,16 +648,16 @@ var g = &grammar{
 		},
 		{
 			name: "Value",
-			pos:  position{line: 168, col: 1, offset: 3120},
+			pos:  position{line: 181, col: 1, offset: 3494},
 			expr: &choiceExpr{
-				pos: position{line: 168, col: 10, offset: 3129},
-				alternatives: []interface{}{
+				pos: position{line: 181, col: 10, offset: 3503},
+				alternatives: []any{
 					&ruleRefExpr{
-						pos:  position{line: 168, col: 10, offset: 3129},
+						pos:  position{line: 181, col: 10, offset: 3503},
 						name: "BracketedValue",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 168, col: 27, offset: 3146},
+						pos:  position{line: 181, col: 27, offset: 3520},
 						name: "UnbracketedValue",
 					},
 				},
@@ -652,37 +665,37 @@ var g = &grammar{
 		},
 		{
 			name: "BracketedValue",
-			pos:  position{line: 170, col: 1, offset: 3164},
+			pos:  position{line: 183, col: 1, offset: 3538},
 			expr: &actionExpr{
-				pos: position{line: 170, col: 19, offset: 3182},
+				pos: position{line: 183, col: 19, offset: 3556},
 				run: (*parser).callonBracketedValue1,
 				expr: &seqExpr{
-					pos: position{line: 170, col: 19, offset: 3182},
-					exprs: []interface{}{
+					pos: position{line: 183, col: 19, offset: 3556},
+					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 170, col: 19, offset: 3182},
+							pos:        position{line: 183, col: 19, offset: 3556},
 							val:        "(",
 							ignoreCase: false,
 							want:       "\"(\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 170, col: 23, offset: 3186},
+							pos:  position{line: 183, col: 23, offset: 3560},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 170, col: 25, offset: 3188},
+							pos:   position{line: 183, col: 25, offset: 3562},
 							label: "i",
 							expr: &ruleRefExpr{
-								pos:  position{line: 170, col: 27, offset: 3190},
+								pos:  position{line: 183, col: 27, offset: 3564},
 								name: "Value",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 170, col: 33, offset: 3196},
+							pos:  position{line: 183, col: 33, offset: 3570},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 170, col: 35, offset: 3198},
+							pos:        position{line: 183, col: 35, offset: 3572},
 							val:        ")",
 							ignoreCase: false,
 							want:       "\")\"",
@@ -693,24 +706,24 @@ var g = &grammar{
 		},
 		{
 			name: "UnbracketedValue",
-			pos:  position{line: 174, col: 1, offset: 3223},
+			pos:  position{line: 187, col: 1, offset: 3597},
 			expr: &choiceExpr{
-				pos: position{line: 174, col: 21, offset: 3243},
-				alternatives: []interface{}{
+				pos: position{line: 187, col: 21, offset: 3617},
+				alternatives: []any{
 					&ruleRefExpr{
-						pos:  position{line: 174, col: 21, offset: 3243},
+						pos:  position{line: 187, col: 21, offset: 3617},
 						name: "TimeCalculation",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 174, col: 39, offset: 3261},
+						pos:  position{line: 187, col: 39, offset: 3635},
 						name: "Constant",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 174, col: 50, offset: 3272},
+						pos:  position{line: 187, col: 50, offset: 3646},
 						name: "Jsonb",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 174, col: 58, offset: 3280},
+						pos:  position{line: 187, col: 58, offset: 3654},
 						name: "Identifier",
 					},
 				},
@@ -718,16 +731,16 @@ var g = &grammar{
 		},
 		{
 			name: "Identifier",
-			pos:  position{line: 181, col: 1, offset: 3315},
+			pos:  position{line: 194, col: 1, offset: 3689},
 			expr: &choiceExpr{
-				pos: position{line: 181, col: 15, offset: 3329},
-				alternatives: []interface{}{
+				pos: position{line: 194, col: 15, offset: 3703},
+				alternatives: []any{
 					&ruleRefExpr{
-						pos:  position{line: 181, col: 15, offset: 3329},
+						pos:  position{line: 194, col: 15, offset: 3703},
 						name: "Jsonb",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 181, col: 23, offset: 3337},
+						pos:  position{line: 194, col: 23, offset: 3711},
 						name: "ColumnIdentifier",
 					},
 				},
@@ -735,16 +748,16 @@ var g = &grammar{
 		},
 		{
 			name: "ColumnIdentifier",
-			pos:  position{line: 183, col: 1, offset: 3355},
+			pos:  position{line: 196, col: 1, offset: 3729},
 			expr: &choiceExpr{
-				pos: position{line: 183, col: 21, offset: 3375},
-				alternatives: []interface{}{
+				pos: position{line: 196, col: 21, offset: 3749},
+				alternatives: []any{
 					&ruleRefExpr{
-						pos:  position{line: 183, col: 21, offset: 3375},
+						pos:  position{line: 196, col: 21, offset: 3749},
 						name: "QuotedIdentifier",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 183, col: 40, offset: 3394},
+						pos:  position{line: 196, col: 40, offset: 3768},
 						name: "UnquotedIdentifier",
 					},
 				},
@@ -752,32 +765,32 @@ var g = &grammar{
 		},
 		{
 			name: "QuotedIdentifier",
-			pos:  position{line: 185, col: 1, offset: 3414},
+			pos:  position{line: 198, col: 1, offset: 3788},
 			expr: &actionExpr{
-				pos: position{line: 185, col: 21, offset: 3434},
+				pos: position{line: 198, col: 21, offset: 3808},
 				run: (*parser).callonQuotedIdentifier1,
 				expr: &seqExpr{
-					pos: position{line: 185, col: 21, offset: 3434},
-					exprs: []interface{}{
+					pos: position{line: 198, col: 21, offset: 3808},
+					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 185, col: 21, offset: 3434},
+							pos:        position{line: 198, col: 21, offset: 3808},
 							val:        "\"",
 							ignoreCase: false,
 							want:       "\"\\\"\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 185, col: 25, offset: 3438},
+							pos: position{line: 198, col: 25, offset: 3812},
 							expr: &choiceExpr{
-								pos: position{line: 185, col: 26, offset: 3439},
-								alternatives: []interface{}{
+								pos: position{line: 198, col: 26, offset: 3813},
+								alternatives: []any{
 									&litMatcher{
-										pos:        position{line: 185, col: 26, offset: 3439},
+										pos:        position{line: 198, col: 26, offset: 3813},
 										val:        "\"\"",
 										ignoreCase: false,
 										want:       "\"\\\"\\\"\"",
 									},
 									&charClassMatcher{
-										pos:        position{line: 185, col: 33, offset: 3446},
+										pos:        position{line: 198, col: 33, offset: 3820},
 										val:        "[^\"]",
 										chars:      []rune{'"'},
 										ignoreCase: false,
@@ -787,7 +800,7 @@ var g = &grammar{
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 185, col: 40, offset: 3453},
+							pos:        position{line: 198, col: 40, offset: 3827},
 							val:        "\"",
 							ignoreCase: false,
 							want:       "\"\\\"\"",
@@ -798,15 +811,15 @@ var g = &grammar{
 		},
 		{
 			name: "UnquotedIdentifier",
-			pos:  position{line: 196, col: 1, offset: 3649},
+			pos:  position{line: 210, col: 1, offset: 4046},
 			expr: &actionExpr{
-				pos: position{line: 196, col: 23, offset: 3671},
+				pos: position{line: 210, col: 23, offset: 4068},
 				run: (*parser).callonUnquotedIdentifier1,
 				expr: &seqExpr{
-					pos: position{line: 196, col: 23, offset: 3671},
-					exprs: []interface{}{
+					pos: position{line: 210, col: 23, offset: 4068},
+					exprs: []any{
 						&charClassMatcher{
-							pos:        position{line: 196, col: 23, offset: 3671},
+							pos:        position{line: 210, col: 23, offset: 4068},
 							val:        "[A-Za-z_]",
 							chars:      []rune{'_'},
 							ranges:     []rune{'A', 'Z', 'a', 'z'},
@@ -814,9 +827,9 @@ var g = &grammar{
 							inverted:   false,
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 196, col: 32, offset: 3680},
+							pos: position{line: 210, col: 32, offset: 4077},
 							expr: &charClassMatcher{
-								pos:        position{line: 196, col: 32, offset: 3680},
+								pos:        position{line: 210, col: 32, offset: 4077},
 								val:        "[A-Za-z0-9_]",
 								chars:      []rune{'_'},
 								ranges:     []rune{'A', 'Z', 'a', 'z', '0', '9'},
@@ -830,67 +843,67 @@ var g = &grammar{
 		},
 		{
 			name: "Jsonb",
-			pos:  position{line: 206, col: 1, offset: 3843},
+			pos:  position{line: 221, col: 1, offset: 4263},
 			expr: &actionExpr{
-				pos: position{line: 206, col: 10, offset: 3852},
+				pos: position{line: 221, col: 10, offset: 4272},
 				run: (*parser).callonJsonb1,
 				expr: &seqExpr{
-					pos: position{line: 206, col: 10, offset: 3852},
-					exprs: []interface{}{
+					pos: position{line: 221, col: 10, offset: 4272},
+					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 206, col: 10, offset: 3852},
+							pos:   position{line: 221, col: 10, offset: 4272},
 							label: "i",
 							expr: &ruleRefExpr{
-								pos:  position{line: 206, col: 12, offset: 3854},
+								pos:  position{line: 221, col: 12, offset: 4274},
 								name: "ColumnIdentifier",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 206, col: 29, offset: 3871},
+							pos:  position{line: 221, col: 29, offset: 4291},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 206, col: 31, offset: 3873},
+							pos:   position{line: 221, col: 31, offset: 4293},
 							label: "op",
 							expr: &ruleRefExpr{
-								pos:  position{line: 206, col: 34, offset: 3876},
+								pos:  position{line: 221, col: 34, offset: 4296},
 								name: "JsonbOperator",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 206, col: 48, offset: 3890},
+							pos:  position{line: 221, col: 48, offset: 4310},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 206, col: 50, offset: 3892},
+							pos:   position{line: 221, col: 50, offset: 4312},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 206, col: 56, offset: 3898},
+								pos:  position{line: 221, col: 56, offset: 4318},
 								name: "JsonbField",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 206, col: 67, offset: 3909},
+							pos:   position{line: 221, col: 67, offset: 4329},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 206, col: 72, offset: 3914},
+								pos: position{line: 221, col: 72, offset: 4334},
 								expr: &seqExpr{
-									pos: position{line: 206, col: 73, offset: 3915},
-									exprs: []interface{}{
+									pos: position{line: 221, col: 73, offset: 4335},
+									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 206, col: 73, offset: 3915},
+											pos:  position{line: 221, col: 73, offset: 4335},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 206, col: 75, offset: 3917},
+											pos:  position{line: 221, col: 75, offset: 4337},
 											name: "JsonbOperator",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 206, col: 89, offset: 3931},
+											pos:  position{line: 221, col: 89, offset: 4351},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 206, col: 91, offset: 3933},
+											pos:  position{line: 221, col: 91, offset: 4353},
 											name: "JsonbField",
 										},
 									},
@@ -903,16 +916,16 @@ var g = &grammar{
 		},
 		{
 			name: "JsonbField",
-			pos:  position{line: 217, col: 1, offset: 4221},
+			pos:  position{line: 232, col: 1, offset: 4641},
 			expr: &choiceExpr{
-				pos: position{line: 217, col: 15, offset: 4235},
-				alternatives: []interface{}{
+				pos: position{line: 232, col: 15, offset: 4655},
+				alternatives: []any{
 					&ruleRefExpr{
-						pos:  position{line: 217, col: 15, offset: 4235},
+						pos:  position{line: 232, col: 15, offset: 4655},
 						name: "String",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 217, col: 24, offset: 4244},
+						pos:  position{line: 232, col: 24, offset: 4664},
 						name: "Integer",
 					},
 				},
@@ -920,23 +933,23 @@ var g = &grammar{
 		},
 		{
 			name: "JsonbOperator",
-			pos:  position{line: 219, col: 1, offset: 4253},
+			pos:  position{line: 234, col: 1, offset: 4673},
 			expr: &actionExpr{
-				pos: position{line: 219, col: 18, offset: 4270},
+				pos: position{line: 234, col: 18, offset: 4690},
 				run: (*parser).callonJsonbOperator1,
 				expr: &seqExpr{
-					pos: position{line: 219, col: 18, offset: 4270},
-					exprs: []interface{}{
+					pos: position{line: 234, col: 18, offset: 4690},
+					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 219, col: 18, offset: 4270},
+							pos:        position{line: 234, col: 18, offset: 4690},
 							val:        "->",
 							ignoreCase: false,
 							want:       "\"->\"",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 219, col: 23, offset: 4275},
+							pos: position{line: 234, col: 23, offset: 4695},
 							expr: &litMatcher{
-								pos:        position{line: 219, col: 23, offset: 4275},
+								pos:        position{line: 234, col: 23, offset: 4695},
 								val:        ">",
 								ignoreCase: false,
 								want:       "\">\"",
@@ -948,51 +961,51 @@ var g = &grammar{
 		},
 		{
 			name: "CompareOperator",
-			pos:  position{line: 235, col: 1, offset: 4473},
+			pos:  position{line: 251, col: 1, offset: 4916},
 			expr: &actionExpr{
-				pos: position{line: 235, col: 20, offset: 4492},
+				pos: position{line: 251, col: 20, offset: 4935},
 				run: (*parser).callonCompareOperator1,
 				expr: &choiceExpr{
-					pos: position{line: 235, col: 21, offset: 4493},
-					alternatives: []interface{}{
+					pos: position{line: 251, col: 21, offset: 4936},
+					alternatives: []any{
 						&litMatcher{
-							pos:        position{line: 235, col: 21, offset: 4493},
+							pos:        position{line: 251, col: 21, offset: 4936},
 							val:        "<=",
 							ignoreCase: false,
 							want:       "\"<=\"",
 						},
 						&litMatcher{
-							pos:        position{line: 235, col: 28, offset: 4500},
+							pos:        position{line: 251, col: 28, offset: 4943},
 							val:        "<>",
 							ignoreCase: false,
 							want:       "\"<>\"",
 						},
 						&litMatcher{
-							pos:        position{line: 235, col: 35, offset: 4507},
+							pos:        position{line: 251, col: 35, offset: 4950},
 							val:        ">=",
 							ignoreCase: false,
 							want:       "\">=\"",
 						},
 						&litMatcher{
-							pos:        position{line: 235, col: 42, offset: 4514},
+							pos:        position{line: 251, col: 42, offset: 4957},
 							val:        "!=",
 							ignoreCase: false,
 							want:       "\"!=\"",
 						},
 						&litMatcher{
-							pos:        position{line: 235, col: 49, offset: 4521},
+							pos:        position{line: 251, col: 49, offset: 4964},
 							val:        "<",
 							ignoreCase: false,
 							want:       "\"<\"",
 						},
 						&litMatcher{
-							pos:        position{line: 235, col: 55, offset: 4527},
+							pos:        position{line: 251, col: 55, offset: 4970},
 							val:        "=",
 							ignoreCase: false,
 							want:       "\"=\"",
 						},
 						&litMatcher{
-							pos:        position{line: 235, col: 61, offset: 4533},
+							pos:        position{line: 251, col: 61, offset: 4976},
 							val:        ">",
 							ignoreCase: false,
 							want:       "\">\"",
@@ -1003,12 +1016,12 @@ var g = &grammar{
 		},
 		{
 			name: "And",
-			pos:  position{line: 245, col: 1, offset: 4653},
+			pos:  position{line: 262, col: 1, offset: 5119},
 			expr: &actionExpr{
-				pos: position{line: 245, col: 8, offset: 4660},
+				pos: position{line: 262, col: 8, offset: 5126},
 				run: (*parser).callonAnd1,
 				expr: &litMatcher{
-					pos:        position{line: 245, col: 8, offset: 4660},
+					pos:        position{line: 262, col: 8, offset: 5126},
 					val:        "and",
 					ignoreCase: true,
 					want:       "\"and\"i",
@@ -1017,12 +1030,12 @@ var g = &grammar{
 		},
 		{
 			name: "Or",
-			pos:  position{line: 255, col: 1, offset: 4790},
+			pos:  position{line: 273, col: 1, offset: 5279},
 			expr: &actionExpr{
-				pos: position{line: 255, col: 7, offset: 4796},
+				pos: position{line: 273, col: 7, offset: 5285},
 				run: (*parser).callonOr1,
 				expr: &litMatcher{
-					pos:        position{line: 255, col: 7, offset: 4796},
+					pos:        position{line: 273, col: 7, offset: 5285},
 					val:        "or",
 					ignoreCase: true,
 					want:       "\"or\"i",
@@ -1031,12 +1044,12 @@ var g = &grammar{
 		},
 		{
 			name: "Not",
-			pos:  position{line: 265, col: 1, offset: 4924},
+			pos:  position{line: 284, col: 1, offset: 5436},
 			expr: &actionExpr{
-				pos: position{line: 265, col: 8, offset: 4931},
+				pos: position{line: 284, col: 8, offset: 5443},
 				run: (*parser).callonNot1,
 				expr: &litMatcher{
-					pos:        position{line: 265, col: 8, offset: 4931},
+					pos:        position{line: 284, col: 8, offset: 5443},
 					val:        "not",
 					ignoreCase: true,
 					want:       "\"not\"i",
@@ -1045,29 +1058,29 @@ var g = &grammar{
 		},
 		{
 			name: "In",
-			pos:  position{line: 275, col: 1, offset: 5061},
+			pos:  position{line: 295, col: 1, offset: 5596},
 			expr: &actionExpr{
-				pos: position{line: 275, col: 7, offset: 5067},
+				pos: position{line: 295, col: 7, offset: 5602},
 				run: (*parser).callonIn1,
 				expr: &seqExpr{
-					pos: position{line: 275, col: 7, offset: 5067},
-					exprs: []interface{}{
+					pos: position{line: 295, col: 7, offset: 5602},
+					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 275, col: 7, offset: 5067},
+							pos:   position{line: 295, col: 7, offset: 5602},
 							label: "not",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 275, col: 11, offset: 5071},
+								pos: position{line: 295, col: 11, offset: 5606},
 								expr: &seqExpr{
-									pos: position{line: 275, col: 12, offset: 5072},
-									exprs: []interface{}{
+									pos: position{line: 295, col: 12, offset: 5607},
+									exprs: []any{
 										&litMatcher{
-											pos:        position{line: 275, col: 12, offset: 5072},
+											pos:        position{line: 295, col: 12, offset: 5607},
 											val:        "not",
 											ignoreCase: true,
 											want:       "\"not\"i",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 275, col: 19, offset: 5079},
+											pos:  position{line: 295, col: 19, offset: 5614},
 											name: "_",
 										},
 									},
@@ -1075,7 +1088,7 @@ var g = &grammar{
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 275, col: 23, offset: 5083},
+							pos:        position{line: 295, col: 23, offset: 5618},
 							val:        "in",
 							ignoreCase: true,
 							want:       "\"in\"i",
@@ -1086,29 +1099,29 @@ var g = &grammar{
 		},
 		{
 			name: "Like",
-			pos:  position{line: 288, col: 1, offset: 5256},
+			pos:  position{line: 309, col: 1, offset: 5814},
 			expr: &actionExpr{
-				pos: position{line: 288, col: 9, offset: 5264},
+				pos: position{line: 309, col: 9, offset: 5822},
 				run: (*parser).callonLike1,
 				expr: &seqExpr{
-					pos: position{line: 288, col: 9, offset: 5264},
-					exprs: []interface{}{
+					pos: position{line: 309, col: 9, offset: 5822},
+					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 288, col: 9, offset: 5264},
+							pos:   position{line: 309, col: 9, offset: 5822},
 							label: "not",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 288, col: 13, offset: 5268},
+								pos: position{line: 309, col: 13, offset: 5826},
 								expr: &seqExpr{
-									pos: position{line: 288, col: 14, offset: 5269},
-									exprs: []interface{}{
+									pos: position{line: 309, col: 14, offset: 5827},
+									exprs: []any{
 										&litMatcher{
-											pos:        position{line: 288, col: 14, offset: 5269},
+											pos:        position{line: 309, col: 14, offset: 5827},
 											val:        "not",
 											ignoreCase: true,
 											want:       "\"not\"i",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 288, col: 21, offset: 5276},
+											pos:  position{line: 309, col: 21, offset: 5834},
 											name: "_",
 										},
 									},
@@ -1116,10 +1129,10 @@ var g = &grammar{
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 288, col: 25, offset: 5280},
+							pos:   position{line: 309, col: 25, offset: 5838},
 							label: "like",
 							expr: &ruleRefExpr{
-								pos:  position{line: 288, col: 30, offset: 5285},
+								pos:  position{line: 309, col: 30, offset: 5843},
 								name: "LikeOrIlike",
 							},
 						},
@@ -1129,21 +1142,21 @@ var g = &grammar{
 		},
 		{
 			name: "LikeOrIlike",
-			pos:  position{line: 302, col: 1, offset: 5519},
+			pos:  position{line: 324, col: 1, offset: 6100},
 			expr: &actionExpr{
-				pos: position{line: 302, col: 16, offset: 5534},
+				pos: position{line: 324, col: 16, offset: 6115},
 				run: (*parser).callonLikeOrIlike1,
 				expr: &choiceExpr{
-					pos: position{line: 302, col: 17, offset: 5535},
-					alternatives: []interface{}{
+					pos: position{line: 324, col: 17, offset: 6116},
+					alternatives: []any{
 						&litMatcher{
-							pos:        position{line: 302, col: 17, offset: 5535},
+							pos:        position{line: 324, col: 17, offset: 6116},
 							val:        "like",
 							ignoreCase: true,
 							want:       "\"like\"i",
 						},
 						&litMatcher{
-							pos:        position{line: 302, col: 27, offset: 5545},
+							pos:        position{line: 324, col: 27, offset: 6126},
 							val:        "ilike",
 							ignoreCase: true,
 							want:       "\"ilike\"i",
@@ -1154,33 +1167,33 @@ var g = &grammar{
 		},
 		{
 			name: "Is",
-			pos:  position{line: 307, col: 1, offset: 5602},
+			pos:  position{line: 329, col: 1, offset: 6183},
 			expr: &actionExpr{
-				pos: position{line: 307, col: 7, offset: 5608},
+				pos: position{line: 329, col: 7, offset: 6189},
 				run: (*parser).callonIs1,
 				expr: &seqExpr{
-					pos: position{line: 307, col: 7, offset: 5608},
-					exprs: []interface{}{
+					pos: position{line: 329, col: 7, offset: 6189},
+					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 307, col: 7, offset: 5608},
+							pos:        position{line: 329, col: 7, offset: 6189},
 							val:        "is",
 							ignoreCase: true,
 							want:       "\"is\"i",
 						},
 						&labeledExpr{
-							pos:   position{line: 307, col: 13, offset: 5614},
+							pos:   position{line: 329, col: 13, offset: 6195},
 							label: "not",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 307, col: 17, offset: 5618},
+								pos: position{line: 329, col: 17, offset: 6199},
 								expr: &seqExpr{
-									pos: position{line: 307, col: 18, offset: 5619},
-									exprs: []interface{}{
+									pos: position{line: 329, col: 18, offset: 6200},
+									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 307, col: 18, offset: 5619},
+											pos:  position{line: 329, col: 18, offset: 6200},
 											name: "_",
 										},
 										&litMatcher{
-											pos:        position{line: 307, col: 20, offset: 5621},
+											pos:        position{line: 329, col: 20, offset: 6202},
 											val:        "not",
 											ignoreCase: true,
 											want:       "\"not\"i",
@@ -1195,43 +1208,43 @@ var g = &grammar{
 		},
 		{
 			name: "TimeCalculation",
-			pos:  position{line: 327, col: 1, offset: 5870},
+			pos:  position{line: 350, col: 1, offset: 6474},
 			expr: &actionExpr{
-				pos: position{line: 327, col: 20, offset: 5889},
+				pos: position{line: 350, col: 20, offset: 6493},
 				run: (*parser).callonTimeCalculation1,
 				expr: &seqExpr{
-					pos: position{line: 327, col: 20, offset: 5889},
-					exprs: []interface{}{
+					pos: position{line: 350, col: 20, offset: 6493},
+					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 327, col: 20, offset: 5889},
+							pos:   position{line: 350, col: 20, offset: 6493},
 							label: "now",
 							expr: &ruleRefExpr{
-								pos:  position{line: 327, col: 24, offset: 5893},
+								pos:  position{line: 350, col: 24, offset: 6497},
 								name: "NoArgsFunction",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 327, col: 39, offset: 5908},
+							pos:   position{line: 350, col: 39, offset: 6512},
 							label: "interval",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 327, col: 48, offset: 5917},
+								pos: position{line: 350, col: 48, offset: 6521},
 								expr: &seqExpr{
-									pos: position{line: 327, col: 49, offset: 5918},
-									exprs: []interface{}{
+									pos: position{line: 350, col: 49, offset: 6522},
+									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 327, col: 49, offset: 5918},
+											pos:  position{line: 350, col: 49, offset: 6522},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 327, col: 51, offset: 5920},
+											pos:  position{line: 350, col: 51, offset: 6524},
 											name: "Add",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 327, col: 55, offset: 5924},
+											pos:  position{line: 350, col: 55, offset: 6528},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 327, col: 57, offset: 5926},
+											pos:  position{line: 350, col: 57, offset: 6530},
 											name: "StringOperatorFunction",
 										},
 									},
@@ -1244,21 +1257,21 @@ var g = &grammar{
 		},
 		{
 			name: "Add",
-			pos:  position{line: 342, col: 1, offset: 6334},
+			pos:  position{line: 366, col: 1, offset: 6961},
 			expr: &actionExpr{
-				pos: position{line: 342, col: 8, offset: 6341},
+				pos: position{line: 366, col: 8, offset: 6968},
 				run: (*parser).callonAdd1,
 				expr: &choiceExpr{
-					pos: position{line: 342, col: 9, offset: 6342},
-					alternatives: []interface{}{
+					pos: position{line: 366, col: 9, offset: 6969},
+					alternatives: []any{
 						&litMatcher{
-							pos:        position{line: 342, col: 9, offset: 6342},
+							pos:        position{line: 366, col: 9, offset: 6969},
 							val:        "+",
 							ignoreCase: false,
 							want:       "\"+\"",
 						},
 						&litMatcher{
-							pos:        position{line: 342, col: 15, offset: 6348},
+							pos:        position{line: 366, col: 15, offset: 6975},
 							val:        "-",
 							ignoreCase: false,
 							want:       "\"-\"",
@@ -1269,16 +1282,16 @@ var g = &grammar{
 		},
 		{
 			name: "Function",
-			pos:  position{line: 352, col: 1, offset: 6468},
+			pos:  position{line: 377, col: 1, offset: 7118},
 			expr: &choiceExpr{
-				pos: position{line: 352, col: 13, offset: 6480},
-				alternatives: []interface{}{
+				pos: position{line: 377, col: 13, offset: 7130},
+				alternatives: []any{
 					&ruleRefExpr{
-						pos:  position{line: 352, col: 13, offset: 6480},
+						pos:  position{line: 377, col: 13, offset: 7130},
 						name: "StringOperatorFunction",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 352, col: 38, offset: 6505},
+						pos:  position{line: 377, col: 38, offset: 7155},
 						name: "NoArgsFunction",
 					},
 				},
@@ -1286,19 +1299,19 @@ var g = &grammar{
 		},
 		{
 			name: "NoArgsFunction",
-			pos:  position{line: 354, col: 1, offset: 6521},
+			pos:  position{line: 379, col: 1, offset: 7171},
 			expr: &actionExpr{
-				pos: position{line: 354, col: 19, offset: 6539},
+				pos: position{line: 379, col: 19, offset: 7189},
 				run: (*parser).callonNoArgsFunction1,
 				expr: &seqExpr{
-					pos: position{line: 354, col: 19, offset: 6539},
-					exprs: []interface{}{
+					pos: position{line: 379, col: 19, offset: 7189},
+					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 354, col: 19, offset: 6539},
+							pos:  position{line: 379, col: 19, offset: 7189},
 							name: "NoArgsFunctionName",
 						},
 						&litMatcher{
-							pos:        position{line: 354, col: 38, offset: 6558},
+							pos:        position{line: 379, col: 38, offset: 7208},
 							val:        "()",
 							ignoreCase: false,
 							want:       "\"()\"",
@@ -1309,9 +1322,9 @@ var g = &grammar{
 		},
 		{
 			name: "NoArgsFunctionName",
-			pos:  position{line: 369, col: 1, offset: 6828},
+			pos:  position{line: 395, col: 1, offset: 7503},
 			expr: &litMatcher{
-				pos:        position{line: 369, col: 23, offset: 6850},
+				pos:        position{line: 395, col: 23, offset: 7525},
 				val:        "now",
 				ignoreCase: true,
 				want:       "\"now\"i",
@@ -1319,30 +1332,30 @@ var g = &grammar{
 		},
 		{
 			name: "StringOperatorFunction",
-			pos:  position{line: 371, col: 1, offset: 6858},
+			pos:  position{line: 397, col: 1, offset: 7533},
 			expr: &actionExpr{
-				pos: position{line: 371, col: 27, offset: 6884},
+				pos: position{line: 397, col: 27, offset: 7559},
 				run: (*parser).callonStringOperatorFunction1,
 				expr: &seqExpr{
-					pos: position{line: 371, col: 27, offset: 6884},
-					exprs: []interface{}{
+					pos: position{line: 397, col: 27, offset: 7559},
+					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 371, col: 27, offset: 6884},
+							pos:   position{line: 397, col: 27, offset: 7559},
 							label: "fn",
 							expr: &ruleRefExpr{
-								pos:  position{line: 371, col: 30, offset: 6887},
+								pos:  position{line: 397, col: 30, offset: 7562},
 								name: "StringOperatorFunctionName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 371, col: 57, offset: 6914},
+							pos:  position{line: 397, col: 57, offset: 7589},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 371, col: 59, offset: 6916},
+							pos:   position{line: 397, col: 59, offset: 7591},
 							label: "s",
 							expr: &ruleRefExpr{
-								pos:  position{line: 371, col: 61, offset: 6918},
+								pos:  position{line: 397, col: 61, offset: 7593},
 								name: "String",
 							},
 						},
@@ -1352,9 +1365,9 @@ var g = &grammar{
 		},
 		{
 			name: "StringOperatorFunctionName",
-			pos:  position{line: 386, col: 1, offset: 7202},
+			pos:  position{line: 413, col: 1, offset: 7902},
 			expr: &litMatcher{
-				pos:        position{line: 386, col: 31, offset: 7232},
+				pos:        position{line: 413, col: 31, offset: 7932},
 				val:        "interval",
 				ignoreCase: true,
 				want:       "\"interval\"i",
@@ -1362,20 +1375,20 @@ var g = &grammar{
 		},
 		{
 			name: "Constant",
-			pos:  position{line: 393, col: 1, offset: 7266},
+			pos:  position{line: 420, col: 1, offset: 7966},
 			expr: &choiceExpr{
-				pos: position{line: 393, col: 13, offset: 7278},
-				alternatives: []interface{}{
+				pos: position{line: 420, col: 13, offset: 7978},
+				alternatives: []any{
 					&ruleRefExpr{
-						pos:  position{line: 393, col: 13, offset: 7278},
+						pos:  position{line: 420, col: 13, offset: 7978},
 						name: "Bool",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 393, col: 20, offset: 7285},
+						pos:  position{line: 420, col: 20, offset: 7985},
 						name: "Number",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 393, col: 29, offset: 7294},
+						pos:  position{line: 420, col: 29, offset: 7994},
 						name: "String",
 					},
 				},
@@ -1383,32 +1396,32 @@ var g = &grammar{
 		},
 		{
 			name: "String",
-			pos:  position{line: 395, col: 1, offset: 7302},
+			pos:  position{line: 422, col: 1, offset: 8002},
 			expr: &actionExpr{
-				pos: position{line: 395, col: 11, offset: 7312},
+				pos: position{line: 422, col: 11, offset: 8012},
 				run: (*parser).callonString1,
 				expr: &seqExpr{
-					pos: position{line: 395, col: 11, offset: 7312},
-					exprs: []interface{}{
+					pos: position{line: 422, col: 11, offset: 8012},
+					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 395, col: 11, offset: 7312},
+							pos:        position{line: 422, col: 11, offset: 8012},
 							val:        "'",
 							ignoreCase: false,
 							want:       "\"'\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 395, col: 15, offset: 7316},
+							pos: position{line: 422, col: 15, offset: 8016},
 							expr: &choiceExpr{
-								pos: position{line: 395, col: 16, offset: 7317},
-								alternatives: []interface{}{
+								pos: position{line: 422, col: 16, offset: 8017},
+								alternatives: []any{
 									&litMatcher{
-										pos:        position{line: 395, col: 16, offset: 7317},
+										pos:        position{line: 422, col: 16, offset: 8017},
 										val:        "''",
 										ignoreCase: false,
 										want:       "\"''\"",
 									},
 									&charClassMatcher{
-										pos:        position{line: 395, col: 23, offset: 7324},
+										pos:        position{line: 422, col: 23, offset: 8024},
 										val:        "[^']",
 										chars:      []rune{'\''},
 										ignoreCase: false,
@@ -1418,7 +1431,7 @@ var g = &grammar{
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 395, col: 30, offset: 7331},
+							pos:        position{line: 422, col: 30, offset: 8031},
 							val:        "'",
 							ignoreCase: false,
 							want:       "\"'\"",
@@ -1429,41 +1442,41 @@ var g = &grammar{
 		},
 		{
 			name: "Number",
-			pos:  position{line: 406, col: 1, offset: 7516},
+			pos:  position{line: 434, col: 1, offset: 8239},
 			expr: &actionExpr{
-				pos: position{line: 406, col: 11, offset: 7526},
+				pos: position{line: 434, col: 11, offset: 8249},
 				run: (*parser).callonNumber1,
 				expr: &seqExpr{
-					pos: position{line: 406, col: 11, offset: 7526},
-					exprs: []interface{}{
+					pos: position{line: 434, col: 11, offset: 8249},
+					exprs: []any{
 						&zeroOrOneExpr{
-							pos: position{line: 406, col: 11, offset: 7526},
+							pos: position{line: 434, col: 11, offset: 8249},
 							expr: &litMatcher{
-								pos:        position{line: 406, col: 11, offset: 7526},
+								pos:        position{line: 434, col: 11, offset: 8249},
 								val:        "-",
 								ignoreCase: false,
 								want:       "\"-\"",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 406, col: 16, offset: 7531},
+							pos:  position{line: 434, col: 16, offset: 8254},
 							name: "Integer",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 406, col: 24, offset: 7539},
+							pos: position{line: 434, col: 24, offset: 8262},
 							expr: &seqExpr{
-								pos: position{line: 406, col: 26, offset: 7541},
-								exprs: []interface{}{
+								pos: position{line: 434, col: 26, offset: 8264},
+								exprs: []any{
 									&litMatcher{
-										pos:        position{line: 406, col: 26, offset: 7541},
+										pos:        position{line: 434, col: 26, offset: 8264},
 										val:        ".",
 										ignoreCase: false,
 										want:       "\".\"",
 									},
 									&oneOrMoreExpr{
-										pos: position{line: 406, col: 30, offset: 7545},
+										pos: position{line: 434, col: 30, offset: 8268},
 										expr: &ruleRefExpr{
-											pos:  position{line: 406, col: 30, offset: 7545},
+											pos:  position{line: 434, col: 30, offset: 8268},
 											name: "DecimalDigit",
 										},
 									},
@@ -1471,9 +1484,9 @@ var g = &grammar{
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 406, col: 47, offset: 7562},
+							pos: position{line: 434, col: 47, offset: 8285},
 							expr: &ruleRefExpr{
-								pos:  position{line: 406, col: 47, offset: 7562},
+								pos:  position{line: 434, col: 47, offset: 8285},
 								name: "Exponent",
 							},
 						},
@@ -1483,30 +1496,30 @@ var g = &grammar{
 		},
 		{
 			name: "Integer",
-			pos:  position{line: 418, col: 1, offset: 7777},
+			pos:  position{line: 447, col: 1, offset: 8523},
 			expr: &actionExpr{
-				pos: position{line: 418, col: 12, offset: 7788},
+				pos: position{line: 447, col: 12, offset: 8534},
 				run: (*parser).callonInteger1,
 				expr: &choiceExpr{
-					pos: position{line: 418, col: 13, offset: 7789},
-					alternatives: []interface{}{
+					pos: position{line: 447, col: 13, offset: 8535},
+					alternatives: []any{
 						&litMatcher{
-							pos:        position{line: 418, col: 13, offset: 7789},
+							pos:        position{line: 447, col: 13, offset: 8535},
 							val:        "0",
 							ignoreCase: false,
 							want:       "\"0\"",
 						},
 						&seqExpr{
-							pos: position{line: 418, col: 19, offset: 7795},
-							exprs: []interface{}{
+							pos: position{line: 447, col: 19, offset: 8541},
+							exprs: []any{
 								&ruleRefExpr{
-									pos:  position{line: 418, col: 19, offset: 7795},
+									pos:  position{line: 447, col: 19, offset: 8541},
 									name: "NonZeroDecimalDigit",
 								},
 								&zeroOrMoreExpr{
-									pos: position{line: 418, col: 39, offset: 7815},
+									pos: position{line: 447, col: 39, offset: 8561},
 									expr: &ruleRefExpr{
-										pos:  position{line: 418, col: 39, offset: 7815},
+										pos:  position{line: 447, col: 39, offset: 8561},
 										name: "DecimalDigit",
 									},
 								},
@@ -1518,20 +1531,20 @@ var g = &grammar{
 		},
 		{
 			name: "Exponent",
-			pos:  position{line: 428, col: 1, offset: 7949},
+			pos:  position{line: 458, col: 1, offset: 8718},
 			expr: &seqExpr{
-				pos: position{line: 428, col: 13, offset: 7961},
-				exprs: []interface{}{
+				pos: position{line: 458, col: 13, offset: 8730},
+				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 428, col: 13, offset: 7961},
+						pos:        position{line: 458, col: 13, offset: 8730},
 						val:        "e",
 						ignoreCase: true,
 						want:       "\"e\"i",
 					},
 					&zeroOrOneExpr{
-						pos: position{line: 428, col: 18, offset: 7966},
+						pos: position{line: 458, col: 18, offset: 8735},
 						expr: &charClassMatcher{
-							pos:        position{line: 428, col: 18, offset: 7966},
+							pos:        position{line: 458, col: 18, offset: 8735},
 							val:        "[+-]",
 							chars:      []rune{'+', '-'},
 							ignoreCase: false,
@@ -1539,9 +1552,9 @@ var g = &grammar{
 						},
 					},
 					&oneOrMoreExpr{
-						pos: position{line: 428, col: 24, offset: 7972},
+						pos: position{line: 458, col: 24, offset: 8741},
 						expr: &ruleRefExpr{
-							pos:  position{line: 428, col: 24, offset: 7972},
+							pos:  position{line: 458, col: 24, offset: 8741},
 							name: "DecimalDigit",
 						},
 					},
@@ -1550,9 +1563,9 @@ var g = &grammar{
 		},
 		{
 			name: "DecimalDigit",
-			pos:  position{line: 430, col: 1, offset: 7987},
+			pos:  position{line: 460, col: 1, offset: 8756},
 			expr: &charClassMatcher{
-				pos:        position{line: 430, col: 17, offset: 8003},
+				pos:        position{line: 460, col: 17, offset: 8772},
 				val:        "[0-9]",
 				ranges:     []rune{'0', '9'},
 				ignoreCase: false,
@@ -1561,9 +1574,9 @@ var g = &grammar{
 		},
 		{
 			name: "NonZeroDecimalDigit",
-			pos:  position{line: 432, col: 1, offset: 8010},
+			pos:  position{line: 462, col: 1, offset: 8779},
 			expr: &charClassMatcher{
-				pos:        position{line: 432, col: 24, offset: 8033},
+				pos:        position{line: 462, col: 24, offset: 8802},
 				val:        "[1-9]",
 				ranges:     []rune{'1', '9'},
 				ignoreCase: false,
@@ -1572,21 +1585,21 @@ var g = &grammar{
 		},
 		{
 			name: "Bool",
-			pos:  position{line: 434, col: 1, offset: 8040},
+			pos:  position{line: 464, col: 1, offset: 8809},
 			expr: &actionExpr{
-				pos: position{line: 434, col: 9, offset: 8048},
+				pos: position{line: 464, col: 9, offset: 8817},
 				run: (*parser).callonBool1,
 				expr: &choiceExpr{
-					pos: position{line: 434, col: 10, offset: 8049},
-					alternatives: []interface{}{
+					pos: position{line: 464, col: 10, offset: 8818},
+					alternatives: []any{
 						&litMatcher{
-							pos:        position{line: 434, col: 10, offset: 8049},
+							pos:        position{line: 464, col: 10, offset: 8818},
 							val:        "true",
 							ignoreCase: true,
 							want:       "\"true\"i",
 						},
 						&litMatcher{
-							pos:        position{line: 434, col: 20, offset: 8059},
+							pos:        position{line: 464, col: 20, offset: 8828},
 							val:        "false",
 							ignoreCase: true,
 							want:       "\"false\"i",
@@ -1597,12 +1610,12 @@ var g = &grammar{
 		},
 		{
 			name: "Null",
-			pos:  position{line: 444, col: 1, offset: 8203},
+			pos:  position{line: 475, col: 1, offset: 8995},
 			expr: &actionExpr{
-				pos: position{line: 444, col: 9, offset: 8211},
+				pos: position{line: 475, col: 9, offset: 9003},
 				run: (*parser).callonNull1,
 				expr: &litMatcher{
-					pos:        position{line: 444, col: 9, offset: 8211},
+					pos:        position{line: 475, col: 9, offset: 9003},
 					val:        "null",
 					ignoreCase: true,
 					want:       "\"null\"i",
@@ -1611,14 +1624,14 @@ var g = &grammar{
 		},
 		{
 			name: "_",
-			pos:  position{line: 460, col: 1, offset: 8385},
+			pos:  position{line: 493, col: 1, offset: 9201},
 			expr: &actionExpr{
-				pos: position{line: 460, col: 6, offset: 8390},
+				pos: position{line: 493, col: 6, offset: 9206},
 				run: (*parser).callon_1,
 				expr: &zeroOrMoreExpr{
-					pos: position{line: 460, col: 6, offset: 8390},
+					pos: position{line: 493, col: 6, offset: 9206},
 					expr: &charClassMatcher{
-						pos:        position{line: 460, col: 6, offset: 8390},
+						pos:        position{line: 493, col: 6, offset: 9206},
 						val:        "[ \\n\\t\\r]",
 						chars:      []rune{' ', '\n', '\t', '\r'},
 						ignoreCase: false,
@@ -1629,14 +1642,14 @@ var g = &grammar{
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 470, col: 1, offset: 8544},
+			pos:  position{line: 504, col: 1, offset: 9383},
 			expr: &actionExpr{
-				pos: position{line: 470, col: 8, offset: 8551},
+				pos: position{line: 504, col: 8, offset: 9390},
 				run: (*parser).callonEOF1,
 				expr: &notExpr{
-					pos: position{line: 470, col: 8, offset: 8551},
+					pos: position{line: 504, col: 8, offset: 9390},
 					expr: &anyMatcher{
-						line: 470, col: 9, offset: 8552,
+						line: 504, col: 9, offset: 9391,
 					},
 				},
 			},
@@ -1644,17 +1657,17 @@ var g = &grammar{
 	},
 }
 
-func (c *current) onInput1(i interface{}) (interface{}, error) {
+func (c *current) onInput1(i any) (any, error) {
 	return i, nil
 }
 
-func (p *parser) callonInput1() (interface{}, error) {
+func (p *parser) callonInput1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onInput1(stack["i"])
 }
 
-func (c *current) onOrComparison1(first, rest interface{}) (interface{}, error) {
+func (c *current) onOrComparison1(first, rest any) (any, error) {
 	exprs := eval(first, rest)
 	if len(exprs) <= 1 {
 		return first, nil
@@ -1666,13 +1679,13 @@ func (c *current) onOrComparison1(first, rest interface{}) (interface{}, error)
 	return n, nil
 }
 
-func (p *parser) callonOrComparison1() (interface{}, error) {
+func (p *parser) callonOrComparison1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onOrComparison1(stack["first"], stack["rest"])
 }
 
-func (c *current) onAndComparison1(first, rest interface{}) (interface{}, error) {
+func (c *current) onAndComparison1(first, rest any) (any, error) {
 	exprs := eval(first, rest)
 	if len(exprs) <= 1 {
 		return first, nil
@@ -1684,23 +1697,23 @@ func (c *current) onAndComparison1(first, rest interface{}) (interface{}, error)
 	return n, nil
 }
 
-func (p *parser) callonAndComparison1() (interface{}, error) {
+func (p *parser) callonAndComparison1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onAndComparison1(stack["first"], stack["rest"])
 }
 
-func (c *current) onMultiComparison1(expr interface{}) (interface{}, error) {
+func (c *current) onMultiComparison1(expr any) (any, error) {
 	return expr, nil
 }
 
-func (p *parser) callonMultiComparison1() (interface{}, error) {
+func (p *parser) callonMultiComparison1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onMultiComparison1(stack["expr"])
 }
 
-func (c *current) onNotComparison1(op, right interface{}) (interface{}, error) {
+func (c *current) onNotComparison1(op, right any) (any, error) {
 	n := ComparisonNode{
 		Type:     "not",
 		Operator: op.(CodeNode),
@@ -1709,13 +1722,13 @@ func (c *current) onNotComparison1(op, right interface{}) (interface{}, error) {
 	return n, nil
 }
 
-func (p *parser) callonNotComparison1() (interface{}, error) {
+func (p *parser) callonNotComparison1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onNotComparison1(stack["op"], stack["right"])
 }
 
-func (c *current) onLeftRightComparison1(left, op, right interface{}) (interface{}, error) {
+func (c *current) onLeftRightComparison1(left, op, right any) (any, error) {
 	n := ComparisonNode{
 		Type:     "compare",
 		Operator: op.(CodeNode),
@@ -1724,13 +1737,13 @@ func (c *current) onLeftRightComparison1(left, op, right interface{}) (interface
 	return n, nil
 }
 
-func (p *parser) callonLeftRightComparison1() (interface{}, error) {
+func (p *parser) callonLeftRightComparison1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onLeftRightComparison1(stack["left"], stack["op"], stack["right"])
 }
 
-func (c *current) onLikeComparison1(left, op, right interface{}) (interface{}, error) {
+func (c *current) onLikeComparison1(left, op, right any) (any, error) {
 	n := ComparisonNode{
 		Type:     "like",
 		Operator: op.(CodeNode),
@@ -1739,13 +1752,13 @@ func (c *current) onLikeComparison1(left, op, right interface{}) (interface{}, e
 	return n, nil
 }
 
-func (p *parser) callonLikeComparison1() (interface{}, error) {
+func (p *parser) callonLikeComparison1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onLikeComparison1(stack["left"], stack["op"], stack["right"])
 }
 
-func (c *current) onIsComparison1(left, op, right interface{}) (interface{}, error) {
+func (c *current) onIsComparison1(left, op, right any) (any, error) {
 	n := ComparisonNode{
 		Type:     "is",
 		Operator: op.(CodeNode),
@@ -1754,13 +1767,13 @@ func (c *current) onIsComparison1(left, op, right interface{}) (interface{}, err
 	return n, nil
 }
 
-func (p *parser) callonIsComparison1() (interface{}, error) {
+func (p *parser) callonIsComparison1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onIsComparison1(stack["left"], stack["op"], stack["right"])
 }
 
-func (c *current) onInComparison1(first, op, rest interface{}) (interface{}, error) {
+func (c *current) onInComparison1(first, op, rest any) (any, error) {
 	exprs := []CodeNode{first.(CodeNode)}
 	resti := toIfaceSlice(rest)
 	for _, v := range resti {
@@ -1774,13 +1787,13 @@ func (c *current) onInComparison1(first, op, rest interface{}) (interface{}, err
 	return n, nil
 }
 
-func (p *parser) callonInComparison1() (interface{}, error) {
+func (p *parser) callonInComparison1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onInComparison1(stack["first"], stack["op"], stack["rest"])
 }
 
-func (c *current) onIdentifierComparison1(i interface{}) (interface{}, error) {
+func (c *current) onIdentifierComparison1(i any) (any, error) {
 	n := ComparisonNode{
 		Type:   "identifier",
 		Values: []CodeNode{i.(CodeNode)},
@@ -1788,77 +1801,79 @@ func (c *current) onIdentifierComparison1(i interface{}) (interface{}, error) {
 	return n, nil
 }
 
-func (p *parser) callonIdentifierComparison1() (interface{}, error) {
+func (p *parser) callonIdentifierComparison1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onIdentifierComparison1(stack["i"])
 }
 
-func (c *current) onEmptyInList1() (interface{}, error) {
+func (c *current) onEmptyInList1() (any, error) {
 	return []interface{}{}, nil
 }
 
-func (p *parser) callonEmptyInList1() (interface{}, error) {
+func (p *parser) callonEmptyInList1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onEmptyInList1()
 }
 
-func (c *current) onNonEmptyInList1(first, rest interface{}) (interface{}, error) {
+func (c *current) onNonEmptyInList1(first, rest any) (any, error) {
 	exprs := eval(first, rest)
 	return exprs, nil
 }
 
-func (p *parser) callonNonEmptyInList1() (interface{}, error) {
+func (p *parser) callonNonEmptyInList1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onNonEmptyInList1(stack["first"], stack["rest"])
 }
 
-func (c *current) onBracketedValue1(i interface{}) (interface{}, error) {
+func (c *current) onBracketedValue1(i any) (any, error) {
 	return i, nil
 }
 
-func (p *parser) callonBracketedValue1() (interface{}, error) {
+func (p *parser) callonBracketedValue1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onBracketedValue1(stack["i"])
 }
 
-func (c *current) onQuotedIdentifier1() (interface{}, error) {
+func (c *current) onQuotedIdentifier1() (any, error) {
 	src := string(c.text)
 	value := strings.ReplaceAll(src[1:len(src)-1], `""`, `"`)
 	n := CodeNode{
 		Type:   "quoted_identifier",
 		Source: src,
+		Pos:    c.position(),
 		Value:  value,
 	}
 	return n, nil
 }
 
-func (p *parser) callonQuotedIdentifier1() (interface{}, error) {
+func (p *parser) callonQuotedIdentifier1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onQuotedIdentifier1()
 }
 
-func (c *current) onUnquotedIdentifier1() (interface{}, error) {
+func (c *current) onUnquotedIdentifier1() (any, error) {
 	src := string(c.text)
 	n := CodeNode{
 		Type:   "unquoted_identifier",
 		Source: src,
+		Pos:    c.position(),
 		Value:  strings.ToLower(src),
 	}
 	return n, nil
 }
 
-func (p *parser) callonUnquotedIdentifier1() (interface{}, error) {
+func (p *parser) callonUnquotedIdentifier1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onUnquotedIdentifier1()
 }
 
-func (c *current) onJsonb1(i, op, first, rest interface{}) (interface{}, error) {
+func (c *current) onJsonb1(i, op, first, rest any) (any, error) {
 	n := i.(CodeNode)
 	n.JsonbSelector = []CodeNode{op.(CodeNode), first.(CodeNode)}
 	resti := toIfaceSlice(rest)
@@ -1869,97 +1884,103 @@ func (c *current) onJsonb1(i, op, first, rest interface{}) (interface{}, error)
 	return n, nil
 }
 
-func (p *parser) callonJsonb1() (interface{}, error) {
+func (p *parser) callonJsonb1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onJsonb1(stack["i"], stack["op"], stack["first"], stack["rest"])
 }
 
-func (c *current) onJsonbOperator1() (interface{}, error) {
+func (c *current) onJsonbOperator1() (any, error) {
 	s := string(c.text)
 	n := CodeNode{
 		Type:   "operator",
 		Source: s,
+		Pos:    c.position(),
 		Value:  s,
 	}
 	return n, nil
 }
 
-func (p *parser) callonJsonbOperator1() (interface{}, error) {
+func (p *parser) callonJsonbOperator1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onJsonbOperator1()
 }
 
-func (c *current) onCompareOperator1() (interface{}, error) {
+func (c *current) onCompareOperator1() (any, error) {
 	s := string(c.text)
 	n := CodeNode{
 		Type:   "operator",
 		Source: s,
+		Pos:    c.position(),
 		Value:  s,
 	}
 	return n, nil
 }
 
-func (p *parser) callonCompareOperator1() (interface{}, error) {
+func (p *parser) callonCompareOperator1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onCompareOperator1()
 }
 
-func (c *current) onAnd1() (interface{}, error) {
+func (c *current) onAnd1() (any, error) {
 	src := string(c.text)
 	n := CodeNode{
 		Type:   "operator",
 		Source: src,
+		Pos:    c.position(),
 		Value:  "and",
 	}
 	return n, nil
 }
 
-func (p *parser) callonAnd1() (interface{}, error) {
+func (p *parser) callonAnd1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onAnd1()
 }
 
-func (c *current) onOr1() (interface{}, error) {
+func (c *current) onOr1() (any, error) {
 	src := string(c.text)
 	n := CodeNode{
 		Type:   "operator",
 		Source: src,
+		Pos:    c.position(),
 		Value:  "or",
 	}
 	return n, nil
 }
 
-func (p *parser) callonOr1() (interface{}, error) {
+func (p *parser) callonOr1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onOr1()
 }
 
-func (c *current) onNot1() (interface{}, error) {
+func (c *current) onNot1() (any, error) {
 	src := string(c.text)
 	n := CodeNode{
 		Type:   "operator",
 		Source: src,
+		Pos:    c.position(),
 		Value:  "not",
 	}
 	return n, nil
 }
 
-func (p *parser) callonNot1() (interface{}, error) {
+func (p *parser) callonNot1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onNot1()
 }
 
-func (c *current) onIn1(not interface{}) (interface{}, error) {
+func (c *current) onIn1(not any) (any, error) {
 	src := string(c.text)
 	n := CodeNode{
 		Type:   "operator",
 		Source: src,
+		Pos:    c.position(),
 		Value:  "in",
 	}
 	if not != nil {
@@ -1968,18 +1989,19 @@ func (c *current) onIn1(not interface{}) (interface{}, error) {
 	return n, nil
 }
 
-func (p *parser) callonIn1() (interface{}, error) {
+func (p *parser) callonIn1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onIn1(stack["not"])
 }
 
-func (c *current) onLike1(not, like interface{}) (interface{}, error) {
+func (c *current) onLike1(not, like any) (any, error) {
 	src := string(c.text)
 	likeStr := strings.ToLower(like.(string))
 	n := CodeNode{
 		Type:   "operator",
 		Source: src,
+		Pos:    c.position(),
 		Value:  likeStr,
 	}
 	if not != nil {
@@ -1988,28 +2010,29 @@ func (c *current) onLike1(not, like interface{}) (interface{}, error) {
 	return n, nil
 }
 
-func (p *parser) callonLike1() (interface{}, error) {
+func (p *parser) callonLike1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onLike1(stack["not"], stack["like"])
 }
 
-func (c *current) onLikeOrIlike1() (interface{}, error) {
+func (c *current) onLikeOrIlike1() (any, error) {
 	src := string(c.text)
 	return src, nil
 }
 
-func (p *parser) callonLikeOrIlike1() (interface{}, error) {
+func (p *parser) callonLikeOrIlike1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onLikeOrIlike1()
 }
 
-func (c *current) onIs1(not interface{}) (interface{}, error) {
+func (c *current) onIs1(not any) (any, error) {
 	src := string(c.text)
 	n := CodeNode{
 		Type:   "operator",
 		Source: src,
+		Pos:    c.position(),
 		Value:  "is",
 	}
 	if not != nil {
@@ -2018,16 +2041,17 @@ func (c *current) onIs1(not interface{}) (interface{}, error) {
 	return n, nil
 }
 
-func (p *parser) callonIs1() (interface{}, error) {
+func (p *parser) callonIs1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onIs1(stack["not"])
 }
 
-func (c *current) onTimeCalculation1(now, interval interface{}) (interface{}, error) {
+func (c *current) onTimeCalculation1(now, interval any) (any, error) {
 	n := CodeNode{
 		Type:   "time_calculation",
 		Source: string(c.text),
+		Pos:    c.position(),
 		Value:  "now()",
 	}
 	if interval != nil {
@@ -2039,29 +2063,30 @@ func (c *current) onTimeCalculation1(now, interval interface{}) (interface{}, er
 	return n, nil
 }
 
-func (p *parser) callonTimeCalculation1() (interface{}, error) {
+func (p *parser) callonTimeCalculation1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onTimeCalculation1(stack["now"], stack["interval"])
 }
 
-func (c *current) onAdd1() (interface{}, error) {
+func (c *current) onAdd1() (any, error) {
 	s := string(c.text)
 	n := CodeNode{
 		Type:   "operator",
 		Source: s,
+		Pos:    c.position(),
 		Value:  s,
 	}
 	return n, nil
 }
 
-func (p *parser) callonAdd1() (interface{}, error) {
+func (p *parser) callonAdd1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onAdd1()
 }
 
-func (c *current) onNoArgsFunction1() (interface{}, error) {
+func (c *current) onNoArgsFunction1() (any, error) {
 	src := string(c.text)
 	fnName := src[:len(src)-2]
 	n := FunctionNode{
@@ -2069,6 +2094,7 @@ func (c *current) onNoArgsFunction1() (interface{}, error) {
 		Function: CodeNode{
 			Type:   "function_name",
 			Source: fnName,
+			Pos:    c.position(),
 			Value:  strings.ToLower(fnName),
 		},
 		Args: []CodeNode{},
@@ -2076,13 +2102,13 @@ func (c *current) onNoArgsFunction1() (interface{}, error) {
 	return n, nil
 }
 
-func (p *parser) callonNoArgsFunction1() (interface{}, error) {
+func (p *parser) callonNoArgsFunction1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onNoArgsFunction1()
 }
 
-func (c *current) onStringOperatorFunction1(fn, s interface{}) (interface{}, error) {
+func (c *current) onStringOperatorFunction1(fn, s any) (any, error) {
 	src := string(c.text)
 	fnName := src[:len(src)-2]
 	n := FunctionNode{
@@ -2090,6 +2116,7 @@ func (c *current) onStringOperatorFunction1(fn, s interface{}) (interface{}, err
 		Function: CodeNode{
 			Type:   "function_name",
 			Source: fnName,
+			Pos:    c.position(),
 			Value:  strings.ToLower(fnName),
 		},
 		Args: []CodeNode{s.(CodeNode)},
@@ -2097,100 +2124,106 @@ func (c *current) onStringOperatorFunction1(fn, s interface{}) (interface{}, err
 	return n, nil
 }
 
-func (p *parser) callonStringOperatorFunction1() (interface{}, error) {
+func (p *parser) callonStringOperatorFunction1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onStringOperatorFunction1(stack["fn"], stack["s"])
 }
 
-func (c *current) onString1() (interface{}, error) {
+func (c *current) onString1() (any, error) {
 	src := string(c.text)
 	value := strings.ReplaceAll(src[1:len(src)-1], "''", "'")
 	n := CodeNode{
 		Type:   "string",
 		Source: src,
+		Pos:    c.position(),
 		Value:  value,
 	}
 	return n, nil
 }
 
-func (p *parser) callonString1() (interface{}, error) {
+func (p *parser) callonString1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onString1()
 }
 
-func (c *current) onNumber1() (interface{}, error) {
+func (c *current) onNumber1() (any, error) {
 	// JSON numbers have the same syntax as Go's, and are parseable using
 	// strconv.
 	src := string(c.text)
 	n := CodeNode{
 		Type:   "number",
 		Source: src,
+		Pos:    c.position(),
 		Value:  src,
 	}
 	return n, nil
 }
 
-func (p *parser) callonNumber1() (interface{}, error) {
+func (p *parser) callonNumber1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onNumber1()
 }
 
-func (c *current) onInteger1() (interface{}, error) {
+func (c *current) onInteger1() (any, error) {
 	src := string(c.text)
 	n := CodeNode{
 		Type:   "number",
 		Source: src,
+		Pos:    c.position(),
 		Value:  src,
 	}
 	return n, nil
 }
 
-func (p *parser) callonInteger1() (interface{}, error) {
+func (p *parser) callonInteger1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onInteger1()
 }
 
-func (c *current) onBool1() (interface{}, error) {
+func (c *current) onBool1() (any, error) {
 	src := string(c.text)
 	n := CodeNode{
 		Type:   "bool",
 		Source: src,
+		Pos:    c.position(),
 		Value:  strings.ToLower(src),
 	}
 	return n, nil
 }
 
-func (p *parser) callonBool1() (interface{}, error) {
+func (p *parser) callonBool1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onBool1()
 }
 
-func (c *current) onNull1() (interface{}, error) {
+func (c *current) onNull1() (any, error) {
 	src := string(c.text)
 	n := CodeNode{
 		Type:   "null",
 		Source: src,
+		Pos:    c.position(),
 		Value:  strings.ToLower(src),
 	}
 	return n, nil
 }
 
-func (p *parser) callonNull1() (interface{}, error) {
+func (p *parser) callonNull1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onNull1()
 }
 
-func (c *current) on_1() (interface{}, error) {
+func (c *current) on_1() (any, error) {
 	src := string(c.text)
 	n := CodeNode{
 		Type:   "whitespace",
 		Source: src,
+		Pos:    c.position(),
 	}
 	if len(src) > 0 {
 		n.Value = " "
@@ -2198,22 +2231,23 @@ func (c *current) on_1() (interface{}, error) {
 	return n, nil
 }
 
-func (p *parser) callon_1() (interface{}, error) {
+func (p *parser) callon_1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.on_1()
 }
 
-func (c *current) onEOF1() (interface{}, error) {
+func (c *current) onEOF1() (any, error) {
 	n := CodeNode{
 		Type:   "eof",
 		Source: "",
+		Pos:    c.position(),
 		Value:  "",
 	}
 	return n, nil
 }
 
-func (p *parser) callonEOF1() (interface{}, error) {
+func (p *parser) callonEOF1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
 	return p.cur.onEOF1()
@@ -2287,6 +2321,7 @@ func Entrypoint(ruleName string) Option {
 //	if err != nil {
 //	    log.Panicln(err)
 //	}
+//	fmt.Println(string(b))
 func Statistics(stats *Stats, choiceNoMatch string) Option {
 	return func(p *parser) Option {
 		oldStats := p.Stats
@@ -2356,7 +2391,7 @@ func Recover(b bool) Option {
 
 // GlobalStore creates an Option to set a key to a certain value in
 // the globalStore.
-func GlobalStore(key string, value interface{}) Option {
+func GlobalStore(key string, value any) Option {
 	return func(p *parser) Option {
 		old := p.cur.globalStore[key]
 		p.cur.globalStore[key] = value
@@ -2366,7 +2401,7 @@ func GlobalStore(key string, value interface{}) Option {
 
 // InitState creates an Option to set a key to a certain value in
 // the global "state" store.
-func InitState(key string, value interface{}) Option {
+func InitState(key string, value any) Option {
 	return func(p *parser) Option {
 		old := p.cur.state[key]
 		p.cur.state[key] = value
@@ -2375,7 +2410,7 @@ func InitState(key string, value interface{}) Option {
 }
 
 // ParseFile parses the file identified by filename.
-func ParseFile(filename string, opts ...Option) (i interface{}, err error) {
+func ParseFile(filename string, opts ...Option) (i any, err error) {
 	f, err := os.Open(filename)
 	if err != nil {
 		return nil, err
@@ -2390,7 +2425,7 @@ func ParseFile(filename string, opts ...Option) (i interface{}, err error) {
 
 // ParseReader parses the data from r using filename as information in the
 // error messages.
-func ParseReader(filename string, r io.Reader, opts ...Option) (interface{}, error) {
+func ParseReader(filename string, r io.Reader, opts ...Option) (any, error) {
 	b, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
@@ -2401,7 +2436,7 @@ func ParseReader(filename string, r io.Reader, opts ...Option) (interface{}, err
 
 // Parse parses the data from b using filename as information in the
 // error messages.
-func Parse(filename string, b []byte, opts ...Option) (interface{}, error) {
+func Parse(filename string, b []byte, opts ...Option) (any, error) {
 	return newParser(filename, b, opts...).parse(g)
 }
 
@@ -2439,7 +2474,7 @@ type current struct {
 	globalStore storeDict
 }
 
-type storeDict map[string]interface{}
+type storeDict map[string]any
 
 // the AST types...
 
@@ -2452,30 +2487,30 @@ type rule struct {
 	pos         position
 	name        string
 	displayName string
-	expr        interface{}
+	expr        any
 }
 
 type choiceExpr struct {
 	pos          position
-	alternatives []interface{}
+	alternatives []any
 }
 
 type actionExpr struct {
 	pos  position
-	expr interface{}
-	run  func(*parser) (interface{}, error)
+	expr any
+	run  func(*parser) (any, error)
 }
 
 type recoveryExpr struct {
 	pos          position
-	expr         interface{}
-	recoverExpr  interface{}
+	expr         any
+	recoverExpr  any
 	failureLabel []string
 }
 
 type seqExpr struct {
 	pos   position
-	exprs []interface{}
+	exprs []any
 }
 
 type throwExpr struct {
@@ -2486,19 +2521,21 @@ type throwExpr struct {
 type labeledExpr struct {
 	pos   position
 	label string
-	expr  interface{}
+	expr  any
 }
 
 type expr struct {
 	pos  position
-	expr interface{}
+	expr any
 }
 
-type andExpr expr
-type notExpr expr
-type zeroOrOneExpr expr
-type zeroOrMoreExpr expr
-type oneOrMoreExpr expr
+type (
+	andExpr        expr
+	notExpr        expr
+	zeroOrOneExpr  expr
+	zeroOrMoreExpr expr
+	oneOrMoreExpr  expr
+)
 
 type ruleRefExpr struct {
 	pos  position
@@ -2639,7 +2676,7 @@ func (p *parser) setOptions(opts []Option) {
 }
 
 type resultTuple struct {
-	v   interface{}
+	v   any
 	b   bool
 	end savepoint
 }
@@ -2683,12 +2720,12 @@ type parser struct {
 	memoize bool
 	// memoization table for the packrat algorithm:
 	// map[offset in source] map[expression or rule] {value, match}
-	memo map[int]map[interface{}]resultTuple
+	memo map[int]map[any]resultTuple
 
 	// rules table, maps the rule identifier to the rule node
 	rules map[string]*rule
 	// variables stack, map of label to value
-	vstack []map[string]interface{}
+	vstack []map[string]any
 	// rule stack, allows identification of the current rule in errors
 	rstack []*rule
 
@@ -2708,7 +2745,7 @@ type parser struct {
 
 	choiceNoMatch string
 	// recovery expression stack, keeps track of the currently available recovery expression, these are traversed in reverse
-	recoveryStack []map[string]interface{}
+	recoveryStack []map[string]any
 }
 
 // push a variable set on the vstack.
@@ -2728,7 +2765,7 @@ func (p *parser) pushV() {
 		return
 	}
 
-	m = make(map[string]interface{})
+	m = make(map[string]any)
 	p.vstack[len(p.vstack)-1] = m
 }
 
@@ -2744,7 +2781,7 @@ func (p *parser) popV() {
 }
 
 // push a recovery expression with its labels to the recoveryStack
-func (p *parser) pushRecovery(labels []string, expr interface{}) {
+func (p *parser) pushRecovery(labels []string, expr any) {
 	if cap(p.recoveryStack) == len(p.recoveryStack) {
 		// create new empty slot in the stack
 		p.recoveryStack = append(p.recoveryStack, nil)
@@ -2753,7 +2790,7 @@ func (p *parser) pushRecovery(labels []string, expr interface{}) {
 		p.recoveryStack = p.recoveryStack[:len(p.recoveryStack)+1]
 	}
 
-	m := make(map[string]interface{}, len(labels))
+	m := make(map[string]any, len(labels))
 	for _, fl := range labels {
 		m[fl] = expr
 	}
@@ -2778,14 +2815,19 @@ func (p *parser) print(prefix, s string) string {
 	return s
 }
 
+func (p *parser) printIndent(mark string, s string) string {
+	return p.print(strings.Repeat(" ", p.depth)+mark, s)
+}
+
 func (p *parser) in(s string) string {
+	res := p.printIndent(">", s)
 	p.depth++
-	return p.print(strings.Repeat(" ", p.depth)+">", s)
+	return res
 }
 
 func (p *parser) out(s string) string {
 	p.depth--
-	return p.print(strings.Repeat(" ", p.depth)+"<", s)
+	return p.printIndent("<", s)
 }
 
 func (p *parser) addErr(err error) {
@@ -2873,11 +2915,11 @@ func (p *parser) restore(pt savepoint) {
 // copies of the state to allow the parser to properly restore the state in
 // the case of backtracking.
 type Cloner interface {
-	Clone() interface{}
+	Clone() any
 }
 
 var statePool = &sync.Pool{
-	New: func() interface{} { return make(storeDict) },
+	New: func() any { return make(storeDict) },
 }
 
 func (sd storeDict) Discard() {
@@ -2919,7 +2961,7 @@ func (p *parser) sliceFrom(start savepoint) []byte {
 	return p.data[start.position.offset:p.pt.position.offset]
 }
 
-func (p *parser) getMemoized(node interface{}) (resultTuple, bool) {
+func (p *parser) getMemoized(node any) (resultTuple, bool) {
 	if len(p.memo) == 0 {
 		return resultTuple{}, false
 	}
@@ -2931,13 +2973,13 @@ func (p *parser) getMemoized(node interface{}) (resultTuple, bool) {
 	return res, ok
 }
 
-func (p *parser) setMemoized(pt savepoint, node interface{}, tuple resultTuple) {
+func (p *parser) setMemoized(pt savepoint, node any, tuple resultTuple) {
 	if p.memo == nil {
-		p.memo = make(map[int]map[interface{}]resultTuple)
+		p.memo = make(map[int]map[any]resultTuple)
 	}
 	m := p.memo[pt.offset]
 	if m == nil {
-		m = make(map[interface{}]resultTuple)
+		m = make(map[any]resultTuple)
 		p.memo[pt.offset] = m
 	}
 	m[node] = tuple
@@ -2950,7 +2992,7 @@ func (p *parser) buildRulesTable(g *grammar) {
 	}
 }
 
-func (p *parser) parse(g *grammar) (val interface{}, err error) {
+func (p *parser) parse(g *grammar) (val any, err error) {
 	if len(g.rules) == 0 {
 		p.addErr(errNoRule)
 		return nil, p.errs.err()
@@ -2986,7 +3028,7 @@ func (p *parser) parse(g *grammar) (val interface{}, err error) {
 	}
 
 	p.read() // advance to first rune
-	val, ok = p.parseRule(startRule)
+	val, ok = p.parseRuleWrap(startRule)
 	if !ok {
 		if len(*p.errs) == 0 {
 			// If parsing fails, but no errors have been recorded, the expected values
@@ -3027,36 +3069,52 @@ func listJoin(list []string, sep string, lastSep string) string {
 	}
 }
 
-func (p *parser) parseRule(rule *rule) (interface{}, bool) {
+func (p *parser) parseRuleMemoize(rule *rule) (any, bool) {
+	res, ok := p.getMemoized(rule)
+	if ok {
+		p.restore(res.end)
+		return res.v, res.b
+	}
+
+	startMark := p.pt
+	val, ok := p.parseRule(rule)
+	p.setMemoized(startMark, rule, resultTuple{val, ok, p.pt})
+
+	return val, ok
+}
+
+func (p *parser) parseRuleWrap(rule *rule) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseRule " + rule.name))
 	}
+	var (
+		val       any
+		ok        bool
+		startMark = p.pt
+	)
 
 	if p.memoize {
-		res, ok := p.getMemoized(rule)
-		if ok {
-			p.restore(res.end)
-			return res.v, res.b
-		}
+		val, ok = p.parseRuleMemoize(rule)
+	} else {
+		val, ok = p.parseRule(rule)
 	}
 
-	start := p.pt
+	if ok && p.debug {
+		p.printIndent("MATCH", string(p.sliceFrom(startMark)))
+	}
+	return val, ok
+}
+
+func (p *parser) parseRule(rule *rule) (any, bool) {
 	p.rstack = append(p.rstack, rule)
 	p.pushV()
-	val, ok := p.parseExpr(rule.expr)
+	val, ok := p.parseExprWrap(rule.expr)
 	p.popV()
 	p.rstack = p.rstack[:len(p.rstack)-1]
-	if ok && p.debug {
-		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(start)))
-	}
-
-	if p.memoize {
-		p.setMemoized(start, rule, resultTuple{val, ok, p.pt})
-	}
 	return val, ok
 }
 
-func (p *parser) parseExpr(expr interface{}) (interface{}, bool) {
+func (p *parser) parseExprWrap(expr any) (any, bool) {
 	var pt savepoint
 
 	if p.memoize {
@@ -3068,12 +3126,21 @@ func (p *parser) parseExpr(expr interface{}) (interface{}, bool) {
 		pt = p.pt
 	}
 
+	val, ok := p.parseExpr(expr)
+
+	if p.memoize {
+		p.setMemoized(pt, expr, resultTuple{val, ok, p.pt})
+	}
+	return val, ok
+}
+
+func (p *parser) parseExpr(expr any) (any, bool) {
 	p.ExprCnt++
 	if p.ExprCnt > p.maxExprCnt {
 		panic(errMaxExprCnt)
 	}
 
-	var val interface{}
+	var val any
 	var ok bool
 	switch expr := expr.(type) {
 	case *actionExpr:
@@ -3115,19 +3182,16 @@ func (p *parser) parseExpr(expr interface{}) (interface{}, bool) {
 	default:
 		panic(fmt.Sprintf("unknown expression type %T", expr))
 	}
-	if p.memoize {
-		p.setMemoized(pt, expr, resultTuple{val, ok, p.pt})
-	}
 	return val, ok
 }
 
-func (p *parser) parseActionExpr(act *actionExpr) (interface{}, bool) {
+func (p *parser) parseActionExpr(act *actionExpr) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseActionExpr"))
 	}
 
 	start := p.pt
-	val, ok := p.parseExpr(act.expr)
+	val, ok := p.parseExprWrap(act.expr)
 	if ok {
 		p.cur.pos = start.position
 		p.cur.text = p.sliceFrom(start)
@@ -3141,12 +3205,12 @@ func (p *parser) parseActionExpr(act *actionExpr) (interface{}, bool) {
 		val = actVal
 	}
 	if ok && p.debug {
-		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(start)))
+		p.printIndent("MATCH", string(p.sliceFrom(start)))
 	}
 	return val, ok
 }
 
-func (p *parser) parseAndCodeExpr(and *andCodeExpr) (interface{}, bool) {
+func (p *parser) parseAndCodeExpr(and *andCodeExpr) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseAndCodeExpr"))
 	}
@@ -3162,7 +3226,7 @@ func (p *parser) parseAndCodeExpr(and *andCodeExpr) (interface{}, bool) {
 	return nil, ok
 }
 
-func (p *parser) parseAndExpr(and *andExpr) (interface{}, bool) {
+func (p *parser) parseAndExpr(and *andExpr) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseAndExpr"))
 	}
@@ -3170,7 +3234,7 @@ func (p *parser) parseAndExpr(and *andExpr) (interface{}, bool) {
 	pt := p.pt
 	state := p.cloneState()
 	p.pushV()
-	_, ok := p.parseExpr(and.expr)
+	_, ok := p.parseExprWrap(and.expr)
 	p.popV()
 	p.restoreState(state)
 	p.restore(pt)
@@ -3178,7 +3242,7 @@ func (p *parser) parseAndExpr(and *andExpr) (interface{}, bool) {
 	return nil, ok
 }
 
-func (p *parser) parseAnyMatcher(any *anyMatcher) (interface{}, bool) {
+func (p *parser) parseAnyMatcher(any *anyMatcher) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseAnyMatcher"))
 	}
@@ -3194,7 +3258,7 @@ func (p *parser) parseAnyMatcher(any *anyMatcher) (interface{}, bool) {
 	return p.sliceFrom(start), true
 }
 
-func (p *parser) parseCharClassMatcher(chr *charClassMatcher) (interface{}, bool) {
+func (p *parser) parseCharClassMatcher(chr *charClassMatcher) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseCharClassMatcher"))
 	}
@@ -3275,7 +3339,7 @@ func (p *parser) incChoiceAltCnt(ch *choiceExpr, altI int) {
 	m[alt]++
 }
 
-func (p *parser) parseChoiceExpr(ch *choiceExpr) (interface{}, bool) {
+func (p *parser) parseChoiceExpr(ch *choiceExpr) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseChoiceExpr"))
 	}
@@ -3287,7 +3351,7 @@ func (p *parser) parseChoiceExpr(ch *choiceExpr) (interface{}, bool) {
 		state := p.cloneState()
 
 		p.pushV()
-		val, ok := p.parseExpr(alt)
+		val, ok := p.parseExprWrap(alt)
 		p.popV()
 		if ok {
 			p.incChoiceAltCnt(ch, altI)
@@ -3299,13 +3363,13 @@ func (p *parser) parseChoiceExpr(ch *choiceExpr) (interface{}, bool) {
 	return nil, false
 }
 
-func (p *parser) parseLabeledExpr(lab *labeledExpr) (interface{}, bool) {
+func (p *parser) parseLabeledExpr(lab *labeledExpr) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseLabeledExpr"))
 	}
 
 	p.pushV()
-	val, ok := p.parseExpr(lab.expr)
+	val, ok := p.parseExprWrap(lab.expr)
 	p.popV()
 	if ok && lab.label != "" {
 		m := p.vstack[len(p.vstack)-1]
@@ -3314,7 +3378,7 @@ func (p *parser) parseLabeledExpr(lab *labeledExpr) (interface{}, bool) {
 	return val, ok
 }
 
-func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
+func (p *parser) parseLitMatcher(lit *litMatcher) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseLitMatcher"))
 	}
@@ -3336,7 +3400,7 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
 	return p.sliceFrom(start), true
 }
 
-func (p *parser) parseNotCodeExpr(not *notCodeExpr) (interface{}, bool) {
+func (p *parser) parseNotCodeExpr(not *notCodeExpr) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseNotCodeExpr"))
 	}
@@ -3352,7 +3416,7 @@ func (p *parser) parseNotCodeExpr(not *notCodeExpr) (interface{}, bool) {
 	return nil, !ok
 }
 
-func (p *parser) parseNotExpr(not *notExpr) (interface{}, bool) {
+func (p *parser) parseNotExpr(not *notExpr) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseNotExpr"))
 	}
@@ -3361,7 +3425,7 @@ func (p *parser) parseNotExpr(not *notExpr) (interface{}, bool) {
 	state := p.cloneState()
 	p.pushV()
 	p.maxFailInvertExpected = !p.maxFailInvertExpected
-	_, ok := p.parseExpr(not.expr)
+	_, ok := p.parseExprWrap(not.expr)
 	p.maxFailInvertExpected = !p.maxFailInvertExpected
 	p.popV()
 	p.restoreState(state)
@@ -3370,16 +3434,16 @@ func (p *parser) parseNotExpr(not *notExpr) (interface{}, bool) {
 	return nil, !ok
 }
 
-func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (interface{}, bool) {
+func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseOneOrMoreExpr"))
 	}
 
-	var vals []interface{}
+	var vals []any
 
 	for {
 		p.pushV()
-		val, ok := p.parseExpr(expr.expr)
+		val, ok := p.parseExprWrap(expr.expr)
 		p.popV()
 		if !ok {
 			if len(vals) == 0 {
@@ -3392,19 +3456,19 @@ func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (interface{}, bool) {
 	}
 }
 
-func (p *parser) parseRecoveryExpr(recover *recoveryExpr) (interface{}, bool) {
+func (p *parser) parseRecoveryExpr(recover *recoveryExpr) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseRecoveryExpr (" + strings.Join(recover.failureLabel, ",") + ")"))
 	}
 
 	p.pushRecovery(recover.failureLabel, recover.recoverExpr)
-	val, ok := p.parseExpr(recover.expr)
+	val, ok := p.parseExprWrap(recover.expr)
 	p.popRecovery()
 
 	return val, ok
 }
 
-func (p *parser) parseRuleRefExpr(ref *ruleRefExpr) (interface{}, bool) {
+func (p *parser) parseRuleRefExpr(ref *ruleRefExpr) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseRuleRefExpr " + ref.name))
 	}
@@ -3418,20 +3482,20 @@ func (p *parser) parseRuleRefExpr(ref *ruleRefExpr) (interface{}, bool) {
 		p.addErr(fmt.Errorf("undefined rule: %s", ref.name))
 		return nil, false
 	}
-	return p.parseRule(rule)
+	return p.parseRuleWrap(rule)
 }
 
-func (p *parser) parseSeqExpr(seq *seqExpr) (interface{}, bool) {
+func (p *parser) parseSeqExpr(seq *seqExpr) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseSeqExpr"))
 	}
 
-	vals := make([]interface{}, 0, len(seq.exprs))
+	vals := make([]any, 0, len(seq.exprs))
 
 	pt := p.pt
 	state := p.cloneState()
 	for _, expr := range seq.exprs {
-		val, ok := p.parseExpr(expr)
+		val, ok := p.parseExprWrap(expr)
 		if !ok {
 			p.restoreState(state)
 			p.restore(pt)
@@ -3442,7 +3506,7 @@ func (p *parser) parseSeqExpr(seq *seqExpr) (interface{}, bool) {
 	return vals, true
 }
 
-func (p *parser) parseStateCodeExpr(state *stateCodeExpr) (interface{}, bool) {
+func (p *parser) parseStateCodeExpr(state *stateCodeExpr) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseStateCodeExpr"))
 	}
@@ -3454,14 +3518,14 @@ func (p *parser) parseStateCodeExpr(state *stateCodeExpr) (interface{}, bool) {
 	return nil, true
 }
 
-func (p *parser) parseThrowExpr(expr *throwExpr) (interface{}, bool) {
+func (p *parser) parseThrowExpr(expr *throwExpr) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseThrowExpr"))
 	}
 
 	for i := len(p.recoveryStack) - 1; i >= 0; i-- {
 		if recoverExpr, ok := p.recoveryStack[i][expr.label]; ok {
-			if val, ok := p.parseExpr(recoverExpr); ok {
+			if val, ok := p.parseExprWrap(recoverExpr); ok {
 				return val, ok
 			}
 		}
@@ -3470,16 +3534,16 @@ func (p *parser) parseThrowExpr(expr *throwExpr) (interface{}, bool) {
 	return nil, false
 }
 
-func (p *parser) parseZeroOrMoreExpr(expr *zeroOrMoreExpr) (interface{}, bool) {
+func (p *parser) parseZeroOrMoreExpr(expr *zeroOrMoreExpr) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseZeroOrMoreExpr"))
 	}
 
-	var vals []interface{}
+	var vals []any
 
 	for {
 		p.pushV()
-		val, ok := p.parseExpr(expr.expr)
+		val, ok := p.parseExprWrap(expr.expr)
 		p.popV()
 		if !ok {
 			return vals, true
@@ -3488,13 +3552,13 @@ func (p *parser) parseZeroOrMoreExpr(expr *zeroOrMoreExpr) (interface{}, bool) {
 	}
 }
 
-func (p *parser) parseZeroOrOneExpr(expr *zeroOrOneExpr) (interface{}, bool) {
+func (p *parser) parseZeroOrOneExpr(expr *zeroOrOneExpr) (any, bool) {
 	if p.debug {
 		defer p.out(p.in("parseZeroOrOneExpr"))
 	}
 
 	p.pushV()
-	val, _ := p.parseExpr(expr.expr)
+	val, _ := p.parseExprWrap(expr.expr)
 	p.popV()
 	// whether it matched or not, consider it a match
 	return val, true
@@ -0,0 +1,195 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Interval is a postgres style interval, e.g. '1 day' or '2 hours 30 minutes'.
+// As in postgres, months and days are stored separately from the duration, as their length varies
+type Interval struct {
+	Months   int
+	Days     int
+	Duration time.Duration
+}
+
+// map of interval unit (as accepted by postgres) to the function which adds a quantity of the unit to an interval
+var intervalUnits = map[string]func(i *Interval, quantity float64){
+	"microsecond":  addIntervalDuration(time.Microsecond),
+	"microseconds": addIntervalDuration(time.Microsecond),
+	"us":           addIntervalDuration(time.Microsecond),
+	"millisecond":  addIntervalDuration(time.Millisecond),
+	"milliseconds": addIntervalDuration(time.Millisecond),
+	"ms":           addIntervalDuration(time.Millisecond),
+	"second":       addIntervalDuration(time.Second),
+	"seconds":      addIntervalDuration(time.Second),
+	"sec":          addIntervalDuration(time.Second),
+	"secs":         addIntervalDuration(time.Second),
+	"s":            addIntervalDuration(time.Second),
+	"minute":       addIntervalDuration(time.Minute),
+	"minutes":      addIntervalDuration(time.Minute),
+	"min":          addIntervalDuration(time.Minute),
+	"mins":         addIntervalDuration(time.Minute),
+	"m":            addIntervalDuration(time.Minute),
+	"hour":         addIntervalDuration(time.Hour),
+	"hours":        addIntervalDuration(time.Hour),
+	"hr":           addIntervalDuration(time.Hour),
+	"hrs":          addIntervalDuration(time.Hour),
+	"h":            addIntervalDuration(time.Hour),
+	"day":          addIntervalDays(1),
+	"days":         addIntervalDays(1),
+	"d":            addIntervalDays(1),
+	"week":         addIntervalDays(7),
+	"weeks":        addIntervalDays(7),
+	"w":            addIntervalDays(7),
+	"month":        addIntervalMonths(1),
+	"months":       addIntervalMonths(1),
+	"mon":          addIntervalMonths(1),
+	"mons":         addIntervalMonths(1),
+	"year":         addIntervalMonths(12),
+	"years":        addIntervalMonths(12),
+	"yr":           addIntervalMonths(12),
+	"yrs":          addIntervalMonths(12),
+	"y":            addIntervalMonths(12),
+}
+
+func addIntervalDuration(unit time.Duration) func(i *Interval, quantity float64) {
+	return func(i *Interval, quantity float64) {
+		i.Duration += time.Duration(quantity * float64(unit))
+	}
+}
+
+// addIntervalDays adds whole days to the days, and any fraction of a day to the duration
+func addIntervalDays(days int) func(i *Interval, quantity float64) {
+	return func(i *Interval, quantity float64) {
+		whole, fraction := math.Modf(quantity * float64(days))
+		i.Days += int(whole)
+		i.Duration += time.Duration(fraction * float64(24*time.Hour))
+	}
+}
+
+// addIntervalMonths adds whole months to the months, and as in postgres, any fraction of a month as 30 day months
+func addIntervalMonths(months int) func(i *Interval, quantity float64) {
+	return func(i *Interval, quantity float64) {
+		whole, fraction := math.Modf(quantity * float64(months))
+		i.Months += int(whole)
+		addIntervalDays(30)(i, fraction)
+	}
+}
+
+// ParseInterval parses a postgres style interval, i.e. a sequence of quantities and units such as '1 day',
+// '2 weeks 3 days', '-1 hour' or '1h30m'. An optional trailing 'ago' negates the interval
+func ParseInterval(s string) (Interval, error) {
+	var res Interval
+
+	fields := strings.Fields(strings.ToLower(s))
+	ago := len(fields) > 0 && fields[len(fields)-1] == "ago"
+	if ago {
+		fields = fields[:len(fields)-1]
+	}
+	// split the fields into quantities and units, e.g. '1h30m' is [1 h 30 m]
+	var tokens []string
+	for _, f := range fields {
+		tokens = append(tokens, splitIntervalField(f)...)
+	}
+	if len(tokens) == 0 || len(tokens)%2 != 0 {
+		return res, fmt.Errorf("invalid interval '%s'", s)
+	}
+
+	for i := 0; i < len(tokens); i += 2 {
+		quantity, err := strconv.ParseFloat(tokens[i], 64)
+		if err != nil {
+			return res, fmt.Errorf("invalid interval '%s': '%s' is not a number", s, tokens[i])
+		}
+		add, ok := intervalUnits[tokens[i+1]]
+		if !ok {
+			return res, fmt.Errorf("invalid interval '%s': unknown unit '%s'", s, tokens[i+1])
+		}
+		add(&res, quantity)
+	}
+
+	if ago {
+		res = res.Negate()
+	}
+	return res, nil
+}
+
+// splitIntervalField splits a field into alternating numeric and non numeric tokens
+func splitIntervalField(field string) []string {
+	var res []string
+	start := 0
+	isNumeric := func(r rune) bool {
+		return unicode.IsDigit(r) || r == '.' || r == '-' || r == '+'
+	}
+	runes := []rune(field)
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || isNumeric(runes[i]) != isNumeric(runes[i-1]) {
+			res = append(res, string(runes[start:i]))
+			start = i
+		}
+	}
+	return res
+}
+
+// Negate returns the interval with all components negated
+func (i Interval) Negate() Interval {
+	return Interval{Months: -i.Months, Days: -i.Days, Duration: -i.Duration}
+}
+
+// AddTo adds the interval to the time - months and days are added as calendar months and days
+func (i Interval) AddTo(t time.Time) time.Time {
+	return t.AddDate(0, i.Months, i.Days).Add(i.Duration)
+}
+
+// timeCalculation is a parsed time calculation, i.e. now() or now() +/- interval '<interval>'
+type timeCalculation struct {
+	subtract bool
+	// the unescaped interval string, or empty if the calculation is now()
+	intervalString string
+}
+
+// interval parses the interval string, negating it if it is subtracted
+func (c timeCalculation) interval() (Interval, error) {
+	i, err := ParseInterval(c.intervalString)
+	if err != nil {
+		return i, err
+	}
+	if c.subtract {
+		i = i.Negate()
+	}
+	return i, nil
+}
+
+// parseTimeCalculation parses the value of a time calculation code node, which is of the form now() or
+// now() +/- interval '<interval>'
+func parseTimeCalculation(node CodeNode) (timeCalculation, error) {
+	var res timeCalculation
+	invalidErr := fmt.Errorf("invalid time calculation '%s'", node.Source)
+
+	calculation, ok := strings.CutPrefix(node.Value, "now()")
+	if !ok {
+		return res, invalidErr
+	}
+	calculation = strings.TrimSpace(calculation)
+	if calculation == "" {
+		return res, nil
+	}
+
+	switch calculation[0] {
+	case '+':
+	case '-':
+		res.subtract = true
+	default:
+		return res, invalidErr
+	}
+	intervalString, ok := strings.CutPrefix(strings.TrimSpace(calculation[1:]), "interval ")
+	if !ok || len(intervalString) < 3 || !strings.HasPrefix(intervalString, "'") || !strings.HasSuffix(intervalString, "'") {
+		return res, invalidErr
+	}
+	res.intervalString = strings.ReplaceAll(intervalString[1:len(intervalString)-1], "''", "'")
+	return res, nil
+}
@@ -30,7 +30,7 @@ func ComparisonToSQL(node ComparisonNode, identifiers []string) (string, []strin
 		sql, err := IdentifierToSQL(node)
 		return sql, identifiers, err
 	}
-	return "", identifiers, nil
+	return "", identifiers, fmt.Errorf("unsupported expression type '%s'", node.Type)
 }
 
 func CodeToSQL(node CodeNode) (string, error) {
@@ -39,7 +39,10 @@ func CodeToSQL(node CodeNode) (string, error) {
 	case "quoted_identifier", "unquoted_identifier":
 		s = fmt.Sprintf(`"%s"`, strings.ReplaceAll(node.Value, `"`, `""`))
 		for _, i := range node.JsonbSelector {
-			sql, _ := CodeToSQL(i)
+			sql, err := CodeToSQL(i)
+			if err != nil {
+				return "", err
+			}
 			s += fmt.Sprintf(" %s", sql)
 		}
 	case "string":
@@ -56,7 +59,10 @@ func LogicToSQL(node ComparisonNode, identifiers []string) (string, []string, er
 	newIdentifiers := identifiers
 	parts := []string{}
 	for _, v := range toIfaceSlice(node.Values) {
-		s, i, _ := ComparisonToSQL(v.(ComparisonNode), newIdentifiers)
+		s, i, err := ComparisonToSQL(v.(ComparisonNode), newIdentifiers)
+		if err != nil {
+			return "", identifiers, err
+		}
 		newIdentifiers = i
 		parts = append(parts, s)
 	}
@@ -70,7 +76,10 @@ func IdentifierToSQL(node ComparisonNode) (string, error) {
 
 func NotToSQL(node ComparisonNode, identifiers []string) (string, []string, error) {
 	values := node.Values.([]ComparisonNode)
-	rightSQL, newIdentifiers, _ := ComparisonToSQL(values[0], identifiers)
+	rightSQL, newIdentifiers, err := ComparisonToSQL(values[0], identifiers)
+	if err != nil {
+		return "", identifiers, err
+	}
 	return fmt.Sprintf(`( not %s )`, rightSQL), newIdentifiers, nil
 }
 
@@ -79,19 +88,37 @@ func CompareToSQL(node ComparisonNode, identifiers []string) (string, []string,
 	leftCodeNode := values[0]
 	newIdentifiers := appendIdentifier(identifiers, leftCodeNode.Value)
 	rightCodeNode := values[1]
-	leftSQL, _ := CodeToSQL(leftCodeNode)
-	opSQL, _ := OperatorSQL(node.Operator)
-	rightSQL, _ := CodeToSQL(rightCodeNode)
+	leftSQL, err := CodeToSQL(leftCodeNode)
+	if err != nil {
+		return "", identifiers, err
+	}
+	opSQL, err := OperatorSQL(node.Operator)
+	if err != nil {
+		return "", identifiers, err
+	}
+	rightSQL, err := CodeToSQL(rightCodeNode)
+	if err != nil {
+		return "", identifiers, err
+	}
 	return fmt.Sprintf("( %s %s %s )", leftSQL, opSQL, rightSQL), newIdentifiers, nil
 }
 
 func InToSQL(node ComparisonNode) (string, error) {
 	values := node.Values.([]CodeNode)
-	leftSQL, _ := CodeToSQL(values[0])
-	opSQL, _ := OperatorSQL(node.Operator)
+	leftSQL, err := CodeToSQL(values[0])
+	if err != nil {
+		return "", err
+	}
+	opSQL, err := OperatorSQL(node.Operator)
+	if err != nil {
+		return "", err
+	}
 	inValues := []string{}
 	for _, v := range values[1:] {
-		s, _ := CodeToSQL(v)
+		s, err := CodeToSQL(v)
+		if err != nil {
+			return "", err
+		}
 		inValues = append(inValues, s)
 	}
 	return fmt.Sprintf("( %s %s ( %s ) )", leftSQL, opSQL, strings.Join(inValues, ", ")), nil
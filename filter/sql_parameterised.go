@@ -0,0 +1,380 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect is the SQL dialect which parameterised SQL is built for
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectSqlite   Dialect = "sqlite"
+	DialectDuckDB   Dialect = "duckdb"
+)
+
+type SQLOption func(*sqlBuilder)
+
+// WithDialect sets the dialect of the SQL - by default this is postgres
+func WithDialect(dialect Dialect) SQLOption {
+	return func(b *sqlBuilder) {
+		b.dialect = dialect
+	}
+}
+
+// WithAllowedIdentifiers restricts the identifiers (i.e. columns) which the filter may refer to - any other
+// identifier is an error
+func WithAllowedIdentifiers(identifiers []string) SQLOption {
+	return func(b *sqlBuilder) {
+		b.allowedIdentifiers = make(map[string]struct{}, len(identifiers))
+		for _, i := range identifiers {
+			b.allowedIdentifiers[i] = struct{}{}
+		}
+	}
+}
+
+type sqlBuilder struct {
+	dialect Dialect
+	// if set, the identifiers the filter may refer to
+	allowedIdentifiers map[string]struct{}
+	args               []any
+}
+
+// ComparisonToParameterisedSQL converts the filter (as returned by Parse) to SQL for the dialect, in which
+// all constant values are replaced by placeholders. It returns the SQL and the args for the placeholders.
+//
+// Unlike ComparisonToSQL, any error building the SQL is returned, and if WithAllowedIdentifiers is set,
+// an identifier which is not allowed is an error
+func ComparisonToParameterisedSQL(node ComparisonNode, opts ...SQLOption) (string, []any, error) {
+	b := &sqlBuilder{dialect: DialectPostgres}
+	for _, opt := range opts {
+		opt(b)
+	}
+	switch b.dialect {
+	case DialectPostgres, DialectSqlite, DialectDuckDB:
+	default:
+		return "", nil, fmt.Errorf("unsupported SQL dialect '%s'", b.dialect)
+	}
+
+	sql, err := b.comparison(node)
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, b.args, nil
+}
+
+// placeholder adds the arg and returns its placeholder
+func (b *sqlBuilder) placeholder(arg any) string {
+	b.args = append(b.args, arg)
+	if b.dialect == DialectPostgres {
+		return fmt.Sprintf("$%d", len(b.args))
+	}
+	return "?"
+}
+
+func (b *sqlBuilder) comparison(node ComparisonNode) (string, error) {
+	switch node.Type {
+	case "and", "or":
+		var parts []string
+		for _, v := range toIfaceSlice(node.Values) {
+			child, ok := v.(ComparisonNode)
+			if !ok {
+				return "", fmt.Errorf("invalid '%s' expression", node.Type)
+			}
+			s, err := b.comparison(child)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, s)
+		}
+		return fmt.Sprintf("( %s )", strings.Join(parts, fmt.Sprintf(" %s ", node.Type))), nil
+	case "not":
+		values, ok := node.Values.([]ComparisonNode)
+		if !ok || len(values) != 1 {
+			return "", fmt.Errorf("invalid 'not' expression")
+		}
+		s, err := b.comparison(values[0])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("( not %s )", s), nil
+	case "compare":
+		return b.compare(node)
+	case "like":
+		return b.like(node)
+	case "is":
+		return b.is(node)
+	case "in":
+		return b.in(node)
+	case "identifier":
+		values, ok := node.Values.([]CodeNode)
+		if !ok || len(values) != 1 {
+			return "", fmt.Errorf("invalid identifier expression")
+		}
+		return b.code(values[0])
+	}
+	return "", fmt.Errorf("unsupported expression type '%s'", node.Type)
+}
+
+func (b *sqlBuilder) compare(node ComparisonNode) (string, error) {
+	switch node.Operator.Value {
+	case "=", "!=", "<>", "<", "<=", ">", ">=":
+	default:
+		return "", fmt.Errorf("unsupported comparison operator '%s'", node.Operator.Value)
+	}
+	left, right, err := b.operands(node)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("( %s %s %s )", left, node.Operator.Value, right), nil
+}
+
+func (b *sqlBuilder) like(node ComparisonNode) (string, error) {
+	op := node.Operator.Value
+	switch op {
+	case "like", "ilike", "not like", "not ilike":
+	default:
+		return "", fmt.Errorf("unsupported like operator '%s'", op)
+	}
+	if b.dialect == DialectSqlite && !strings.HasSuffix(op, "ilike") {
+		return b.sqliteLike(node)
+	}
+	left, right, err := b.operands(node)
+	if err != nil {
+		return "", err
+	}
+	// neither sqlite nor duckdb use '\' as the default escape character, as postgres does
+	switch b.dialect {
+	case DialectSqlite:
+		// sqlite has no ilike (note that sqlite like is case insensitive for ASCII characters)
+		left, right = fmt.Sprintf("lower(%s)", left), fmt.Sprintf("lower(%s)", right)
+		op = strings.TrimSuffix(op, "ilike") + "like"
+		return fmt.Sprintf(`( %s %s %s escape '\' )`, left, op, right), nil
+	case DialectDuckDB:
+		return fmt.Sprintf(`( %s %s %s escape '\' )`, left, op, right), nil
+	}
+	return fmt.Sprintf("( %s %s %s )", left, op, right), nil
+}
+
+// sqliteLike converts a case sensitive like to a sqlite glob, as sqlite like is case insensitive for ASCII
+// characters. The pattern must be a string, so it can be converted to a glob pattern
+func (b *sqlBuilder) sqliteLike(node ComparisonNode) (string, error) {
+	values, ok := node.Values.([]CodeNode)
+	if !ok || len(values) != 2 {
+		return "", fmt.Errorf("invalid '%s' expression", node.Type)
+	}
+	if values[1].Type != "string" {
+		return "", fmt.Errorf("the pattern of '%s' must be a string for sqlite", node.Operator.Value)
+	}
+	left, err := b.code(values[0])
+	if err != nil {
+		return "", err
+	}
+	op := strings.TrimSuffix(node.Operator.Value, "like") + "glob"
+	return fmt.Sprintf("( %s %s %s )", left, op, b.placeholder(likeToGlob(values[1].Value))), nil
+}
+
+// likeToGlob converts a like pattern to a sqlite glob pattern - '%' and '_' are converted to '*' and '?', and
+// characters which are special in a glob are matched literally using a character class, e.g. '[*]'
+func likeToGlob(pattern string) string {
+	var sb strings.Builder
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case !escaped && r == '\\':
+			escaped = true
+			continue
+		case !escaped && r == '%':
+			sb.WriteRune('*')
+		case !escaped && r == '_':
+			sb.WriteRune('?')
+		case r == '*', r == '?', r == '[':
+			sb.WriteString("[" + string(r) + "]")
+		default:
+			sb.WriteRune(r)
+		}
+		escaped = false
+	}
+	return sb.String()
+}
+
+func (b *sqlBuilder) is(node ComparisonNode) (string, error) {
+	switch node.Operator.Value {
+	case "is", "is not":
+	default:
+		return "", fmt.Errorf("unsupported is operator '%s'", node.Operator.Value)
+	}
+	values, ok := node.Values.([]CodeNode)
+	if !ok || len(values) != 2 {
+		return "", fmt.Errorf("invalid 'is' expression")
+	}
+	// the operands of 'is' may not be placeholders, so bool and null constants are written as literals
+	var operands []string
+	for _, v := range values {
+		var s string
+		var err error
+		switch v.Type {
+		case "bool", "null":
+			s = v.Value
+		default:
+			s, err = b.code(v)
+		}
+		if err != nil {
+			return "", err
+		}
+		operands = append(operands, s)
+	}
+	return fmt.Sprintf("( %s %s %s )", operands[0], node.Operator.Value, operands[1]), nil
+}
+
+func (b *sqlBuilder) in(node ComparisonNode) (string, error) {
+	values, ok := node.Values.([]CodeNode)
+	if !ok || len(values) == 0 {
+		return "", fmt.Errorf("invalid 'in' expression")
+	}
+	var not bool
+	switch node.Operator.Value {
+	case "in":
+	case "not in":
+		not = true
+	default:
+		return "", fmt.Errorf("unsupported in operator '%s'", node.Operator.Value)
+	}
+	left, err := b.code(values[0])
+	if err != nil {
+		return "", err
+	}
+	// an empty list is not valid SQL, and never matches
+	if len(values) == 1 {
+		return fmt.Sprintf("( %t )", not), nil
+	}
+	var inValues []string
+	for _, v := range values[1:] {
+		s, err := b.code(v)
+		if err != nil {
+			return "", err
+		}
+		inValues = append(inValues, s)
+	}
+	return fmt.Sprintf("( %s %s ( %s ) )", left, node.Operator.Value, strings.Join(inValues, ", ")), nil
+}
+
+func (b *sqlBuilder) operands(node ComparisonNode) (string, string, error) {
+	values, ok := node.Values.([]CodeNode)
+	if !ok || len(values) != 2 {
+		return "", "", fmt.Errorf("invalid '%s' expression", node.Type)
+	}
+	left, err := b.code(values[0])
+	if err != nil {
+		return "", "", err
+	}
+	right, err := b.code(values[1])
+	if err != nil {
+		return "", "", err
+	}
+	return left, right, nil
+}
+
+func (b *sqlBuilder) code(node CodeNode) (string, error) {
+	switch node.Type {
+	case "quoted_identifier", "unquoted_identifier":
+		return b.identifier(node)
+	case "string":
+		return b.placeholder(node.Value), nil
+	case "number":
+		if i, err := strconv.ParseInt(node.Value, 10, 64); err == nil {
+			return b.placeholder(i), nil
+		}
+		f, err := strconv.ParseFloat(node.Value, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid number '%s'", node.Source)
+		}
+		return b.placeholder(f), nil
+	case "bool":
+		return b.placeholder(node.Value == "true"), nil
+	case "null":
+		return "null", nil
+	case "time_calculation":
+		return b.timeCalculation(node)
+	}
+	return "", fmt.Errorf("unsupported value type '%s'", node.Type)
+}
+
+func (b *sqlBuilder) identifier(node CodeNode) (string, error) {
+	if b.allowedIdentifiers != nil {
+		if _, ok := b.allowedIdentifiers[node.Value]; !ok {
+			return "", fmt.Errorf("unknown column '%s'", node.Value)
+		}
+	}
+	s := fmt.Sprintf(`"%s"`, strings.ReplaceAll(node.Value, `"`, `""`))
+
+	if len(node.JsonbSelector)%2 != 0 {
+		return "", fmt.Errorf("invalid jsonb selector")
+	}
+	for i := 0; i < len(node.JsonbSelector); i += 2 {
+		op, field := node.JsonbSelector[i], node.JsonbSelector[i+1]
+		if op.Value != "->" && op.Value != "->>" {
+			return "", fmt.Errorf("unsupported jsonb operator '%s'", op.Value)
+		}
+		fieldSQL, err := b.code(field)
+		if err != nil {
+			return "", err
+		}
+		// postgres cannot infer the type of the placeholder as the operators are overloaded for keys and indexes
+		if b.dialect == DialectPostgres {
+			if field.Type == "number" {
+				fieldSQL += "::int"
+			} else {
+				fieldSQL += "::text"
+			}
+		}
+		s += fmt.Sprintf(" %s %s", op.Value, fieldSQL)
+	}
+	return s, nil
+}
+
+func (b *sqlBuilder) timeCalculation(node CodeNode) (string, error) {
+	calculation, err := parseTimeCalculation(node)
+	if err != nil {
+		return "", err
+	}
+	// validate the interval, so an invalid interval is reported regardless of dialect
+	var interval Interval
+	if calculation.intervalString != "" {
+		if interval, err = calculation.interval(); err != nil {
+			return "", fmt.Errorf("invalid time calculation '%s': %s", node.Source, err.Error())
+		}
+	}
+
+	if b.dialect == DialectSqlite {
+		// sqlite has no interval type, so the interval is converted to datetime modifiers - note that the result
+		// is text of the form 'YYYY-MM-DD HH:MM:SS', so is only comparable to timestamps stored in this form
+		s := "datetime('now'"
+		if interval.Months != 0 {
+			s += ", " + b.placeholder(fmt.Sprintf("%+d months", interval.Months))
+		}
+		if interval.Days != 0 {
+			s += ", " + b.placeholder(fmt.Sprintf("%+d days", interval.Days))
+		}
+		if interval.Duration != 0 {
+			s += ", " + b.placeholder(fmt.Sprintf("%+f seconds", interval.Duration.Seconds()))
+		}
+		return s + ")", nil
+	}
+
+	now := "now()"
+	if b.dialect == DialectDuckDB {
+		// duckdb now() is a timestamp with time zone, which requires the icu extension for interval arithmetic
+		now = "cast(now() as timestamp)"
+	}
+	if calculation.intervalString == "" {
+		return now, nil
+	}
+	op := "+"
+	if calculation.subtract {
+		op = "-"
+	}
+	return fmt.Sprintf("%s %s cast(%s as interval)", now, op, b.placeholder(calculation.intervalString)), nil
+}
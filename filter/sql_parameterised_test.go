@@ -0,0 +1,195 @@
+package filter
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	_ "github.com/marcboeker/go-duckdb"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type parameterisedSQLCase struct {
+	sql  string
+	args []any
+}
+
+var parameterisedSQLCases = map[string]map[Dialect]parameterisedSQLCase{
+	`foo = 'foo'`: {
+		DialectPostgres: {`( "foo" = $1 )`, []any{"foo"}},
+		DialectSqlite:   {`( "foo" = ? )`, []any{"foo"}},
+	},
+	`foo = 'it''s' or bar > 1.5 and baz = true`: {
+		DialectPostgres: {`( ( "foo" = $1 ) or ( ( "bar" > $2 ) and ( "baz" = $3 ) ) )`, []any{"it's", 1.5, true}},
+	},
+	`not foo in (1, 2) and bar is not null and baz is true`: {
+		DialectPostgres: {`( ( not ( "foo" in ( $1, $2 ) ) ) and ( "bar" is not null ) and ( "baz" is true ) )`, []any{int64(1), int64(2)}},
+	},
+	`foo in () or foo not in ()`: {
+		DialectPostgres: {`( ( false ) or ( true ) )`, nil},
+	},
+	`foo like 'a%' and foo not like '\%*_[x'`: {
+		DialectPostgres: {`( ( "foo" like $1 ) and ( "foo" not like $2 ) )`, []any{"a%", `\%*_[x`}},
+		DialectSqlite:   {`( ( "foo" glob ? ) and ( "foo" not glob ? ) )`, []any{"a*", "%[*]?[[]x"}},
+		DialectDuckDB:   {`( ( "foo" like ? escape '\' ) and ( "foo" not like ? escape '\' ) )`, []any{"a%", `\%*_[x`}},
+	},
+	`foo ilike 'a%'`: {
+		DialectPostgres: {`( "foo" ilike $1 )`, []any{"a%"}},
+		DialectSqlite:   {`( lower("foo") like lower(?) escape '\' )`, []any{"a%"}},
+		DialectDuckDB:   {`( "foo" ilike ? escape '\' )`, []any{"a%"}},
+	},
+	`tags ->> 'service' = 'ec2' and items -> 0 is not null`: {
+		DialectPostgres: {`( ( "tags" ->> $1::text = $2 ) and ( "items" -> $3::int is not null ) )`, []any{"service", "ec2", int64(0)}},
+		DialectSqlite:   {`( ( "tags" ->> ? = ? ) and ( "items" -> ? is not null ) )`, []any{"service", "ec2", int64(0)}},
+	},
+	`created_at > now() - interval '1 day 2 hours'`: {
+		DialectPostgres: {`( "created_at" > now() - cast($1 as interval) )`, []any{"1 day 2 hours"}},
+		DialectSqlite:   {`( "created_at" > datetime('now', ?, ?) )`, []any{"-1 days", "-7200.000000 seconds"}},
+		DialectDuckDB:   {`( "created_at" > cast(now() as timestamp) - cast(? as interval) )`, []any{"1 day 2 hours"}},
+	},
+	`created_at < now()`: {
+		DialectPostgres: {`( "created_at" < now() )`, nil},
+		DialectSqlite:   {`( "created_at" < datetime('now') )`, nil},
+	},
+}
+
+func TestComparisonToParameterisedSQL(t *testing.T) {
+	for tc, dialects := range parameterisedSQLCases {
+		parsed, err := Parse("", []byte(tc))
+		if err != nil {
+			t.Errorf("%q: want no parse error, got %v", tc, err)
+			continue
+		}
+		for dialect, exp := range dialects {
+			sql, args, err := ComparisonToParameterisedSQL(parsed.(ComparisonNode), WithDialect(dialect))
+			if err != nil {
+				t.Errorf("%q (%s): want no error, got %v", tc, dialect, err)
+				continue
+			}
+			if sql != exp.sql {
+				t.Errorf("%q (%s): want %s, got %s", tc, dialect, exp.sql, sql)
+			}
+			if !reflect.DeepEqual(args, exp.args) {
+				t.Errorf("%q (%s): want args %v, got %v", tc, dialect, exp.args, args)
+			}
+		}
+	}
+}
+
+func TestComparisonToParameterisedSQLErrors(t *testing.T) {
+	cases := map[string][]SQLOption{
+		`foo = 'foo' and bar = 'bar'`:                 {WithAllowedIdentifiers([]string{"foo"})},
+		`foo -> 'a' = 'b' or not baz`:                 {WithAllowedIdentifiers([]string{"foo"})},
+		`created_at > now() - interval '1 fortnight'`: nil,
+		`foo = 'foo'`:                                 {WithDialect("oracle")},
+	}
+	for tc, opts := range cases {
+		parsed, err := Parse("", []byte(tc))
+		if err != nil {
+			t.Errorf("%q: want no parse error, got %v", tc, err)
+			continue
+		}
+		if _, _, err := ComparisonToParameterisedSQL(parsed.(ComparisonNode), opts...); err == nil {
+			t.Errorf("%q: want error, got none", tc)
+		}
+	}
+
+	// allowed identifiers
+	parsed, _ := Parse("", []byte(`foo = 'foo' and "Bar" -> 'a' = 'b'`))
+	if _, _, err := ComparisonToParameterisedSQL(parsed.(ComparisonNode), WithAllowedIdentifiers([]string{"foo", "Bar"})); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+}
+
+// execute the SQL against in-memory databases, to check it is valid and matches the expected rows
+func TestComparisonToParameterisedSQLExecute(t *testing.T) {
+	dialects := map[Dialect]string{
+		DialectSqlite: "sqlite3",
+		DialectDuckDB: "duckdb",
+	}
+	cases := map[string][]string{
+		`name = 'a'`:                              {"a"},
+		`count >= 2 and name != 'c'`:              {"b"},
+		`name like 'a%' or name in ('c')`:         {"a", "c"},
+		`name ilike 'B'`:                          {"b"},
+		`name like 'A%'`:                          nil,
+		`name not like 'A%'`:                      {"a", "b", "c"},
+		`name like '\_%'`:                         nil,
+		`enabled is true`:                         {"a"},
+		`not enabled`:                             {"b"},
+		`count is null`:                           {"c"},
+		`name in ()`:                              nil,
+		`tags ->> 'service' = 'ec2'`:              {"a"},
+		`created_at > now() - interval '1 day'`:   {"a"},
+		`created_at < now() - interval '1 month'`: {"b"},
+	}
+
+	for dialect, driver := range dialects {
+		db, err := sql.Open(driver, "")
+		if err != nil {
+			t.Fatalf("%s: failed to open database: %v", dialect, err)
+		}
+		defer db.Close()
+
+		// use the database clock for the timestamps
+		now := map[Dialect]string{
+			DialectSqlite: "datetime('now')",
+			DialectDuckDB: "cast(now() as timestamp)",
+		}[dialect]
+		yearAgo := map[Dialect]string{
+			DialectSqlite: "datetime('now', '-1 years')",
+			DialectDuckDB: "cast(now() as timestamp) - interval '1 year'",
+		}[dialect]
+		createStatements := []string{
+			`create table t (name text, count integer, enabled boolean, tags json, created_at timestamp)`,
+			fmt.Sprintf(`insert into t values ('a', 1, true, '{"service": "ec2"}', %s)`, now),
+			fmt.Sprintf(`insert into t values ('b', 2, false, '{"service": "s3"}', %s)`, yearAgo),
+			`insert into t values ('c', null, null, null, null)`,
+		}
+		for _, stmt := range createStatements {
+			if _, err := db.Exec(stmt); err != nil {
+				t.Fatalf("%s: failed to create test data: %v", dialect, err)
+			}
+		}
+
+		for tc, exp := range cases {
+			parsed, err := Parse("", []byte(tc))
+			if err != nil {
+				t.Errorf("%q: want no parse error, got %v", tc, err)
+				continue
+			}
+			where, args, err := ComparisonToParameterisedSQL(parsed.(ComparisonNode), WithDialect(dialect))
+			if err != nil {
+				t.Errorf("%q (%s): want no error, got %v", tc, dialect, err)
+				continue
+			}
+			got, err := queryNames(db, "select name from t where "+where+" order by name", args)
+			if err != nil {
+				t.Errorf("%q (%s): query %s failed: %v", tc, dialect, where, err)
+				continue
+			}
+			if !slices.Equal(got, exp) {
+				t.Errorf("%q (%s): want %v, got %v", tc, dialect, exp, got)
+			}
+		}
+	}
+}
+
+func queryNames(db *sql.DB, query string, args []any) ([]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		res = append(res, name)
+	}
+	return res, rows.Err()
+}
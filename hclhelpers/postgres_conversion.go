@@ -14,6 +14,7 @@ import (
 )
 
 // GoToPostgresString convert a go value into a postgres representation of the value
+// (for other database dialects, use backend.ParamBinder)
 func GoToPostgresString(v any) (string, error) {
 	// pass false to indicate we want a slice to be returned as a PostgresSlice
 	return goToPostgresString(v, false)
@@ -108,6 +109,7 @@ func pgArraySuffixFromElem(elem any) string {
 }
 
 // CtyToPostgresString convert a cty value into a postgres representation of the value
+// (for other database dialects, use backend.ParamBinder)
 func CtyToPostgresString(v cty.Value) (valStr string, err error) {
 	ty := v.Type()
 
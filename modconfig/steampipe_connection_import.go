@@ -3,6 +3,7 @@ package modconfig
 import (
 	"github.com/hashicorp/hcl/v2"
 	gokit "github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/connection"
 	"github.com/turbot/pipe-fittings/utils"
 )
 
@@ -15,14 +16,18 @@ type ConnectionImport struct {
 	StartLineNumber int    `json:"start_line_number"`
 	EndLineNumber   int    `json:"end_line_number"`
 
-	Source      *string  `json:"source" cty:"source" hcl:"source"`
+	// the type of the source, e.g. steampipe (the default), aws or dotenv
+	Type   *string `json:"type,omitempty" cty:"type" hcl:"type,optional"`
+	Source *string `json:"source" cty:"source" hcl:"source,optional"`
+	// the names of the connections to import - these may be glob patterns, e.g. "prod_*"
 	Connections []string `json:"connections" cty:"connections" hcl:"connections,optional"`
 	Prefix      *string  `json:"prefix" cty:"prefix" hcl:"prefix,optional"`
 }
 
 func (c ConnectionImport) Equals(other ConnectionImport) bool {
 
-	return utils.PtrEqual(c.Source, other.Source) &&
+	return c.GetType() == other.GetType() &&
+		utils.PtrEqual(c.Source, other.Source) &&
 		gokit.StringSliceEqualIgnoreOrder(c.Connections, other.Connections) &&
 		utils.PtrEqual(c.Prefix, other.Prefix)
 }
@@ -33,6 +38,14 @@ func (c *ConnectionImport) SetFileReference(fileName string, startLineNumber int
 	c.EndLineNumber = endLineNumber
 }
 
+// GetType returns the type of the source, defaulting to steampipe
+func (c *ConnectionImport) GetType() string {
+	if c.Type == nil {
+		return connection.ConnectionImportTypeSteampipe
+	}
+	return *c.Type
+}
+
 func (c *ConnectionImport) GetSource() *string {
 	return c.Source
 }
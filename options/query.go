@@ -17,6 +17,8 @@ type Query struct {
 	Multi        *bool   `hcl:"multi" cty:"query_multi"`
 	Timing       *string `hcl:"timing" cty:"query_timing"` // parsed manually
 	AutoComplete *bool   `hcl:"autocomplete" cty:"query_autocomplete"`
+	// query timeout in seconds
+	Timeout *int `hcl:"timeout" cty:"query_timeout"`
 }
 
 func (t *Query) SetBaseProperties(otherOptions Options) {
@@ -42,6 +44,9 @@ func (t *Query) SetBaseProperties(otherOptions Options) {
 		if t.AutoComplete == nil && o.AutoComplete != nil {
 			t.AutoComplete = o.AutoComplete
 		}
+		if t.Timeout == nil && o.Timeout != nil {
+			t.Timeout = o.Timeout
+		}
 	}
 }
 
@@ -67,6 +72,9 @@ func (t *Query) ConfigMap() map[string]interface{} {
 	if t.AutoComplete != nil {
 		res[constants.ArgAutoComplete] = t.AutoComplete
 	}
+	if t.Timeout != nil {
+		res[constants.ArgDatabaseQueryTimeout] = t.Timeout
+	}
 	return res
 }
 
@@ -93,6 +101,9 @@ func (t *Query) Merge(otherOptions Options) {
 		if o.AutoComplete != nil {
 			t.AutoComplete = o.AutoComplete
 		}
+		if o.Timeout != nil {
+			t.Timeout = o.Timeout
+		}
 	}
 }
 
@@ -131,6 +142,11 @@ func (t *Query) String() string {
 	} else {
 		str = append(str, fmt.Sprintf("  AutoComplete: %v", *t.AutoComplete))
 	}
+	if t.Timeout == nil {
+		str = append(str, "  Timeout: nil")
+	} else {
+		str = append(str, fmt.Sprintf("  Timeout: %d", *t.Timeout))
+	}
 	return strings.Join(str, "\n")
 }
 
@@ -2,10 +2,13 @@ package parse
 
 import (
 	"fmt"
-	"github.com/turbot/pipe-fittings/modconfig"
 	"path"
+	"slices"
+	"strings"
 
 	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/pipe-fittings/connection"
+	"github.com/turbot/pipe-fittings/modconfig"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
@@ -62,9 +65,96 @@ func DecodeConnectionImport(configPath string, block *hcl.Block) (*modconfig.Con
 	// 	diags = append(diags, moreDiags...)
 	// }
 
+	diags = validateConnectionImport(connectionImport)
+	if len(diags) > 0 {
+		return nil, diags
+	}
+
 	return connectionImport, diags
 }
 
+func validateConnectionImport(connectionImport *modconfig.ConnectionImport) hcl.Diagnostics {
+	importType := connectionImport.GetType()
+	if !slices.Contains(connection.ConnectionImportTypes, importType) {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("invalid connection_import type '%s' - must be one of %s", importType, strings.Join(connection.ConnectionImportTypes, ", ")),
+				Subject:  connectionImport.GetDeclRange(),
+			},
+		}
+	}
+
+	// all other types have a default source
+	if connectionImport.Source == nil && (importType == connection.ConnectionImportTypeSteampipe || importType == connection.ConnectionImportTypeDotEnv) {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("connection_import '%s' requires a source", connectionImport.ShortName),
+				Subject:  connectionImport.GetDeclRange(),
+			},
+		}
+	}
+	return nil
+}
+
+// LoadConnectionImport decodes a connection_import block and imports the connections from its source.
+// This should be called by the application config loader for each connection_import block.
+// Steampipe connections are imported by the application, so for a steampipe import no connections are returned
+func LoadConnectionImport(configPath string, block *hcl.Block) (*modconfig.ConnectionImport, map[string]connection.PipelingConnection, hcl.Diagnostics) {
+	connectionImport, diags := DecodeConnectionImport(configPath, block)
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+
+	if connectionImport.GetType() == connection.ConnectionImportTypeSteampipe {
+		return connectionImport, nil, diags
+	}
+
+	conns, err := ImportConnections(connectionImport)
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("failed to import connections for connection_import '%s': %s", connectionImport.ShortName, err.Error()),
+			Subject:  connectionImport.GetDeclRange(),
+		})
+		return nil, nil, diags
+	}
+	return connectionImport, conns, diags
+}
+
+// ImportConnections imports the connections from the source of a connection_import block, applying the
+// connection filter and prefix of the block. Steampipe connection imports are not supported, as the
+// connections are imported by the application
+func ImportConnections(connectionImport *modconfig.ConnectionImport) (map[string]connection.PipelingConnection, error) {
+	source := connectionImport.Source
+	if source == nil {
+		defaultSource, err := connection.DefaultConnectionImportSource(connectionImport.GetType())
+		if err != nil {
+			return nil, err
+		}
+		source = &defaultSource
+	}
+
+	expandedSource, err := filehelpers.Tildefy(*source)
+	if err != nil {
+		return nil, err
+	}
+	filePaths, err := ResolveConnectionImportSource(&expandedSource)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []connection.ConnectionImportOption{
+		connection.WithImportFilter(connectionImport.Connections),
+		connection.WithImportDeclRange(*connectionImport.GetDeclRange()),
+	}
+	if connectionImport.Prefix != nil {
+		opts = append(opts, connection.WithImportPrefix(*connectionImport.Prefix))
+	}
+	return connection.ImportConnections(connectionImport.GetType(), filePaths, opts...)
+}
+
 func ResolveConnectionImportSource(source *string) ([]string, error) {
 	var filePaths []string
 
@@ -0,0 +1,132 @@
+package parse
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/turbot/pipe-fittings/app_specific_connection"
+	"github.com/turbot/pipe-fittings/connection"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+func TestLoadConnectionImportDotEnv(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "prod.env"), []byte("API_KEY=abc123\nREGION=eu-west-1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dev.env"), []byte("API_KEY=def456\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := fmt.Sprintf(`
+connection_import "env" {
+  type        = "dotenv"
+  source      = "%s"
+  connections = ["prod"]
+  prefix      = "env_"
+}
+`, filepath.ToSlash(filepath.Join(dir, "*.env")))
+
+	block := parseConnectionImportBlock(t, config)
+	connectionImport, conns, diags := LoadConnectionImport(dir, block)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	if connectionImport.GetType() != connection.ConnectionImportTypeDotEnv {
+		t.Errorf("expected import type %s, got %s", connection.ConnectionImportTypeDotEnv, connectionImport.GetType())
+	}
+	if len(conns) != 1 {
+		t.Fatalf("expected 1 connection, got %d", len(conns))
+	}
+	conn, ok := conns["env_prod"].(*connection.KeyValueConnection)
+	if !ok {
+		t.Fatalf("expected a key_value connection named env_prod, got %v", conns)
+	}
+	expectedValues := map[string]string{"API_KEY": "abc123", "REGION": "eu-west-1"}
+	if conn.Values == nil || !maps.Equal(*conn.Values, expectedValues) {
+		t.Errorf("expected values %v, got %v", expectedValues, conn.Values)
+	}
+
+	// the imported connection must round trip through its cty value
+	ctyVal, err := conn.CtyValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTripped, err := app_specific_connection.CtyValueToConnection(ctyVal)
+	if err != nil {
+		t.Fatalf("failed to convert cty value to connection: %s", err)
+	}
+	if !conn.Equals(roundTripped) {
+		t.Errorf("expected round tripped connection to equal %v, got %v", conn, roundTripped)
+	}
+}
+
+func TestLoadConnectionImportSteampipe(t *testing.T) {
+	block := parseConnectionImportBlock(t, `
+connection_import "steampipe" {
+  source = "~/.steampipe/config/*.spc"
+}
+`)
+	connectionImport, conns, diags := LoadConnectionImport(".", block)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	if connectionImport.GetType() != connection.ConnectionImportTypeSteampipe {
+		t.Errorf("expected import type %s, got %s", connection.ConnectionImportTypeSteampipe, connectionImport.GetType())
+	}
+	// steampipe connections are imported by the application
+	if conns != nil {
+		t.Errorf("expected no connections, got %v", conns)
+	}
+}
+
+func TestLoadConnectionImportInvalidSource(t *testing.T) {
+	block := parseConnectionImportBlock(t, fmt.Sprintf(`
+connection_import "env" {
+  type   = "dotenv"
+  source = "%s"
+}
+`, filepath.ToSlash(writeInvalidDotEnv(t))))
+
+	_, _, diags := LoadConnectionImport(".", block)
+	if !diags.HasErrors() {
+		t.Errorf("expected an error importing an invalid .env file")
+	}
+}
+
+func parseConnectionImportBlock(t *testing.T, config string) *hcl.Block {
+	t.Helper()
+	file, diags := hclsyntax.ParseConfig([]byte(config), "config.fpc", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("failed to parse config: %s", diags.Error())
+	}
+	content, diags := file.Body.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{
+				Type:       schema.BlockTypeConnectionImport,
+				LabelNames: []string{schema.LabelName},
+			},
+		},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("failed to decode config: %s", diags.Error())
+	}
+	if len(content.Blocks) != 1 {
+		t.Fatalf("expected 1 block, got %d", len(content.Blocks))
+	}
+	return content.Blocks[0]
+}
+
+func writeInvalidDotEnv(t *testing.T) string {
+	t.Helper()
+	source := filepath.Join(t.TempDir(), "invalid.env")
+	if err := os.WriteFile(source, []byte("NOT A VALUE\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return source
+}
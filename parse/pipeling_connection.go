@@ -50,7 +50,11 @@ func DecodePipelingConnection(configPath string, block *hcl.Block) (connection.P
 		return nil, diags
 	}
 	// now decode the rest of the block
-	diags = gohcl.DecodeBody(remainderBody, evalCtx, conn)
+	if bodyDecoder, ok := conn.(connection.BodyDecoder); ok {
+		diags = bodyDecoder.DecodeBody(remainderBody, evalCtx)
+	} else {
+		diags = gohcl.DecodeBody(remainderBody, evalCtx, conn)
+	}
 	if diags.HasErrors() {
 		return nil, diags
 	}
@@ -21,7 +21,7 @@ func NewJsonPrinter[T any]() (*JsonPrinter[T], error) {
 
 func (p JsonPrinter[T]) PrintResource(ctx context.Context, r PrintableResource[T], writer io.Writer) error {
 	// marshal
-	s, err := json.Marshal(r.GetItems())
+	s, err := json.Marshal(getPrintableItems(r))
 	if err != nil {
 		return err
 	}
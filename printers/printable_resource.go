@@ -1,8 +1,24 @@
 package printers
 
+import (
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/sanitize"
+)
+
 type Printer int
 
 type PrintableResource[T any] interface {
 	GetItems() []T
 	GetTable() (*Table, error)
 }
+
+// getPrintableItems returns the items of the resource, with the values of fields tagged as secrets redacted
+// unless the show-secrets arg is set
+func getPrintableItems[T any](r PrintableResource[T]) []T {
+	items := r.GetItems()
+	if viper.GetBool(constants.ArgShowSecrets) {
+		return items
+	}
+	return sanitize.RedactSecrets(items)
+}
@@ -22,7 +22,7 @@ func NewShowPrinter[T any]() (*ShowPrinter[T], error) {
 }
 
 func (p ShowPrinter[T]) PrintResource(_ context.Context, r PrintableResource[T], writer io.Writer) error {
-	items := r.GetItems()
+	items := getPrintableItems(r)
 	if len(items) != 1 {
 		return fmt.Errorf("expected exactly one item, got %d", len(items))
 	}
@@ -84,6 +84,13 @@ func (d *RowData) AddField(f FieldValue) {
 	d.Fields[columnName] = f
 }
 
+// GetColumnValue returns the value of the column, and whether the row has the column
+// (this implements filter.Row, so a filter may be evaluated against the row)
+func (d *RowData) GetColumnValue(column string) (any, bool) {
+	f, ok := d.Fields[strings.ToLower(column)]
+	return f.Value, ok
+}
+
 func (d *RowData) GetRow() *TableRow {
 	row := NewTableRow()
 	row.Columns = d.GetDisplayColumns()
@@ -30,7 +30,7 @@ func NewStringPrinter[T any]() (*StringPrinter[T], error) {
 }
 
 func (p StringPrinter[T]) PrintResource(_ context.Context, r PrintableResource[T], writer io.Writer) error {
-	items := r.GetItems()
+	items := getPrintableItems(r)
 	enableColor := viper.GetString(constants.ArgOutput) == constants.OutputFormatPretty
 	for _, item := range items {
 		if item, isSanitizedStringer := any(item).(sanitize.SanitizedStringer); isSanitizedStringer {
@@ -32,7 +32,7 @@ func format(attr color.Attribute) string {
 
 func (px YamlPrinter[T]) PrintResource(ctx context.Context, r PrintableResource[T], writer io.Writer) error {
 	// marshal to json to avoid having to put yaml tags on all structs
-	s, err := json.Marshal(r.GetItems())
+	s, err := json.Marshal(getPrintableItems(r))
 	if err != nil {
 		return err
 	}
@@ -3,11 +3,12 @@ package querydisplay
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/turbot/pipe-fittings/queryresult"
+	"strings"
 	"time"
 
 	typeHelpers "github.com/turbot/go-kit/types"
 	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/queryresult"
 )
 
 // columnNames builds a list of name from a slice of column defs - respecting the original name if present
@@ -65,6 +66,15 @@ func ColumnValueAsString(val interface{}, col *queryresult.ColumnDef, opts ...Co
 		return opt.nullString, nil
 	}
 
+	// display arrays as a comma separated list of elements
+	if arr, ok := val.([]any); ok && queryresult.IsArrayDataType(col.DataType) {
+		elements := make([]string, len(arr))
+		for i, e := range arr {
+			elements[i] = typeHelpers.ToString(e)
+		}
+		return strings.Join(elements, ","), nil
+	}
+
 	//log.Printf("[TRACE] ColumnValueAsString type %s", colType.DatabaseTypeName())
 	// possible types for colType are defined in pq/oid/types.go
 	switch col.DataType {
@@ -0,0 +1,21 @@
+package queryresult
+
+import "fmt"
+
+// CellError is returned when the value of a single cell of a row cannot be read
+type CellError struct {
+	Column  string
+	Backend string
+	Err     error
+}
+
+func (e *CellError) Error() string {
+	if e.Backend == "" {
+		return fmt.Sprintf("failed to read column '%s': %s", e.Column, e.Err.Error())
+	}
+	return fmt.Sprintf("failed to read column '%s' from %s backend: %s", e.Column, e.Backend, e.Err.Error())
+}
+
+func (e *CellError) Unwrap() error {
+	return e.Err
+}
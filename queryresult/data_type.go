@@ -0,0 +1,71 @@
+package queryresult
+
+import "strings"
+
+// Canonical data types reported by ColumnDef.DataType, regardless of backend.
+// The names follow the postgres type names, and each has a canonical Go representation:
+const (
+	DataTypeText        = "TEXT"        // string
+	DataTypeInt         = "INT8"        // int64
+	DataTypeFloat       = "FLOAT8"      // float64
+	DataTypeDecimal     = "NUMERIC"     // string, the exact decimal representation, e.g. 12.34
+	DataTypeBool        = "BOOL"        // bool
+	DataTypeJSON        = "JSONB"       // decoded JSON: map[string]any, []any, string, float64, bool or nil
+	DataTypeDate        = "DATE"        // time.Time
+	DataTypeTime        = "TIME"        // string, formatted as 15:04:05
+	DataTypeTimestamp   = "TIMESTAMP"   // time.Time
+	DataTypeTimestampTz = "TIMESTAMPTZ" // time.Time
+	DataTypeInterval    = "INTERVAL"    // string, formatted as postgres does, e.g. 1 year 2 days 03:00:00
+	DataTypeUUID        = "UUID"        // string
+	DataTypeInet        = "INET"        // string
+	DataTypeBytes       = "BYTEA"       // []byte
+)
+
+// arrays are represented as the element type prefixed with an underscore, e.g. _TEXT, with values of type []any
+const arrayDataTypePrefix = "_"
+
+var canonicalDataTypes = map[string]struct{}{
+	DataTypeText:        {},
+	DataTypeInt:         {},
+	DataTypeFloat:       {},
+	DataTypeDecimal:     {},
+	DataTypeBool:        {},
+	DataTypeJSON:        {},
+	DataTypeDate:        {},
+	DataTypeTime:        {},
+	DataTypeTimestamp:   {},
+	DataTypeTimestampTz: {},
+	DataTypeInterval:    {},
+	DataTypeUUID:        {},
+	DataTypeInet:        {},
+	DataTypeBytes:       {},
+}
+
+// IsCanonicalDataType returns true if the data type is one of the canonical data types, or an array of one
+func IsCanonicalDataType(dataType string) bool {
+	if elem, ok := ArrayElementDataType(dataType); ok {
+		dataType = elem
+	}
+	_, ok := canonicalDataTypes[dataType]
+	return ok
+}
+
+// ArrayDataType returns the array data type for the given element data type
+func ArrayDataType(elemDataType string) string {
+	return arrayDataTypePrefix + elemDataType
+}
+
+// IsArrayDataType returns true if the data type is an array
+func IsArrayDataType(dataType string) bool {
+	_, ok := ArrayElementDataType(dataType)
+	return ok
+}
+
+// ArrayElementDataType returns the element data type of an array data type
+func ArrayElementDataType(dataType string) (string, bool) {
+	elem, ok := strings.CutPrefix(dataType, arrayDataTypePrefix)
+	if !ok || elem == "" {
+		return "", false
+	}
+	return elem, true
+}
@@ -7,6 +7,9 @@ import (
 type RowResult struct {
 	Data  []interface{}
 	Error error
+	// Warnings contains any errors which did not prevent the row being returned,
+	// for example cells which could not be read and have been set to null
+	Warnings []error
 }
 type TimingMetadata struct {
 	Duration time.Duration
@@ -49,6 +52,11 @@ func (r *Result[T]) StreamError(err error) {
 	r.RowChan <- &RowResult{Error: err}
 }
 
+// StreamRowResult streams a RowResult, allowing data to be streamed along with warnings
+func (r *Result[T]) StreamRowResult(rowResult *RowResult) {
+	r.RowChan <- rowResult
+}
+
 type SyncQueryResult struct {
 	Rows   []interface{}
 	Cols   []*ColumnDef
@@ -173,12 +173,17 @@ type SanitizerOptions struct {
 	ExcludePatterns []string
 
 	ImportCodeMatchers bool
+
+	// ShowSecrets disables the redaction of struct fields tagged as secrets (see SecretTag) by Sanitize and
+	// SanitizeStruct - this should only be set when the unredacted values are required, e.g. for execution
+	ShowSecrets bool
 }
 
 type Sanitizer struct {
 	regexes             []*regexp.Regexp
 	fieldPatternRegexes []*regexp.Regexp
 	excludeFields       map[string]struct{}
+	showSecrets         bool
 }
 
 var codePluginExcludedRegex = []string{
@@ -191,6 +196,7 @@ var codePluginExcludedRegex = []string{
 func NewSanitizer(opts SanitizerOptions) *Sanitizer {
 	s := &Sanitizer{
 		excludeFields: helpers.SliceToLookup(opts.ExcludeFields),
+		showSecrets:   opts.ShowSecrets,
 	}
 
 	builtInExcludeFields := opts.ExcludeFields
@@ -318,14 +324,24 @@ func RedactDbConnectionPassword(connectionString string) string {
 	return re.ReplaceAllString(connectionString, `${protocol}${username}:REDACTED${rest}`)
 }
 
+// RedactSecrets returns a copy of v with the values of fields tagged as secrets redacted,
+// unless the sanitizer was created with ShowSecrets set
+func (s *Sanitizer) RedactSecrets(v any) any {
+	if s.showSecrets {
+		return v
+	}
+	return RedactSecrets(v)
+}
+
 // Sanitize takes any value and returns a sanitized version of the value.
 // If the value is a string, then it is sanitized.
-// Otherwise the value is marshaled to JSON and then sanitized.
+// Otherwise the fields tagged as secrets are redacted, and the value is marshaled to JSON and then sanitized.
 // Attempt to marshal back to original type but if this fails, return the json
 func (s *Sanitizer) Sanitize(v any) any {
 	valStr, isString := v.(string)
 
 	if !isString {
+		v = s.RedactSecrets(v)
 		jsonBytes, err := json.Marshal(v)
 		if err != nil {
 			return RedactedStr
@@ -355,6 +371,10 @@ func (s *Sanitizer) Sanitize(v any) any {
 func SanitizeStruct[T any](s *Sanitizer, v T) (T, error) {
 	var empty T
 
+	if !s.showSecrets {
+		v = RedactSecrets(v)
+	}
+
 	jsonBytes, err := json.Marshal(v)
 	if err != nil {
 		return empty, err
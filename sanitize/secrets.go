@@ -0,0 +1,308 @@
+package sanitize
+
+import (
+	"reflect"
+	"sync"
+)
+
+// SecretTag is the struct tag which marks a field as containing a secret, i.e. `secret:"true"`.
+// Secret fields may be strings, string pointers, or slices or maps of strings
+const SecretTag = "secret"
+
+// Redactable is implemented by types which redact their own secrets, e.g. types which store secrets in
+// unexported fields. Redacted must return a value of the same type with the secrets redacted
+type Redactable interface {
+	Redacted() any
+}
+
+// IsSecretField returns whether the struct field is tagged as a secret
+func IsSecretField(field reflect.StructField) bool {
+	return field.Tag.Get(SecretTag) == "true"
+}
+
+// RedactSecrets returns a copy of v with the values of all fields tagged as secrets replaced with RedactedStr.
+// Pointers, slices, maps and interfaces are traversed - any which contain secrets are copied rather than modified,
+// so v itself is not changed. Empty values are not redacted
+func RedactSecrets[T any](v T) T {
+	r := &redactor{visited: make(map[visitKey]*visitedPointer)}
+	res, changed := r.redact(reflect.ValueOf(&v).Elem())
+	if !changed {
+		return v
+	}
+	return res.Interface().(T)
+}
+
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+type visitedPointer struct {
+	// the redacted copy of the pointer - this is allocated before the element is traversed, so cyclic
+	// references may refer to it
+	res reflect.Value
+	// whether the element contains secrets, or the copy is referenced by a cyclic reference
+	changed bool
+	// whether the traversal of the element is complete
+	done bool
+}
+
+type redactor struct {
+	// the pointers visited so far, so cyclic references are only traversed once
+	visited map[visitKey]*visitedPointer
+}
+
+// redact returns the redacted value, and whether it differs from the original value
+func (r *redactor) redact(v reflect.Value) (reflect.Value, bool) {
+	if !v.IsValid() || !mayContainSecrets(v.Type()) {
+		return v, false
+	}
+
+	if v.CanInterface() && v.Type().Implements(redactableType) && !isNil(v) {
+		redacted := reflect.ValueOf(v.Interface().(Redactable).Redacted())
+		if redacted.IsValid() && redacted.Type().AssignableTo(v.Type()) {
+			return redacted, true
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v, false
+		}
+		key := visitKey{v.Pointer(), v.Type()}
+		if visited, ok := r.visited[key]; ok {
+			if visited.done {
+				return visited.res, visited.changed
+			}
+			// the pointer is still being traversed, so this is a cyclic reference - refer to the copy
+			visited.changed = true
+			return visited.res, true
+		}
+		visited := &visitedPointer{res: reflect.New(v.Type().Elem())}
+		r.visited[key] = visited
+		elem, changed := r.redact(v.Elem())
+		visited.changed = visited.changed || changed
+		visited.done = true
+		if !visited.changed {
+			// no copy is required - record the original pointer for any further references
+			visited.res = v
+			return v, false
+		}
+		visited.res.Elem().Set(elem)
+		return visited.res, true
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, false
+		}
+		elem, changed := r.redact(v.Elem())
+		if !changed {
+			return v, false
+		}
+		res := reflect.New(v.Type()).Elem()
+		res.Set(elem)
+		return res, true
+
+	case reflect.Struct:
+		var res reflect.Value
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			var fieldValue reflect.Value
+			var changed bool
+			if IsSecretField(field) {
+				fieldValue, changed = redactSecretValue(v.Field(i))
+			} else {
+				fieldValue, changed = r.redact(v.Field(i))
+			}
+			if !changed {
+				continue
+			}
+			// copy the struct on the first change
+			if !res.IsValid() {
+				res = reflect.New(v.Type()).Elem()
+				res.Set(v)
+			}
+			res.Field(i).Set(fieldValue)
+		}
+		if !res.IsValid() {
+			return v, false
+		}
+		return res, true
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return v, false
+		}
+		var res reflect.Value
+		for i := 0; i < v.Len(); i++ {
+			elem, changed := r.redact(v.Index(i))
+			if !changed {
+				continue
+			}
+			// copy the slice on the first change
+			if !res.IsValid() {
+				res = copyList(v)
+			}
+			res.Index(i).Set(elem)
+		}
+		if !res.IsValid() {
+			return v, false
+		}
+		return res, true
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v, false
+		}
+		var res reflect.Value
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, changed := r.redact(iter.Value())
+			if !changed {
+				continue
+			}
+			// copy the map on the first change
+			if !res.IsValid() {
+				res = reflect.MakeMapWithSize(v.Type(), v.Len())
+				copyIter := v.MapRange()
+				for copyIter.Next() {
+					res.SetMapIndex(copyIter.Key(), copyIter.Value())
+				}
+			}
+			res.SetMapIndex(iter.Key(), elem)
+		}
+		if !res.IsValid() {
+			return v, false
+		}
+		return res, true
+	}
+	return v, false
+}
+
+// redactSecretValue redacts the value of a secret field, which may be a string, or a pointer to, slice of
+// or map of strings
+func redactSecretValue(v reflect.Value) (reflect.Value, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.Len() == 0 {
+			return v, false
+		}
+		res := reflect.New(v.Type()).Elem()
+		res.SetString(RedactedStr)
+		return res, true
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v, false
+		}
+		elem, changed := redactSecretValue(v.Elem())
+		if !changed {
+			return v, false
+		}
+		res := reflect.New(v.Type().Elem())
+		res.Elem().Set(elem)
+		return res, true
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, false
+		}
+		res := copyList(v)
+		changed := false
+		for i := 0; i < v.Len(); i++ {
+			if elem, elemChanged := redactSecretValue(v.Index(i)); elemChanged {
+				res.Index(i).Set(elem)
+				changed = true
+			}
+		}
+		return res, changed
+	case reflect.Map:
+		if v.IsNil() {
+			return v, false
+		}
+		res := reflect.MakeMapWithSize(v.Type(), v.Len())
+		changed := false
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, elemChanged := redactSecretValue(iter.Value())
+			changed = changed || elemChanged
+			res.SetMapIndex(iter.Key(), elem)
+		}
+		return res, changed
+	}
+	return v, false
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+func copyList(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Array {
+		res := reflect.New(v.Type()).Elem()
+		res.Set(v)
+		return res
+	}
+	res := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	reflect.Copy(res, v)
+	return res
+}
+
+var redactableType = reflect.TypeOf((*Redactable)(nil)).Elem()
+
+var (
+	// map of type to whether values of the type may contain secrets
+	secretTypes     = make(map[reflect.Type]bool)
+	secretTypesLock sync.Mutex
+)
+
+// mayContainSecrets returns whether values of the type may contain secrets, i.e. whether the type has a field tagged
+// as a secret, implements Redactable, or contains an interface (which may hold a value containing secrets)
+func mayContainSecrets(t reflect.Type) bool {
+	secretTypesLock.Lock()
+	defer secretTypesLock.Unlock()
+	if res, ok := secretTypes[t]; ok {
+		return res
+	}
+	res := mayContainSecretsLocked(t, make(map[reflect.Type]struct{}))
+	secretTypes[t] = res
+	return res
+}
+
+// mayContainSecretsLocked evaluates the type, treating types which are already being evaluated as not containing
+// secrets, in case the type is recursive. As the result for a nested type may therefore depend on its parents,
+// only positive results are cached for nested types
+func mayContainSecretsLocked(t reflect.Type, visiting map[reflect.Type]struct{}) bool {
+	if res, ok := secretTypes[t]; ok {
+		return res
+	}
+	if _, ok := visiting[t]; ok {
+		return false
+	}
+	visiting[t] = struct{}{}
+
+	res := t.Implements(redactableType)
+	if !res {
+		switch t.Kind() {
+		case reflect.Interface:
+			res = true
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			res = mayContainSecretsLocked(t.Elem(), visiting)
+		case reflect.Struct:
+			for i := 0; i < t.NumField() && !res; i++ {
+				field := t.Field(i)
+				res = field.IsExported() && (IsSecretField(field) || mayContainSecretsLocked(field.Type, visiting))
+			}
+		}
+	}
+	if res {
+		secretTypes[t] = true
+	}
+	return res
+}
@@ -0,0 +1,63 @@
+package sanitize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCredentials struct {
+	User     string
+	Password *string           `secret:"true"`
+	Keys     map[string]string `secret:"true"`
+}
+
+type testConfig struct {
+	Name        string
+	Credentials *testCredentials
+	Items       []any
+	Children    map[string]*testConfig
+}
+
+func TestRedactSecrets(t *testing.T) {
+	password := "p@ss"
+	creds := &testCredentials{User: "alice", Password: &password, Keys: map[string]string{"a": "1", "b": ""}}
+	config := &testConfig{
+		Name:        "parent",
+		Credentials: creds,
+		Items:       []any{"plain", creds},
+	}
+	config.Children = map[string]*testConfig{"self": config}
+
+	redacted := RedactSecrets(config)
+
+	// the original is not modified
+	assert.Equal(t, "p@ss", *config.Credentials.Password)
+	assert.Equal(t, "1", config.Credentials.Keys["a"])
+
+	assert.Equal(t, "parent", redacted.Name)
+	assert.Equal(t, "alice", redacted.Credentials.User)
+	assert.Equal(t, RedactedStr, *redacted.Credentials.Password)
+	assert.Equal(t, map[string]string{"a": RedactedStr, "b": ""}, redacted.Credentials.Keys)
+	assert.Equal(t, "plain", redacted.Items[0])
+	assert.Equal(t, RedactedStr, *redacted.Items[1].(*testCredentials).Password)
+	// cyclic references refer to the redacted copy
+	assert.Same(t, redacted, redacted.Children["self"])
+
+	// values without secrets are returned as is
+	plain := &testConfig{Name: "plain"}
+	assert.Same(t, plain, RedactSecrets(plain))
+}
+
+func TestSanitizer_ShowSecrets(t *testing.T) {
+	password := "p@ss"
+	creds := testCredentials{User: "alice", Password: &password}
+
+	res, err := SanitizeStruct(NewSanitizer(SanitizerOptions{}), creds)
+	assert.NoError(t, err)
+	assert.Equal(t, RedactedStr, *res.Password)
+
+	res, err = SanitizeStruct(NewSanitizer(SanitizerOptions{ShowSecrets: true}), creds)
+	assert.NoError(t, err)
+	assert.Equal(t, "p@ss", *res.Password)
+}
@@ -2,6 +2,7 @@ package sslio
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
@@ -30,6 +31,28 @@ func ParseCertificateInLocation(location string) (*x509.Certificate, error) {
 	return x509.ParseCertificate(rootPemBlock.Bytes)
 }
 
+// ParsePrivateKeyInLocation parses a PEM encoded PKCS #1, PKCS #8 or EC private key
+func ParsePrivateKeyInLocation(location string) (crypto.PrivateKey, error) {
+	keyRaw, err := os.ReadFile(location)
+	if err != nil {
+		return nil, err
+	}
+	keyPemBlock, _ := pem.Decode(keyRaw)
+	if keyPemBlock == nil {
+		return nil, fmt.Errorf("could not decode PEM blocks from private key at %s", location)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(keyPemBlock.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(keyPemBlock.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(keyPemBlock.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("could not parse private key at %s", location)
+}
+
 func WriteCertificate(path string, certificate []byte) error {
 	return writeAsPEM(path, "CERTIFICATE", certificate)
 }
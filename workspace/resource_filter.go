@@ -1,22 +1,21 @@
 package workspace
 
 import (
-	"fmt"
-	"log"
 	"net/url"
+	"reflect"
 	"slices"
-	"strings"
+	"time"
 
-	"github.com/danwakefield/fnmatch"
 	"github.com/turbot/pipe-fittings/filter"
 	"github.com/turbot/pipe-fittings/modconfig"
-	"github.com/turbot/pipe-fittings/printers"
 	"github.com/turbot/pipe-fittings/sperr"
-	"golang.org/x/exp/maps"
 )
 
 type ResourceFilter struct {
-	Where          string
+	Where string
+	// if set, the columns which Where may refer to - these are the lower case column names of the resource show data
+	// if not set, FilterWorkspaceResourcesOfType derives it from the show data of the resources being filtered
+	Schema         filter.Schema
 	Tags           map[string][]string
 	WherePredicate func(item modconfig.HclResource) bool
 }
@@ -92,142 +91,73 @@ func (f *ResourceFilter) parseFilter() (func(resource modconfig.HclResource) boo
 			return true
 		}, nil
 	}
-	parsed, err := filter.Parse("", []byte(f.Where))
+	predicate, err := filter.Compile(f.Where, f.Schema)
 	if err != nil {
-		log.Printf("err %v", err)
-		return nil, sperr.New("failed to parse 'where' property: %s", err.Error())
-	}
-
-	// convert table schema into a column map
-
-	columnFilter, err := newColumnFilter(parsed.(filter.ComparisonNode))
-	if err != nil {
-		return nil, err
+		return nil, sperr.New("invalid 'where' property:\n%s", err.Error())
 	}
 
 	// now build the predicate
 	p := func(resource modconfig.HclResource) bool {
-		data := resource.GetShowData()
-
-		if _, containsColumn := data.Fields[columnFilter.column]; !containsColumn {
-			return false
-		}
-
-		return columnFilter.evaluate(data)
+		return predicate.Match(resource.GetShowData())
 	}
 	return p, nil
 }
 
-type columnFilter struct {
-	column   string
-	operator string
-	values   []string
-}
-
-func newColumnFilter(cn filter.ComparisonNode) (columnFilter, error) {
-	var res columnFilter
-
-	switch cn.Type {
-
-	case "compare", "like":
-		codeNodes, ok := cn.Values.([]filter.CodeNode)
-		if !ok {
-			return res, fmt.Errorf("failed to parse cn")
-		}
-		if len(codeNodes) != 2 {
-			return res, fmt.Errorf("failed to parse cn")
-		}
-
-		res.column = codeNodes[0].Value
-		res.values = append(res.values, codeNodes[1].Value)
-		res.operator = cn.Operator.Value
-
-	case "in":
-		res.operator = cn.Operator.Value
-
-		codeNodes, ok := cn.Values.([]filter.CodeNode)
-		if !ok || len(codeNodes) < 2 {
-			return res, fmt.Errorf("failed to parse cn")
-		}
-		res.column = codeNodes[0].Value
-
-		// Build look up of values to dedupe
-		valuesMap := make(map[string]struct{}, len(codeNodes)-1)
-		for _, c := range codeNodes[1:] {
-			valuesMap[c.Value] = struct{}{}
-		}
-		res.values = maps.Keys(valuesMap)
-
-	default:
-		return res, fmt.Errorf("failed to convert 'where' arg to qual")
+// resourceFilterSchema returns the schema of the show data of the resources, i.e. the lower case column names
+// and the types of their values, or nil if there are no resources
+// if resources have different types for a column, or the type of a column is not known, it is not type checked
+func resourceFilterSchema[T modconfig.HclResource](resources map[string]T) filter.Schema {
+	if len(resources) == 0 {
+		return nil
 	}
-
-	return res, nil
-}
-
-// evaluateFilter evaluates whether the f.column filter passes for the given resource
-func (f columnFilter) evaluate(data *printers.RowData) bool {
-	switch f.operator {
-	case "=":
-		return data.Fields[f.column].ValueString() == f.values[0]
-	case "!=":
-		return data.Fields[f.column].ValueString() != f.values[0]
-	// TODO cast as number??
-	//case "<":
-	//case "<=":
-	//case ">":
-	//case ">=":
-	case "~~", "like":
-		return SqlLike(data.Fields[f.column].ValueString(), f.values[0], true)
-	case "!~~", "not like":
-		return !SqlLike(data.Fields[f.column].ValueString(), f.values[0], true)
-	case "~~*", "ilike":
-		return SqlLike(data.Fields[f.column].ValueString(), f.values[0], false)
-	case "!~~*", "not ilike":
-		return !SqlLike(data.Fields[f.column].ValueString(), f.values[0], false)
-	case "in":
-		for _, v := range f.values {
-			if data.Fields[f.column].ValueString() == v {
-				return true
+	var schema = filter.Schema{}
+	// the columns for which no resource has had a value of known type
+	var untyped = map[string]bool{}
+	for _, resource := range resources {
+		showData := resource.GetShowData()
+		for _, column := range showData.Columns {
+			columnType, ok := showDataColumnType(reflect.TypeOf(showData.Fields[column].Value))
+			existing, seen := schema[column]
+			switch {
+			case !seen || untyped[column]:
+				schema[column] = columnType
+				untyped[column] = !ok
+			case ok && columnType != existing:
+				schema[column] = filter.ColumnTypeAny
 			}
 		}
-		return false
-	case "not in":
-		for _, v := range f.values {
-			if data.Fields[f.column].ValueString() == v {
-				return false
-			}
-		}
-		return true
-	default:
-		return false
 	}
+	return schema
 }
 
-// SqlLike simulates SQL LIKE pattern matching using fnmatch, with an option for case sensitivity.
-func SqlLike(input, pattern string, caseSensitive bool) bool {
-	flag := 0
-	if !caseSensitive {
-		flag = fnmatch.FNM_CASEFOLD
+// showDataColumnType returns the filter column type of a show data value of the given type, and whether the
+// type is known
+func showDataColumnType(t reflect.Type) (filter.ColumnType, bool) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
 	}
-	// convert he sql pattern to fnmatch pattern
-	fnmatchPattern := sqlLikeToFnmatch(pattern)
-	return fnmatch.Match(fnmatchPattern, input, flag)
-
+	if t == nil {
+		return filter.ColumnTypeAny, false
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return filter.ColumnTypeTimestamp, true
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return filter.ColumnTypeString, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return filter.ColumnTypeNumber, true
+	case reflect.Bool:
+		return filter.ColumnTypeBool, true
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		return filter.ColumnTypeJson, true
+	}
+	return filter.ColumnTypeAny, false
 }
 
-// sqlLikeToFnmatch converts a SQL LIKE pattern to an fnmatch pattern
-func sqlLikeToFnmatch(pattern string) string {
-	// Replace SQL '%' wildcard with fnmatch '*' wildcard
-	pattern = strings.ReplaceAll(pattern, "%", "*")
-
-	// Replace SQL '_' wildcard with fnmatch '?' wildcard
-	pattern = strings.ReplaceAll(pattern, "_", "?")
-
-	// Handle escaped '%' and '_' characters
-	// This example assumes '\' is used as the escape character in the SQL pattern
-	pattern = strings.ReplaceAll(pattern, "\\%", "%")
-	pattern = strings.ReplaceAll(pattern, "\\_", "_")
-
-	return pattern
+// SqlLike simulates SQL LIKE pattern matching, with an option for case sensitivity.
+func SqlLike(input, pattern string, caseSensitive bool) bool {
+	return filter.Like(input, pattern, caseSensitive)
 }
@@ -0,0 +1,60 @@
+package workspace
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/filter"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/printers"
+)
+
+type filterTestResource struct {
+	modconfig.HclResourceImpl
+	extra any
+}
+
+func (r *filterTestResource) GetShowData() *printers.RowData {
+	res := printers.NewRowData(
+		printers.NewFieldValue("Count", 1),
+		printers.NewFieldValue("Extra", r.extra),
+	)
+	res.Merge(r.HclResourceImpl.GetShowData())
+	return res
+}
+
+func TestResourceFilterSchema(t *testing.T) {
+	resources := map[string]*filterTestResource{
+		"a": {HclResourceImpl: modconfig.HclResourceImpl{FullName: "mod.query.a"}},
+		"b": {HclResourceImpl: modconfig.HclResourceImpl{FullName: "mod.query.b"}, extra: true},
+		"c": {HclResourceImpl: modconfig.HclResourceImpl{FullName: "mod.query.c"}, extra: "c"},
+		"d": {HclResourceImpl: modconfig.HclResourceImpl{FullName: "mod.query.d"}, extra: false},
+	}
+	expected := filter.Schema{
+		"name":          filter.ColumnTypeString,
+		"title":         filter.ColumnTypeString,
+		"description":   filter.ColumnTypeString,
+		"documentation": filter.ColumnTypeString,
+		"tags":          filter.ColumnTypeJson,
+		"count":         filter.ColumnTypeNumber,
+		// the types of extra differ between resources, so it is not type checked
+		"extra": filter.ColumnTypeAny,
+	}
+	if got := resourceFilterSchema(resources); !maps.Equal(got, expected) {
+		t.Errorf("want schema %v, got %v", expected, got)
+	}
+
+	if got := resourceFilterSchema(map[string]*filterTestResource{}); got != nil {
+		t.Errorf("want no schema without resources, got %v", got)
+	}
+
+	// the where clause is checked against the schema
+	f := ResourceFilter{Where: "nmae = 'a'", Schema: resourceFilterSchema(resources)}
+	if _, err := f.getPredicate(); err == nil {
+		t.Errorf("want an error for an unknown column")
+	}
+	f = ResourceFilter{Where: "count > 0 and extra = 1", Schema: resourceFilterSchema(resources)}
+	if _, err := f.getPredicate(); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+}
@@ -26,21 +26,23 @@ func GetWorkspaceResourcesOfType[T modconfig.HclResource](w *Workspace) map[stri
 func FilterWorkspaceResourcesOfType[T modconfig.HclResource](w *Workspace, filter ResourceFilter) (map[string]T, error) {
 	var res = map[string]T{}
 
+	resources := GetWorkspaceResourcesOfType[T](w)
+	// if no schema was provided, check the 'where' clause against the show data columns of the resources
+	if filter.Schema == nil && filter.Where != "" {
+		filter.Schema = resourceFilterSchema(resources)
+	}
+
 	filterPredicate, err := filter.getPredicate()
 	if err != nil {
 		return nil, err
 	}
 
-	resourceFunc := func(item modconfig.HclResource) (bool, error) {
-		// if item is correct type and matches the predicate, add it to the result
-		if item, ok := item.(T); ok && filterPredicate(item) {
-			res[item.Name()] = item
+	// if item matches the predicate, add it to the result
+	for name, item := range resources {
+		if filterPredicate(item) {
+			res[name] = item
 		}
-		return true, nil
 	}
 
-	// resource func does not return error
-	_ = w.GetModResources().WalkResources(resourceFunc)
-
 	return res, nil
 }